@@ -0,0 +1,56 @@
+package asset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestBlocksTOML(t *testing.T) {
+	source, err := Asset("blocks.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks struct {
+		Blocks []struct{ Name string }
+	}
+	if _, err := toml.Decode(string(source), &blocks); err != nil {
+		t.Fatalf("failed to decode blocks.toml: %v", err)
+	}
+	if len(blocks.Blocks) == 0 || blocks.Blocks[0].Name != "Air" {
+		t.Error("blocks.toml doesn't start with air")
+	}
+}
+
+func TestShader(t *testing.T) {
+	source, err := Asset("shaders/chunkVert.glsl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(source), "#version") {
+		t.Error("chunk vertex shader doesn't start with a version directive")
+	}
+
+	// The shader is listed in its folder
+	names, err := AssetDir("shaders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, name := range names {
+		found = found || name == "chunkVert.glsl"
+	}
+	if !found {
+		t.Errorf("chunkVert.glsl not listed in the shaders folder: %v", names)
+	}
+}
+
+func TestMissingAsset(t *testing.T) {
+	if _, err := Asset("shaders/missing.glsl"); err == nil {
+		t.Error("got no error reading a missing file")
+	}
+	if _, err := AssetDir("missing"); err == nil {
+		t.Error("got no error listing a missing folder")
+	}
+}
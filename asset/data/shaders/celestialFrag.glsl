@@ -0,0 +1,12 @@
+#version 330
+
+uniform sampler2D tex;
+uniform float brightness;
+
+in vec2 fragUv;
+out vec4 color;
+
+void main() {
+	// The sun and moon are drawn additively, so darkening them fades them out
+	color = texture(tex, fragUv) * brightness;
+}
@@ -0,0 +1,16 @@
+#version 330
+
+uniform mat4 mvp;
+uniform vec4 uvRect;
+
+in vec3 position;
+in vec2 uv;
+out vec2 fragUv;
+
+void main() {
+	gl_Position = mvp * vec4(position, 1.0);
+
+	// Map the quad's UVs onto the region of the texture to use, whose offset is
+	// given by uvRect.xy and size by uvRect.zw
+	fragUv = uvRect.xy + uv * uvRect.zw;
+}
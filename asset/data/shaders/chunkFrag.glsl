@@ -2,9 +2,27 @@
 
 uniform sampler2D blockAtlas;
 
+// The color of the fog, and how far the chunk has faded in from it after being
+// loaded (from 0 to 1)
+uniform vec3 fogColor;
+uniform float fade;
+
 in vec2 fragUV;
+in float fragLight;
+in float fragShade;
 out vec4 color;
 
 void main() {
-	color = texture(blockAtlas, fragUV);
+	// Convert the linear light level into a brightness, so that light falls
+	// off more quickly near its source. Even completely dark blocks are
+	// slightly visible
+	float brightness = fragLight / (4.0 - 3.0 * fragLight);
+	brightness = mix(0.05, 1.0, brightness);
+
+	// Faces are shaded on top of the light level, depending on their direction
+	brightness *= fragShade;
+
+	// Newly loaded chunks fade in from the fog color
+	vec4 texColor = texture(blockAtlas, fragUV);
+	color = vec4(mix(fogColor, texColor.rgb * brightness, fade), texColor.a);
 }
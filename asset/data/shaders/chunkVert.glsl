@@ -2,13 +2,43 @@
 
 uniform mat4 mvp;
 
+// The brightness multiplier for top, bottom, x facing, and z facing faces
+uniform vec4 faceShading;
+
+// The number of seconds since the game started, the number of seconds each
+// frame of an animated texture is shown for, and the distance between frames
+// in the block atlas
+uniform float time;
+uniform float frameTime;
+uniform float frameHeight;
+
 in vec3 position;
 in vec3 normal;
 in vec2 uv;
+in float light;
+in float frames;
 
 out vec2 fragUV;
+out float fragLight;
+out float fragShade;
 
 void main() {
 	gl_Position = mvp * vec4(position, 1.0);
-	fragUV = uv;
+	fragLight = light;
+
+	// Animated textures have their frames one after the other down the block
+	// atlas, so move down to the current frame
+	float frame = mod(floor(time / frameTime), frames);
+	fragUV = uv + vec2(0.0, frame * frameHeight);
+
+	// Shade each face based on the direction it faces
+	if (normal.y > 0.5) {
+		fragShade = faceShading.x;
+	} else if (normal.y < -0.5) {
+		fragShade = faceShading.y;
+	} else if (abs(normal.x) > 0.5) {
+		fragShade = faceShading.z;
+	} else {
+		fragShade = faceShading.w;
+	}
 }
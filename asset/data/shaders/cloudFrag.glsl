@@ -0,0 +1,21 @@
+#version 330
+
+uniform sampler2D tex;
+uniform vec3 cloudColor;
+uniform vec3 fogColor;
+uniform float radius;
+
+in vec2 fragUv;
+in vec2 fragPos;
+out vec4 color;
+
+void main() {
+	// Tile the texture infinitely in every direction
+	vec4 cloud = texture(tex, fract(fragUv));
+
+	// Blend the clouds into the fog color towards the edge of the cloud layer,
+	// so they merge into the horizon
+	float fog = clamp(length(fragPos) / radius, 0.0, 1.0);
+	fog = fog * fog;
+	color = vec4(mix(cloudColor, fogColor, fog), cloud.a * (1.0 - fog));
+}
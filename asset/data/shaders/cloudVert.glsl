@@ -0,0 +1,25 @@
+#version 330
+
+uniform mat4 mvp;
+uniform vec3 offset;
+uniform vec2 uvOffset;
+uniform float uvScale;
+
+in vec3 position;
+out vec2 fragUv;
+out vec2 fragPos;
+
+void main() {
+	// Translate the cloud plane so it's above the camera
+	vec3 worldPos = position + offset;
+	gl_Position = mvp * vec4(worldPos, 1.0);
+
+	// The texture is fixed in world space, so the clouds don't move along with
+	// the camera. uvOffset is the texture coordinate above the camera, which
+	// is wrapped on the CPU so it stays precise far from the origin, and
+	// uvScale is the reciprocal of the size of the texture in blocks
+	fragUv = position.xz * uvScale + uvOffset;
+
+	// The position relative to the camera, used for the fog calculation
+	fragPos = position.xz;
+}
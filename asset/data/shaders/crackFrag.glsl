@@ -0,0 +1,15 @@
+#version 330
+
+uniform sampler2D cracks;
+
+in vec2 fragUV;
+out vec4 color;
+
+void main() {
+	// Only the cracks themselves are drawn over the block
+	vec4 texColor = texture(cracks, fragUV);
+	if (texColor.a < 0.1) {
+		discard;
+	}
+	color = texColor;
+}
@@ -0,0 +1,17 @@
+#version 330
+
+uniform mat4 mvp;
+
+// The crack textures are stacked vertically in a single strip, so the stage
+// picks out which one is used
+uniform float stage;
+uniform float numStages;
+
+in vec3 position;
+in vec2 uv;
+out vec2 fragUV;
+
+void main() {
+	gl_Position = mvp * vec4(position, 1.0);
+	fragUV = vec2(uv.x, (uv.y + stage) / numStages);
+}
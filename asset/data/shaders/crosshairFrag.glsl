@@ -0,0 +1,9 @@
+#version 330
+
+out vec4 color;
+
+void main() {
+	// Blended with the inverse of the destination color, so white inverts
+	// whatever is behind the crosshair
+	color = vec4(1.0);
+}
@@ -0,0 +1,9 @@
+#version 330
+
+// Positions are given directly in normalised device coordinates, so the
+// overlay covers the whole screen regardless of its size
+in vec2 position;
+
+void main() {
+	gl_Position = vec4(position, 0.0, 1.0);
+}
@@ -0,0 +1,17 @@
+#version 330
+
+uniform sampler2D blockAtlas;
+
+in vec2 fragUV;
+in float fragFade;
+out vec4 color;
+
+void main() {
+	// Skip the transparent parts of the block's texture (e.g. on leaves), so
+	// they don't hide what's behind them
+	vec4 texColor = texture(blockAtlas, fragUV);
+	if (texColor.a < 0.1) {
+		discard;
+	}
+	color = vec4(texColor.rgb, texColor.a * fragFade);
+}
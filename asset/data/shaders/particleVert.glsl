@@ -0,0 +1,16 @@
+#version 330
+
+uniform mat4 mvp;
+
+in vec3 position;
+in vec2 uv;
+in float fade;
+
+out vec2 fragUV;
+out float fragFade;
+
+void main() {
+	gl_Position = mvp * vec4(position, 1.0);
+	fragUV = uv;
+	fragFade = fade;
+}
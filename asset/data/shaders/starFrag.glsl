@@ -0,0 +1,9 @@
+#version 330
+
+uniform float alpha;
+
+out vec4 color;
+
+void main() {
+	color = vec4(1.0, 1.0, 1.0, alpha);
+}
@@ -0,0 +1,15 @@
+#version 330
+
+uniform sampler2D font;
+uniform vec3 textColor;
+
+in vec2 fragUv;
+out vec4 color;
+
+void main() {
+	vec4 glyph = texture(font, fragUv);
+	if (glyph.a == 0.0) {
+		discard;
+	}
+	color = vec4(textColor * glyph.rgb, glyph.a);
+}
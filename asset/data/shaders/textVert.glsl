@@ -0,0 +1,13 @@
+#version 330
+
+uniform mat4 mvp;
+uniform vec2 offset;
+
+in vec2 position;
+in vec2 uv;
+out vec2 fragUv;
+
+void main() {
+	gl_Position = mvp * vec4(position + offset, 0.0, 1.0);
+	fragUv = uv;
+}
@@ -0,0 +1,23 @@
+#version 330
+
+uniform vec3 voidColor;
+uniform vec3 fogColor;
+uniform float farPlane;
+
+in vec3 fragPos;
+out vec4 color;
+
+void main() {
+	// Darken the void the more directly down the fragment is from the camera,
+	// so looking straight down feels like looking into a bottomless pit
+	float depth = clamp(-normalize(fragPos).y, 0.0, 1.0);
+	vec3 base = voidColor * mix(1.0, 0.6, depth);
+
+	// Use the position of the fragment to calculate the fog strength, the same
+	// as for the sky plane, so the void meets the fog at the horizon
+	float fog_strength = length(fragPos) / (farPlane * 0.8);
+	fog_strength = clamp(fog_strength, 0.0, 1.0);
+
+	// Modulate between the void and fog colors by the fog strength factor
+	color = vec4(mix(base, fogColor, fog_strength), 1.0);
+}
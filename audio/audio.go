@@ -0,0 +1,138 @@
+// Package audio plays short sound effects (like blocks breaking), using
+// SDL_mixer to mix any number of overlapping sounds together.
+package audio
+
+import (
+	"fmt"
+	"log"
+	"path"
+
+	"github.com/benanders/mineral/asset"
+
+	"github.com/veandco/go-sdl2/mix"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	// The number of sounds that can play at the same time. Any more are
+	// skipped until one of the playing sounds finishes.
+	numChannels = 16
+
+	// The number of audio samples mixed at a time. Smaller values make sounds
+	// start playing sooner, but take more CPU time.
+	chunkSize = 1024
+)
+
+// Clips holds every sound loaded so far. It's nil until `Init` succeeds, in
+// which case `Play` does nothing.
+var clips *registry
+
+// Init opens the audio device. If it fails (e.g. because the computer has no
+// sound card), the game can carry on without sound. SDL must already be
+// initialised.
+func Init() error {
+	if err := mix.Init(mix.INIT_OGG); err != nil {
+		return fmt.Errorf("failed to initialise SDL_mixer: %v", err)
+	}
+	err := mix.OpenAudio(mix.DEFAULT_FREQUENCY, mix.DEFAULT_FORMAT,
+		mix.DEFAULT_CHANNELS, chunkSize)
+	if err != nil {
+		mix.Quit()
+		return fmt.Errorf("failed to open audio device: %v", err)
+	}
+	mix.AllocateChannels(numChannels)
+	clips = newRegistry(loadClip)
+	return nil
+}
+
+// Quit frees every loaded sound and closes the audio device.
+func Quit() {
+	if clips == nil {
+		return
+	}
+	clips.destroy()
+	clips = nil
+	mix.CloseAudio()
+	mix.Quit()
+}
+
+// Play starts playing the sound at the given path, relative to the
+// `asset/data/sounds` folder (e.g. `dig/stone.ogg`), at a volume between 0 and
+// 1. The sound is loaded the first time it's played. Does nothing for an empty
+// path, or if the audio device isn't open.
+func Play(name string, volume float32) {
+	if clips == nil || name == "" {
+		return
+	}
+	clip, err := clips.get(name)
+	if err != nil {
+		return // Already logged when the clip failed to load
+	}
+
+	// Play on the first free channel, skipping the sound if there isn't one
+	channel, err := clip.Play(-1, 0)
+	if err != nil {
+		return
+	}
+	mix.Volume(channel, int(volume*mix.MAX_VOLUME))
+}
+
+// LoadClip decodes the sound at the given path from the assets.
+func loadClip(name string) (*mix.Chunk, error) {
+	assetPath := path.Join("sounds", name)
+	data, err := asset.Asset(assetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load asset `%v`: %v", assetPath, err)
+	}
+	rw, err := sdl.RWFromMem(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sound `%v`: %v", assetPath, err)
+	}
+	clip, err := mix.LoadWAVRW(rw, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sound `%v`: %v", assetPath,
+			err)
+	}
+	return clip, nil
+}
+
+// Registry caches sounds by name, so each one is only loaded once. Sounds
+// that fail to load are remembered too, so the error is only logged once.
+type registry struct {
+	load   func(name string) (*mix.Chunk, error)
+	clips  map[string]*mix.Chunk
+	failed map[string]error
+}
+
+// NewRegistry creates an empty registry, which uses `load` to load each sound
+// the first time it's asked for.
+func newRegistry(load func(name string) (*mix.Chunk, error)) *registry {
+	return &registry{load: load, clips: make(map[string]*mix.Chunk),
+		failed: make(map[string]error)}
+}
+
+// Get returns the sound with the given name, loading it if necessary.
+func (r *registry) get(name string) (*mix.Chunk, error) {
+	if clip, ok := r.clips[name]; ok {
+		return clip, nil
+	}
+	if err, ok := r.failed[name]; ok {
+		return nil, err
+	}
+	clip, err := r.load(name)
+	if err != nil {
+		log.Println(err)
+		r.failed[name] = err
+		return nil, err
+	}
+	r.clips[name] = clip
+	return clip, nil
+}
+
+// Destroy frees every sound in the registry.
+func (r *registry) destroy() {
+	for _, clip := range r.clips {
+		clip.Free()
+	}
+	r.clips = make(map[string]*mix.Chunk)
+}
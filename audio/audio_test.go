@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/veandco/go-sdl2/mix"
+)
+
+// TestRegistry creates a registry that knows a single clip, `dig/stone.ogg`,
+// and counts how many times each clip is loaded.
+func testRegistry(loads map[string]int) *registry {
+	return newRegistry(func(name string) (*mix.Chunk, error) {
+		loads[name]++
+		if name != "dig/stone.ogg" {
+			return nil, errors.New("no such clip")
+		}
+		return &mix.Chunk{}, nil
+	})
+}
+
+func TestRegistryLoadsClip(t *testing.T) {
+	loads := make(map[string]int)
+	r := testRegistry(loads)
+	first, err := r.get("dig/stone.ogg")
+	if err != nil || first == nil {
+		t.Fatalf("failed to load clip: %v", err)
+	}
+
+	// The clip is only loaded once
+	second, err := r.get("dig/stone.ogg")
+	if err != nil || second != first {
+		t.Error("clip wasn't cached")
+	}
+	if loads["dig/stone.ogg"] != 1 {
+		t.Errorf("clip loaded %d times, want 1", loads["dig/stone.ogg"])
+	}
+}
+
+func TestRegistryUnknownClip(t *testing.T) {
+	loads := make(map[string]int)
+	r := testRegistry(loads)
+	for i := 0; i < 2; i++ {
+		if clip, err := r.get("dig/missing.ogg"); err == nil || clip != nil {
+			t.Fatal("loaded a clip that doesn't exist")
+		}
+	}
+
+	// The failure is remembered, so the clip isn't loaded again
+	if loads["dig/missing.ogg"] != 1 {
+		t.Errorf("clip loaded %d times, want 1", loads["dig/missing.ogg"])
+	}
+}
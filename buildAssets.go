@@ -1,7 +1,14 @@
+//go:build ignore
+// +build ignore
+
+// The asset extraction script is run on its own with `go run buildAssets.go`,
+// so it's left out of the game's own build.
+
 package main
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"io/ioutil"
 	"log"
 	"os"
@@ -35,14 +42,68 @@ var supportedVersions = []string{
 // this repository.
 var assetMap = map[string]string{
 	// Blocks
-	"assets/minecraft/textures/blocks/bedrock.png":     "textures/blocks/bedrock.png",
-	"assets/minecraft/textures/blocks/stone.png":       "textures/blocks/stone.png",
-	"assets/minecraft/textures/blocks/cobblestone.png": "textures/blocks/cobblestone.png",
-	"assets/minecraft/textures/blocks/dirt.png":        "textures/blocks/dirt.png",
+	"assets/minecraft/textures/blocks/bedrock.png":         "textures/blocks/bedrock.png",
+	"assets/minecraft/textures/blocks/stone.png":           "textures/blocks/stone.png",
+	"assets/minecraft/textures/blocks/cobblestone.png":     "textures/blocks/cobblestone.png",
+	"assets/minecraft/textures/blocks/dirt.png":            "textures/blocks/dirt.png",
+	"assets/minecraft/textures/blocks/stone_slab_top.png":  "textures/blocks/stone_slab_top.png",
+	"assets/minecraft/textures/blocks/stone_slab_side.png": "textures/blocks/stone_slab_side.png",
+	"assets/minecraft/textures/blocks/log_oak.png":         "textures/blocks/log_oak.png",
+	"assets/minecraft/textures/blocks/log_oak_top.png":     "textures/blocks/log_oak_top.png",
+	"assets/minecraft/textures/blocks/grass_top.png":       "textures/blocks/grass_top.png",
+	"assets/minecraft/textures/blocks/grass_side.png":      "textures/blocks/grass_side.png",
+	"assets/minecraft/textures/blocks/sand.png":            "textures/blocks/sand.png",
+	"assets/minecraft/textures/blocks/gravel.png":          "textures/blocks/gravel.png",
+	"assets/minecraft/textures/blocks/water_still.png":     "textures/blocks/water_still.png",
+	"assets/minecraft/textures/blocks/glowstone.png":       "textures/blocks/glowstone.png",
+	"assets/minecraft/textures/blocks/lava_still.png":      "textures/blocks/lava_still.png",
+	"assets/minecraft/textures/blocks/ladder.png":          "textures/blocks/ladder.png",
+
+	// Cracks drawn over blocks as they're broken
+	"assets/minecraft/textures/blocks/destroy_stage_0.png": "textures/blocks/destroy_stage_0.png",
+	"assets/minecraft/textures/blocks/destroy_stage_1.png": "textures/blocks/destroy_stage_1.png",
+	"assets/minecraft/textures/blocks/destroy_stage_2.png": "textures/blocks/destroy_stage_2.png",
+	"assets/minecraft/textures/blocks/destroy_stage_3.png": "textures/blocks/destroy_stage_3.png",
+	"assets/minecraft/textures/blocks/destroy_stage_4.png": "textures/blocks/destroy_stage_4.png",
+	"assets/minecraft/textures/blocks/destroy_stage_5.png": "textures/blocks/destroy_stage_5.png",
+	"assets/minecraft/textures/blocks/destroy_stage_6.png": "textures/blocks/destroy_stage_6.png",
+	"assets/minecraft/textures/blocks/destroy_stage_7.png": "textures/blocks/destroy_stage_7.png",
+	"assets/minecraft/textures/blocks/destroy_stage_8.png": "textures/blocks/destroy_stage_8.png",
+	"assets/minecraft/textures/blocks/destroy_stage_9.png": "textures/blocks/destroy_stage_9.png",
 
 	// Environment
 	"assets/minecraft/textures/environment/sun.png":         "textures/environment/sun.png",
 	"assets/minecraft/textures/environment/moon_phases.png": "textures/environment/moon.png",
+	"assets/minecraft/textures/environment/clouds.png":      "textures/environment/clouds.png",
+
+	// Font
+	"assets/minecraft/textures/font/ascii.png": "textures/font/ascii.png",
+}
+
+// SoundMap specifies which sounds from the original Minecraft game are to be
+// copied across into Mineral's assets folder.
+//
+// Sounds aren't stored in the `.jar` file, but in the launcher's shared asset
+// store, under names listed in the asset index for the Minecraft version. All
+// file paths for the Minecraft sounds are specified as they're named in the
+// asset index.
+//
+// All output file paths are relative to the `asset/data` folder contained in
+// this repository.
+var soundMap = map[string]string{
+	// Breaking and placing blocks
+	"minecraft/sounds/dig/stone1.ogg":  "sounds/dig/stone.ogg",
+	"minecraft/sounds/dig/grass1.ogg":  "sounds/dig/grass.ogg",
+	"minecraft/sounds/dig/gravel1.ogg": "sounds/dig/gravel.ogg",
+	"minecraft/sounds/dig/sand1.ogg":   "sounds/dig/sand.ogg",
+	"minecraft/sounds/dig/wood1.ogg":   "sounds/dig/wood.ogg",
+
+	// Footsteps
+	"minecraft/sounds/step/stone1.ogg":  "sounds/step/stone.ogg",
+	"minecraft/sounds/step/grass1.ogg":  "sounds/step/grass.ogg",
+	"minecraft/sounds/step/gravel1.ogg": "sounds/step/gravel.ogg",
+	"minecraft/sounds/step/sand1.ogg":   "sounds/step/sand.ogg",
+	"minecraft/sounds/step/wood1.ogg":   "sounds/step/wood.ogg",
 }
 
 // BasePath is the path relative to the root of the project directory in which
@@ -131,21 +192,7 @@ func main() {
 			}
 			inputReader.Close()
 
-			// Open the output file
-			splitCopyPath := strings.Split(copyPath, "/")
-			outputPath := path.Join(assetsPath, path.Join(splitCopyPath...))
-			os.MkdirAll(path.Dir(outputPath), 0700)
-			outputWriter, err := os.Create(outputPath)
-			if err != nil {
-				log.Fatalln("failed to open output file: " + outputPath)
-			}
-
-			// Write to the output file
-			_, err = outputWriter.Write(bytes)
-			if err != nil {
-				log.Fatalln("failed to write to output file: " + outputPath)
-			}
-			outputWriter.Close()
+			writeAsset(assetsPath, copyPath, bytes)
 		}
 	}
 
@@ -155,5 +202,81 @@ func main() {
 			" files, expected " + strconv.Itoa(len(assetMap)) + " files")
 	}
 
+	// Copy the sounds across from the shared asset store
+	copySounds(minecraftFolder, versionsFolder, version, assetsPath)
+	count += len(soundMap)
+
 	log.Println("successfully copied " + strconv.Itoa(count) + " assets!")
 }
+
+// CopySounds copies every sound in `soundMap` from the launcher's shared asset
+// store into the assets folder.
+//
+// The version's JSON file names its asset index, which in turn gives the hash
+// of each sound. Files in the store are named after their hash.
+func copySounds(minecraftFolder, versionsFolder, version, assetsPath string) {
+	// Find the asset index used by the version
+	versionPath := path.Join(versionsFolder, version, version+".json")
+	var versionInfo struct {
+		AssetIndex struct {
+			ID string `json:"id"`
+		} `json:"assetIndex"`
+	}
+	readJSON(versionPath, &versionInfo)
+
+	// Read the asset index
+	indexPath := path.Join(minecraftFolder, "assets", "indexes",
+		versionInfo.AssetIndex.ID+".json")
+	var index struct {
+		Objects map[string]struct {
+			Hash string `json:"hash"`
+		} `json:"objects"`
+	}
+	readJSON(indexPath, &index)
+
+	// Copy each sound across
+	for name, copyPath := range soundMap {
+		object, ok := index.Objects[name]
+		if !ok {
+			log.Fatalln("missing sound in asset index: " + name)
+		}
+		objectPath := path.Join(minecraftFolder, "assets", "objects",
+			object.Hash[:2], object.Hash)
+		bytes, err := ioutil.ReadFile(objectPath)
+		if err != nil {
+			log.Fatalln("failed to read sound `"+name+"`: ", err)
+		}
+		writeAsset(assetsPath, copyPath, bytes)
+	}
+}
+
+// ReadJSON decodes the JSON file at the given path into `v`.
+func readJSON(filePath string, v interface{}) {
+	bytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		log.Fatalln("failed to read `"+filePath+"`: ", err)
+	}
+	if err := json.Unmarshal(bytes, v); err != nil {
+		log.Fatalln("failed to decode `"+filePath+"`: ", err)
+	}
+}
+
+// WriteAsset writes the contents of an asset file to the given path, relative
+// to the assets folder, creating any folders along the way.
+func writeAsset(assetsPath, copyPath string, bytes []byte) {
+	// Open the output file
+	splitCopyPath := strings.Split(copyPath, "/")
+	outputPath := path.Join(assetsPath, path.Join(splitCopyPath...))
+	os.MkdirAll(path.Dir(outputPath), 0700)
+	outputWriter, err := os.Create(outputPath)
+	if err != nil {
+		log.Fatalln("failed to open output file: " + outputPath)
+	}
+
+	// Write to the output file
+	_, err = outputWriter.Write(bytes)
+	if err != nil {
+		log.Fatalln("failed to write to output file: " + outputPath)
+	}
+	outputWriter.Close()
+}
@@ -14,9 +14,6 @@ type ViewPoint interface {
 }
 
 const (
-	// Fov is the default field of view for the camera.
-	Fov = 60.0 * float32(math.Pi) / 180.0 // 60 degrees in radians
-
 	// Near is the default near plane distance for the camera; the distance
 	// between the center of the camera and the closest visible thing.
 	Near = 0.1
@@ -24,11 +21,19 @@ const (
 	// Far is the default far plane distance for the camera; the distance
 	// between the center of the camera and the furthest visible thing.
 	Far = 256.0
+
+	// FovEaseRate controls how quickly the field of view eases towards a new
+	// value. After 1/FovEaseRate seconds, the field of view has covered about
+	// 63% of the distance to its target.
+	FovEaseRate = 10.0
 )
 
 // Camera keeps track of the model, view, projection, and orientation matrices,
 // which define the perspective from which the scene is viewed.
 type Camera struct {
+	FieldOfView float32
+	Aspect      float32
+	NearPlane   float32
 	FarPlane    float32
 	Projection  mgl32.Mat4
 	View        mgl32.Mat4
@@ -38,10 +43,43 @@ type Camera struct {
 // Perspective sets up the camera's perspective projection with the given
 // parameters. `fov` is in radians.
 func (c *Camera) Perspective(fov, aspect, near, far float32) {
+	c.FieldOfView = fov
+	c.Aspect = aspect
+	c.NearPlane = near
 	c.FarPlane = far
 	c.Projection = mgl32.Perspective(fov, aspect, near, far)
 }
 
+// SetAspect rebuilds the camera's projection matrix for a new aspect ratio
+// (width divided by height), keeping the current field of view and near and
+// far planes. This is needed whenever the window is resized, otherwise the
+// scene is stretched to fit the new window.
+//
+// The camera's view and orientation matrices aren't updated until the next
+// call to `Follow`.
+func (c *Camera) SetAspect(aspect float32) {
+	c.Perspective(c.FieldOfView, aspect, c.NearPlane, c.FarPlane)
+}
+
+// EaseFov moves the camera's field of view smoothly towards a target field of
+// view (in radians), given the time elapsed since the previous frame (in
+// seconds). Since the easing depends on the elapsed time, the transition
+// takes the same time regardless of the frame rate.
+//
+// The camera's view and orientation matrices aren't updated until the next
+// call to `Follow`.
+func (c *Camera) EaseFov(target, elapsed float32) {
+	fov := EaseTowards(c.FieldOfView, target, FovEaseRate, elapsed)
+	c.Perspective(fov, c.Aspect, c.NearPlane, c.FarPlane)
+}
+
+// EaseTowards exponentially eases a value towards a target, given the time
+// elapsed (in seconds) and the rate at which to ease.
+func EaseTowards(current, target, rate, elapsed float32) float32 {
+	factor := 1.0 - float32(math.Exp(float64(-rate*elapsed)))
+	return current + (target-current)*factor
+}
+
 // Follow updates the camera's view and orientation matrices so that the scene
 // is now viewed from the perspective of the given entity.
 func (c *Camera) Follow(viewPoint ViewPoint) {
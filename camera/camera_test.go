@@ -0,0 +1,121 @@
+package camera
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// EaseFovFor eases a camera's field of view from `from` towards `to` for the
+// given number of frames at the given frame rate, and returns the field of
+// view after each frame.
+func easeFovFor(from, to float32, frames, fps int) []float32 {
+	var c Camera
+	c.Perspective(from, 1.0, Near, Far)
+	fovs := make([]float32, frames)
+	for i := range fovs {
+		c.EaseFov(to, 1.0/float32(fps))
+		fovs[i] = c.FieldOfView
+	}
+	return fovs
+}
+
+func TestEaseFovConverges(t *testing.T) {
+	from, to := mgl32.DegToRad(70.0), mgl32.DegToRad(80.0)
+	for _, fps := range []int{30, 60, 144} {
+		// The field of view approaches the target without overshooting
+		fovs := easeFovFor(from, to, fps, fps)
+		prev := from
+		for i, fov := range fovs {
+			if fov < prev || fov > to {
+				t.Fatalf("got field of view %v on frame %d at %d fps, want "+
+					"between %v and %v", fov, i, fps, prev, to)
+			}
+			prev = fov
+		}
+		if got := fovs[len(fovs)-1]; mgl32.Abs(got-to) > 1e-3 {
+			t.Errorf("got field of view %v after 1 second at %d fps, want "+
+				"about %v", got, fps, to)
+		}
+	}
+
+	// The easing takes the same time regardless of the frame rate
+	slow := easeFovFor(from, to, 2, 20)
+	fast := easeFovFor(from, to, 20, 200)
+	if a, b := slow[len(slow)-1], fast[len(fast)-1]; mgl32.Abs(a-b) > 1e-5 {
+		t.Errorf("got field of view %v at 20 fps and %v at 200 fps after "+
+			"0.1 seconds", a, b)
+	}
+}
+
+func TestSetAspect(t *testing.T) {
+	var c Camera
+	fov := mgl32.DegToRad(70.0)
+	c.Perspective(fov, 4.0/3.0, Near, Far)
+	before := c.Projection
+
+	// A new aspect ratio gives a new projection, keeping everything else
+	c.SetAspect(16.0 / 9.0)
+	if c.Projection == before {
+		t.Fatal("projection didn't change with the aspect ratio")
+	}
+	want := mgl32.Perspective(fov, 16.0/9.0, Near, Far)
+	if !c.Projection.ApproxEqual(want) {
+		t.Errorf("got projection %v, want %v", c.Projection, want)
+	}
+	if c.FieldOfView != fov || c.NearPlane != Near || c.FarPlane != Far {
+		t.Errorf("got field of view %v and planes %v to %v, want %v and "+
+			"%v to %v", c.FieldOfView, c.NearPlane, c.FarPlane, fov,
+			float32(Near), float32(Far))
+	}
+}
+
+// TestViewPoint is a view point at a fixed position, looking in a fixed
+// direction.
+type testViewPoint struct {
+	eye, sight mgl32.Vec3
+}
+
+func (v testViewPoint) Sight() mgl32.Vec3 {
+	return v.sight
+}
+
+func (v testViewPoint) EyePosition() mgl32.Vec3 {
+	return v.eye
+}
+
+// Project transforms a point by the given matrix into normalized device
+// coordinates, and returns whether the point is in front of the camera.
+func project(m mgl32.Mat4, p mgl32.Vec3) (mgl32.Vec3, bool) {
+	clip := m.Mul4x1(p.Vec4(1.0))
+	return clip.Vec3().Mul(1.0 / clip.W()), clip.W() > 0.0
+}
+
+func TestFollow(t *testing.T) {
+	var c Camera
+	c.Perspective(mgl32.DegToRad(70.0), 16.0/9.0, Near, Far)
+	viewPoint := testViewPoint{
+		eye:   mgl32.Vec3{10.0, 70.0, -5.0},
+		sight: mgl32.Vec3{1.0, -1.0, 0.5}.Normalize(),
+	}
+	c.Follow(viewPoint)
+
+	// A point along the line of sight is in the middle of the screen
+	look := viewPoint.sight.Mul(-1.0)
+	ahead := viewPoint.eye.Add(look.Mul(5.0))
+	ndc, inFront := project(c.View, ahead)
+	if !inFront || mgl32.Abs(ndc.X()) > 1e-4 || mgl32.Abs(ndc.Y()) > 1e-4 {
+		t.Errorf("point ahead of the eye is at %v on screen, want the "+
+			"middle", ndc)
+	}
+	if _, inFront := project(c.View, viewPoint.eye.Sub(look)); inFront {
+		t.Error("point behind the eye is in front of the camera")
+	}
+
+	// The orientation matrix has the same rotation, without the position
+	ndc, inFront = project(c.Orientation, look)
+	if !inFront || mgl32.Abs(ndc.X()) > 1e-4 || mgl32.Abs(ndc.Y()) > 1e-4 {
+		t.Errorf("line of sight is at %v on screen without the position, "+
+			"want the middle", ndc)
+	}
+}
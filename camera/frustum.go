@@ -0,0 +1,89 @@
+package camera
+
+import (
+	"github.com/benanders/mineral/math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Plane is a plane in 3D space, made up of all the points `p` for which
+// `Normal.Dot(p) + D` is 0. Points on the side of the plane the normal points
+// towards are in front of it.
+type Plane struct {
+	Normal mgl32.Vec3
+	D      float32
+}
+
+// Distance returns the signed distance from the plane to a point, which is
+// positive if the point is in front of the plane. It's only a true distance if
+// the plane's normal has length 1.
+func (p Plane) Distance(point mgl32.Vec3) float32 {
+	return p.Normal.Dot(point) + p.D
+}
+
+// Frustum is the region of space visible to the camera, bounded by six planes
+// (left, right, bottom, top, near, and far). Each plane's normal points into
+// the frustum, and has length 1.
+type Frustum [6]Plane
+
+// Frustum extracts the planes of the camera's view frustum from its combined
+// projection and view matrix. It's only up to date after the most recent call
+// to `Follow`.
+func (c *Camera) Frustum() Frustum {
+	return FrustumFromMatrix(c.View)
+}
+
+// FrustumFromMatrix extracts the planes of the view frustum for a combined
+// projection and view matrix (the Gribb-Hartmann method). A point is inside
+// the frustum when every component of its clip space position lies between
+// -w and w, and each of those six conditions is one of the planes.
+func FrustumFromMatrix(m mgl32.Mat4) Frustum {
+	rows := [4]mgl32.Vec4{m.Row(0), m.Row(1), m.Row(2), m.Row(3)}
+	var f Frustum
+	for axis := 0; axis < 3; axis++ {
+		f[axis*2] = newPlane(rows[3].Add(rows[axis]))
+		f[axis*2+1] = newPlane(rows[3].Sub(rows[axis]))
+	}
+	return f
+}
+
+// NewPlane creates a plane from its coefficients (the x, y, and z components
+// of its normal, then `D`), normalising it so the normal has length 1.
+func newPlane(v mgl32.Vec4) Plane {
+	normal := v.Vec3()
+	length := normal.Len()
+	return Plane{normal.Mul(1.0 / length), v.W() / length}
+}
+
+// ContainsPoint returns true if a point is inside the frustum (or on its
+// boundary).
+func (f Frustum) ContainsPoint(point mgl32.Vec3) bool {
+	for _, plane := range f {
+		if plane.Distance(point) < 0.0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsAABB returns true if any part of an AABB might be inside the
+// frustum. The test is conservative: an AABB is only rejected if it's entirely
+// behind one of the planes, so a few AABBs just outside the corners of the
+// frustum are counted as intersecting it.
+func (f Frustum) IntersectsAABB(box math.AABB) bool {
+	boxMin, boxMax := box.Min(), box.Max()
+	for _, plane := range f {
+		// Find the corner of the box furthest in front of the plane. If even
+		// that's behind the plane, the whole box is
+		corner := boxMin
+		for axis := 0; axis < 3; axis++ {
+			if plane.Normal[axis] > 0.0 {
+				corner[axis] = boxMax[axis]
+			}
+		}
+		if plane.Distance(corner) < 0.0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package camera
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestFrustum returns the frustum of a camera at the origin looking down the
+// negative z axis, with a 90 degree field of view, so the frustum's sides are
+// at 45 degrees.
+func testFrustum() Frustum {
+	projection := mgl32.Perspective(mgl32.DegToRad(90.0), 1.0, 0.1, 100.0)
+	view := mgl32.LookAtV(mgl32.Vec3{}, mgl32.Vec3{0.0, 0.0, -1.0},
+		mgl32.Vec3{0.0, 1.0, 0.0})
+	return FrustumFromMatrix(projection.Mul4(view))
+}
+
+func TestFrustumContainsPoint(t *testing.T) {
+	f := testFrustum()
+	inside := []mgl32.Vec3{{0.0, 0.0, -10.0}, {9.0, -9.0, -10.0},
+		{0.0, 0.0, -99.0}}
+	for _, point := range inside {
+		if !f.ContainsPoint(point) {
+			t.Errorf("frustum doesn't contain %v", point)
+		}
+	}
+	outside := []mgl32.Vec3{
+		{0.0, 0.0, 10.0},   // Behind the camera
+		{0.0, 0.0, -0.05},  // In front of the near plane
+		{0.0, 0.0, -101.0}, // Beyond the far plane
+		{11.0, 0.0, -10.0}, // To the right
+		{0.0, 11.0, -10.0}, // Above
+	}
+	for _, point := range outside {
+		if f.ContainsPoint(point) {
+			t.Errorf("frustum contains %v", point)
+		}
+	}
+}
+
+func TestFrustumIntersectsAABB(t *testing.T) {
+	f := testFrustum()
+	box := func(center, size mgl32.Vec3) math.AABB {
+		return math.AABB{Center: center, Size: size}
+	}
+	unit := mgl32.Vec3{1.0, 1.0, 1.0}
+	intersecting := []math.AABB{
+		box(mgl32.Vec3{0.0, 0.0, -10.0}, unit),
+		box(mgl32.Vec3{-10.0, 0.0, -10.0}, unit),           // Across the left plane
+		box(mgl32.Vec3{}, mgl32.Vec3{500.0, 500.0, 500.0}), // Around it all
+	}
+	for _, b := range intersecting {
+		if !f.IntersectsAABB(b) {
+			t.Errorf("frustum doesn't intersect box at %v", b.Center)
+		}
+	}
+	rejected := []math.AABB{
+		box(mgl32.Vec3{0.0, 0.0, 10.0}, unit),   // Behind the camera
+		box(mgl32.Vec3{20.0, 0.0, -10.0}, unit), // To the right
+		box(mgl32.Vec3{0.0, 0.0, -200.0}, unit), // Beyond the far plane
+	}
+	for _, b := range rejected {
+		if f.IntersectsAABB(b) {
+			t.Errorf("frustum intersects box at %v", b.Center)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package config loads and saves the player's settings for graphics and
+// controls, which persist between games in a TOML file in the user's config
+// directory.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	// DefaultRenderRadius is the number of chunks around the player that are
+	// rendered, if the settings file doesn't say otherwise.
+	DefaultRenderRadius = 8
+
+	// DefaultFov is the camera's vertical field of view, in degrees.
+	DefaultFov = 60.0
+
+	// MinFov and MaxFov are the limits of the field of view, in degrees.
+	// Anything outside this range distorts the scene too much to play.
+	MinFov = 30.0
+	MaxFov = 110.0
+
+	// MaxLookSmoothing is the most look smoothing allowed. Any more and the
+	// look direction lags too far behind the mouse.
+	MaxLookSmoothing = 0.9
+
+	// The name of the settings file, and of the folder it's kept in within
+	// the user's config directory (see `Dir`).
+	settingsDir  = "mineral"
+	settingsFile = "settings.toml"
+)
+
+// Settings holds the player's choices for graphics and controls. Settings not
+// given in the settings file take on their default values.
+type Settings struct {
+	// The number of chunks around the player that are rendered
+	RenderRadius int
+
+	// The camera's vertical field of view, in degrees, between `MinFov` and
+	// `MaxFov`
+	Fov float32
+
+	// How much mouse movement changes the look direction, where 1 is the
+	// default speed, and whether moving the mouse up looks down
+	MouseSensitivity float32
+	InvertY          bool
+
+	// How much changes in look direction are eased over a few update ticks,
+	// from 0 (no smoothing) up to `MaxLookSmoothing`
+	LookSmoothing float32
+
+	// True to jump automatically when walking into a single block step
+	AutoJump bool
+
+	// True to play in a fullscreen window the size of the desktop
+	Fullscreen bool
+
+	// True to interpolate light smoothly across block faces, rather than
+	// giving each face a single flat light level
+	SmoothLighting bool
+
+	// True to wait for the display to refresh before showing each frame, and
+	// to show late frames straight away rather than waiting for the next
+	// refresh (adaptive VSync), if the graphics driver supports it
+	VSync         bool
+	AdaptiveVSync bool
+
+	// The most frames rendered per second, or 0 for no limit. This applies
+	// whether or not VSync is on
+	MaxFPS int
+
+	// The file the settings were loaded from, and are saved back to. Settings
+	// aren't saved anywhere if empty
+	path string
+}
+
+// Default returns the settings used when there's no settings file.
+func Default() Settings {
+	return Settings{
+		RenderRadius:     DefaultRenderRadius,
+		Fov:              DefaultFov,
+		MouseSensitivity: 1.0,
+		VSync:            true,
+	}
+}
+
+// Load reads the settings from the settings file in the user's config
+// directory. If there's no settings file, one is created holding the default
+// settings.
+//
+// The default settings are returned along with any error, so the game can
+// still be played if the settings can't be loaded.
+func Load() (Settings, error) {
+	dir, err := Dir()
+	if err != nil {
+		return Default(), err
+	}
+	return LoadFile(filepath.Join(dir, settingsFile))
+}
+
+// Dir returns the folder within the user's config directory that holds the
+// settings file, and any other files the player can edit to configure the
+// game (like key bindings). The folder isn't created if it doesn't exist.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %v", err)
+	}
+	return filepath.Join(dir, settingsDir), nil
+}
+
+// LoadFile reads the settings from the given TOML file, which they're saved
+// back to by `Save`. If the file doesn't exist, it's created holding the
+// default settings.
+//
+// If the file can't be read or parsed, the default settings are returned
+// without a file to save them to, so the player's file isn't overwritten with
+// the defaults (losing whatever settings they'd chosen).
+func LoadFile(path string) (Settings, error) {
+	source, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		settings := Default()
+		settings.path = path
+		return settings, settings.Save()
+	} else if err != nil {
+		return Default(), fmt.Errorf("failed to read settings `%v`: %v", path,
+			err)
+	}
+
+	settings, err := Parse(string(source))
+	if err != nil {
+		return Default(), fmt.Errorf("failed to load settings `%v`: %v", path,
+			err)
+	}
+	settings.path = path
+	return settings, nil
+}
+
+// Parse decodes settings from TOML source (e.g. `Fov = 70.0`). Settings that
+// aren't listed keep their default values, and values out of range are
+// clamped to the nearest sensible value.
+func Parse(source string) (Settings, error) {
+	settings := Default()
+	meta, err := toml.Decode(source, &settings)
+	if err != nil {
+		return Default(), err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return Default(), fmt.Errorf("unknown setting `%v`", undecoded[0])
+	}
+	settings.clamp()
+	return settings, nil
+}
+
+// Clamp limits each setting to its allowed range.
+func (s *Settings) clamp() {
+	if s.RenderRadius < 1 {
+		s.RenderRadius = 1
+	}
+	if s.Fov < MinFov {
+		s.Fov = MinFov
+	} else if s.Fov > MaxFov {
+		s.Fov = MaxFov
+	}
+	if s.MouseSensitivity <= 0.0 {
+		s.MouseSensitivity = 1.0
+	}
+	if s.LookSmoothing < 0.0 {
+		s.LookSmoothing = 0.0
+	} else if s.LookSmoothing > MaxLookSmoothing {
+		s.LookSmoothing = MaxLookSmoothing
+	}
+	if s.MaxFPS < 0 {
+		s.MaxFPS = 0
+	}
+}
+
+// WithChanges returns a copy of the settings with every setting that differs
+// between `from` and `to` changed to its value in `to`. The settings are saved
+// to the same file.
+//
+// This is used to save only the settings the player changed while playing.
+// Settings overridden for a single game (e.g. on the command line) are in
+// both `from` and `to`, so they aren't saved.
+func (s Settings) WithChanges(from, to Settings) Settings {
+	result := reflect.ValueOf(&s).Elem()
+	before, after := reflect.ValueOf(from), reflect.ValueOf(to)
+	for i := 0; i < result.NumField(); i++ {
+		if result.Type().Field(i).PkgPath != "" {
+			continue // Unexported, like the file the settings are saved to
+		}
+		if before.Field(i).Interface() != after.Field(i).Interface() {
+			result.Field(i).Set(after.Field(i))
+		}
+	}
+	return s
+}
+
+// Save writes the settings back to the file they were loaded from, creating
+// the file and the folder it's in if necessary. Settings that weren't loaded
+// from a file aren't saved anywhere.
+func (s Settings) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(s); err != nil {
+		return fmt.Errorf("failed to encode settings: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create settings folder: %v", err)
+	}
+	if err := os.WriteFile(s.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write settings `%v`: %v", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMissingSettingsKeepDefaults(t *testing.T) {
+	settings, err := Parse("Fov = 70.0\nInvertY = true\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.Fov = 70.0
+	want.InvertY = true
+	if settings != want {
+		t.Errorf("got settings %+v, want %+v", settings, want)
+	}
+}
+
+func TestParseClampsSettings(t *testing.T) {
+	settings, err := Parse("RenderRadius = -2\nFov = 500.0\n" +
+		"MouseSensitivity = 0.0\nLookSmoothing = 3.0\nMaxFPS = -10\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.RenderRadius != 1 || settings.Fov != MaxFov ||
+		settings.MouseSensitivity != 1.0 ||
+		settings.LookSmoothing != MaxLookSmoothing || settings.MaxFPS != 0 {
+		t.Errorf("got settings %+v, want them clamped", settings)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	sources := []string{
+		"Fov = ",
+		"Fov = \"wide\"",
+		"NotASetting = 1",
+	}
+	for _, source := range sources {
+		settings, err := Parse(source)
+		if err == nil {
+			t.Errorf("parsed invalid settings %q", source)
+		}
+		if settings != Default() {
+			t.Errorf("got settings %+v for %q, want the defaults", settings,
+				source)
+		}
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mineral", settingsFile)
+
+	// Loading a missing file creates it with the default settings
+	settings, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("settings file wasn't created: %v", err)
+	}
+
+	// Every setting survives being saved and loaded again
+	settings.RenderRadius = 12
+	settings.Fov = 85.0
+	settings.MouseSensitivity = 1.5
+	settings.InvertY = true
+	settings.LookSmoothing = 0.4
+	settings.AutoJump = true
+	settings.Fullscreen = true
+	settings.SmoothLighting = true
+	settings.VSync = false
+	settings.AdaptiveVSync = true
+	settings.MaxFPS = 144
+	if err := settings.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != settings {
+		t.Errorf("loaded settings %+v, want %+v", loaded, settings)
+	}
+}
+
+func TestLoadInvalidFileIsNotOverwritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), settingsFile)
+	source := []byte("Fov = 70.0\nMisspeltSetting = true\n")
+	if err := os.WriteFile(path, source, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadFile(path)
+	if err == nil {
+		t.Fatal("loaded an invalid settings file")
+	}
+	if err := settings.Save(); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved) != string(source) {
+		t.Errorf("invalid settings file was overwritten with %q", saved)
+	}
+}
+
+func TestWithChanges(t *testing.T) {
+	file := Default()
+	file.Fov = 80.0
+	file.path = "settings.toml"
+
+	// The render radius is overridden for this game, and only the field of
+	// view and VSync are changed while playing
+	start := file
+	start.RenderRadius = 2
+	end := start
+	end.Fov = 90.0
+	end.VSync = false
+
+	got := file.WithChanges(start, end)
+	want := file
+	want.Fov = 90.0
+	want.VSync = false
+	if got != want {
+		t.Errorf("got settings %+v, want %+v", got, want)
+	}
+}
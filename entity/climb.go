@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"github.com/benanders/mineral/math"
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// ClimbSpeed is the speed at which an entity climbs up or down a
+	// climbable block, in blocks per update tick.
+	climbSpeed = 0.05
+
+	// ClimbReach is how far, in blocks, a climbable block can be from the
+	// side of an entity's AABB for the entity to hold on to it. Climbable
+	// blocks that are also solid can't overlap the entity, so the entity
+	// needs to be able to reach them from next to them.
+	climbReach = 0.05
+)
+
+// TouchingClimbable returns true if any climbable block (like a ladder)
+// overlaps the entity's AABB, or is right next to it horizontally.
+func (e *Entity) touchingClimbable(w *world.World) bool {
+	reach := math.AABB{Center: e.AABB.Center,
+		Size: e.AABB.Size.Add(mgl32.Vec3{2.0 * climbReach, 0.0,
+			2.0 * climbReach})}
+	x1, y1, z1 := world.ToWorldSpace(reach.MinX(), reach.MinY(), reach.MinZ())
+	x2, y2, z2 := world.ToWorldSpace(reach.MaxX(), reach.MaxY(), reach.MaxZ())
+	y1, y2 = w.ClampHeight(y1, y2)
+	for x := x1; x <= x2; x++ {
+		for y := y1; y <= y2; y++ {
+			for z := z1; z <= z2; z++ {
+				block, ok := w.GetBlock(x, y, z)
+				if ok && w.GetBlockInfo(block).Climbable {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ApplyClimbing works out the entity's vertical velocity for this tick while
+// it's holding on to a climbable block, instead of the usual gravity. Moving
+// forwards climbs up and moving backwards climbs down; otherwise the entity
+// stays where it is.
+func (e *Entity) applyClimbing() {
+	vy := float32(0.0)
+	if e.climbInput > 0.0 {
+		vy = climbSpeed
+	} else if e.climbInput < 0.0 {
+		vy = -climbSpeed
+	}
+	e.velocity = mgl32.Vec3{e.velocity.X(), vy, e.velocity.Z()}
+}
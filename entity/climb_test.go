@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// BuildLadder places a column of ladders on the floor of a test world, up
+// against a cobblestone wall on their far side along the negative z axis, so
+// an entity walking into the ladders stops against the wall.
+func buildLadder(t *testing.T, w *world.World, x, z, height int) {
+	t.Helper()
+	for y := 0; y < height; y++ {
+		setBlock(t, w, x, testFloorY+y, z, "Ladder")
+		setBlock(t, w, x, testFloorY+y, z-1, "Cobblestone")
+	}
+}
+
+func TestLadderIsClimbable(t *testing.T) {
+	w := newTestWorld()
+	block, ok := w.FindBlock("Ladder")
+	if !ok {
+		t.Fatal("no block named \"Ladder\"")
+	}
+	if info := w.GetBlockInfo(block); !info.Climbable || info.Collidable {
+		t.Error("ladders aren't climbable, or entities can't walk into them")
+	}
+}
+
+func TestTouchingClimbable(t *testing.T) {
+	w := newTestWorld()
+	buildLadder(t, w, 0, -1, 4)
+
+	// The entity's AABB is right up against the ladder
+	near := newTestEntity(standingAt(0.5, PlayerWidth/2.0))
+	if !near.touchingClimbable(w) {
+		t.Error("entity against the ladder isn't touching it")
+	}
+	far := newTestEntity(standingAt(0.5, 1.5))
+	if far.touchingClimbable(w) {
+		t.Error("entity away from the ladder is touching it")
+	}
+}
+
+func TestApplyClimbing(t *testing.T) {
+	tests := []struct {
+		input, want float32
+	}{
+		{1.0, climbSpeed},
+		{0.3, climbSpeed},
+		{0.0, 0.0},
+		{-1.0, -climbSpeed},
+	}
+	for _, test := range tests {
+		e := newTestEntity(standingAt(0.5, 0.5))
+		e.velocity = mgl32.Vec3{0.1, -0.5, 0.2}
+		e.climbInput = test.input
+		e.applyClimbing()
+		want := mgl32.Vec3{0.1, test.want, 0.2}
+		if e.velocity != want {
+			t.Errorf("got velocity %v climbing with input %v, want %v",
+				e.velocity, test.input, want)
+		}
+	}
+}
+
+func TestWalkingIntoLadderClimbsIt(t *testing.T) {
+	w := newTestWorld()
+	buildLadder(t, w, 0, -1, 4)
+	e := newTestEntity(standingAt(0.5, 0.5))
+	tick(e, w, 10)
+
+	// Walk forward (down the negative z axis) into the ladder
+	for i := 0; i < 40; i++ {
+		e.Move(mgl32.Vec3{0.0, 0.0, 1.0})
+		e.ApplyMovementAndResolveCollisions(w)
+	}
+	if got := e.AABB.MinY(); got < testFloorY+1.0 {
+		t.Errorf("entity's feet at y = %v, want climbed above %v", got,
+			testFloorY+1.0)
+	}
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"github.com/benanders/mineral/math"
+	"github.com/benanders/mineral/world"
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// DropSize is the length of each side of a dropped block's AABB, in
+	// blocks.
+	DropSize = 0.25
+
+	// PickupRadius is the distance from the centre of the player, in blocks,
+	// within which drops are pulled towards the player to be picked up.
+	PickupRadius = 2.0
+
+	// The speed at which drops within the pickup radius move towards the
+	// player, in blocks per update tick.
+	dropPullSpeed = 0.15
+
+	// The number of update ticks after a block is dropped before it can be
+	// picked up, so the player sees it drop before it flies towards them.
+	dropPickupDelay = 30
+)
+
+// Drop is a block lying in the world after being broken, which falls under
+// gravity until the player comes close enough to pick it up.
+type Drop struct {
+	Entity
+
+	// The type of block that's added to the player's inventory when the drop
+	// is picked up
+	Block world.Block
+
+	// The number of update ticks since the block was dropped
+	age int
+}
+
+// Collector stores the blocks picked up from drops (e.g. the player's
+// hotbar).
+type Collector interface {
+	// Add stores a single block, returning false if there's no room for it.
+	Add(block world.Block) bool
+}
+
+// NewDrop creates a dropped block of the given type, centred at `center`.
+// Drops can't be hurt, and don't move by themselves.
+func NewDrop(block world.Block, center mgl32.Vec3) *Drop {
+	size := mgl32.Vec3{DropSize, DropSize, DropSize}
+	aabb := math.AABB{Center: center, Size: size}
+	entity := NewEntity(aabb, mgl32.Vec2{}, 0.0, 0.0)
+	entity.Invulnerable = true
+	return &Drop{Entity: *entity, Block: block.Type()}
+}
+
+// CanBePickedUp returns true if the drop has been lying around for long
+// enough to be picked up.
+func (d *Drop) canBePickedUp() bool {
+	return d.age >= dropPickupDelay
+}
+
+// PullTowards moves the drop towards the centre of the player during the next
+// update tick, if it's within the pickup radius.
+func (d *Drop) pullTowards(player *Player) {
+	offset := player.AABB.Center.Sub(d.AABB.Center)
+	dist := offset.Len()
+	if dist > PickupRadius || dist == 0.0 {
+		return
+	}
+	// Don't overshoot the player's centre
+	step := offset.Mul(math32.Min(dropPullSpeed/dist, 1.0))
+	d.moveDelta = d.moveDelta.Add(step)
+}
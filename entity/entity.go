@@ -28,12 +28,84 @@ type Entity struct {
 	moveSpeed float32 // The speed at which the entity can move around
 	lookSpeed float32 // The speed at which the entity can look around
 
+	// AutoJump makes the entity automatically jump up onto single block steps
+	// that block its horizontal movement while it's on the ground. This is an
+	// accessibility option, and is off by default.
+	AutoJump bool
+
+	// Flying entities aren't affected by gravity, can move straight up and
+	// down, and move faster than when they're walking.
+	Flying bool
+
+	// Sprinting entities move faster than when they're walking.
+	Sprinting bool
+
+	// Sneaking entities move slower than when they're walking, and won't walk
+	// off the edge of a block while they're on the ground.
+	Sneaking bool
+
 	// We aggregate all movement over an update tick before applying the
 	// movement delta and performing collision detection.
 	//
 	// `moveDelta` is in world coordinate space, so we can just sum the current
 	// position with the delta to get the new position.
 	moveDelta mgl32.Vec3
+
+	// The entity's velocity, in blocks per update tick, which is added to the
+	// movement delta every tick. Only gravity and jumping affect the velocity
+	// at the moment.
+	velocity mgl32.Vec3
+
+	// True if the entity landed on a solid block during the most recent update
+	// tick.
+	onGround bool
+
+	// How much of the entity is submerged in a fluid as of the most recent
+	// update tick, from 0 to 1 (see `submersion`), and whether the entity is
+	// swimming upwards during the next tick
+	submerged float32
+	swimUp    bool
+
+	// True if the entity's horizontal movement was blocked by a solid block
+	// during the most recent update tick
+	againstWall bool
+
+	// True if the entity is holding on to a climbable block during the
+	// current update tick, and how far it's moving forwards (positive) or
+	// backwards (negative) this tick, which climbs up or down (see
+	// `applyClimbing`)
+	climbing   bool
+	climbInput float32
+
+	// The entity's health, from `MaxHealth` down to 0. Entities are hurt by
+	// falling too far
+	Health float32
+
+	// Invulnerable entities never take any damage (e.g. the player in
+	// creative mode).
+	Invulnerable bool
+
+	// True while the entity is in the air and hasn't been stopped by flying or
+	// swimming, in which case `fallPeak` is the height of the bottom of the
+	// entity's AABB at the highest point it's reached since it left the
+	// ground (see `updateFall`)
+	falling  bool
+	fallPeak float32
+
+	// The horizontal distance walked along the ground since the entity's last
+	// footstep, and whether the entity took a step during the most recent
+	// update tick (see `updateFootsteps`)
+	stepDistance float32
+	stepped      bool
+
+	// The number of update ticks since the entity's physics was last updated,
+	// while its physics is being batched (see `PhysicsBatcher`)
+	skippedTicks int
+
+	// The centre of the entity's AABB before the most recent update tick. The
+	// entity is rendered somewhere between this and its current position, so
+	// it moves smoothly even when rendering faster than the tick rate.
+	prevCenter mgl32.Vec3
 }
 
 // NewEntity creates a new instance of the entity with an initial position,
@@ -41,7 +113,7 @@ type Entity struct {
 func NewEntity(aabb math.AABB, rotation mgl32.Vec2, moveSpeed,
 	lookSpeed float32) *Entity {
 	e := Entity{AABB: aabb, Rotation: rotation, moveSpeed: moveSpeed,
-		lookSpeed: lookSpeed}
+		lookSpeed: lookSpeed, Health: MaxHealth, prevCenter: aabb.Center}
 	e.updateAxes()
 	return &e
 }
@@ -51,16 +123,29 @@ func NewEntity(aabb math.AABB, rotation mgl32.Vec2, moveSpeed,
 //
 // Implements the `ctrl.Controllable` interface.
 func (e *Entity) Move(delta mgl32.Vec3) {
+	// Flying and sprinting entities move faster
+	speed := e.moveSpeed
+	if e.Flying {
+		speed *= flyingSpeedMultiplier
+	}
+	if e.Sprinting {
+		speed *= sprintSpeedMultiplier
+	}
+	if e.Sneaking {
+		speed *= sneakSpeedMultiplier
+	}
+
 	// Calculate how much we need to move along each of the entity's axes based
 	// on the delta
-	forward := e.forward.Mul(delta.Z() * e.moveSpeed)
-	right := e.right.Mul(delta.X() * e.moveSpeed)
-	up := e.up.Mul(delta.Y() * e.moveSpeed)
+	forward := e.forward.Mul(delta.Z() * speed)
+	right := e.right.Mul(delta.X() * speed)
+	up := e.up.Mul(delta.Y() * speed)
 
 	// Calculate the delta in world coordinates by summing the deltas along the
 	// 3 entity axes
 	worldDelta := forward.Add(right.Add(up))
 	e.moveDelta = e.moveDelta.Add(worldDelta)
+	e.climbInput += delta.Z()
 }
 
 // Look rotates the entity's look direction by a certain amount in the
@@ -98,104 +183,409 @@ func (e *Entity) updateAxes() {
 	e.Sight = mgl32.Vec3{cosY * -sinX, sinY, cosY * cosX}
 }
 
-// CollisionAxis represents an axis along which we can resolve a collision.
-type collisionAxis uint
-
 const (
-	// The three possible collision axes are the x, y, and z axes.
-	axisX collisionAxis = iota
-	axisY
-	axisZ
+	// Gravity is the downward acceleration applied to every entity, in blocks
+	// per update tick squared.
+	Gravity = 0.008
+
+	// TerminalVelocity is the maximum speed at which an entity can fall, in
+	// blocks per update tick.
+	TerminalVelocity = 0.8
+
+	// StepEpsilon is the extra distance above a single block step that an
+	// entity must be able to fit in order to automatically jump onto it.
+	stepEpsilon = 0.01
+
+	// FlyingSpeedMultiplier is the factor by which an entity's move speed is
+	// increased while it's flying.
+	flyingSpeedMultiplier = 2.0
+
+	// SprintSpeedMultiplier is the factor by which an entity's move speed is
+	// increased while it's sprinting.
+	sprintSpeedMultiplier = 1.3
+
+	// SneakSpeedMultiplier is the factor by which an entity's move speed is
+	// reduced while it's sneaking.
+	sneakSpeedMultiplier = 0.3
+
+	// LedgeEpsilon is the depth of the region below a sneaking entity that
+	// must contain a solid block for the entity to be standing on a ledge.
+	ledgeEpsilon = 0.05
+
+	// StepHeight is the tallest obstacle, in blocks, that a walking entity
+	// automatically steps up onto.
+	stepHeight = 1.0
+
+	// JumpVelocity is the upward velocity given to an entity when it jumps, in
+	// blocks per update tick. This is just enough to jump onto a block one
+	// higher than the entity.
+	JumpVelocity = 0.14
 )
 
+// Jump makes the entity jump, if it's standing on the ground. Entities can't
+// jump again until they've landed. An entity in a fluid swims upwards instead,
+// for as long as it keeps jumping.
+//
+// Implements the `ctrl.Controllable` interface.
+func (e *Entity) Jump() {
+	if e.submerged > 0.0 && !e.onGround {
+		e.swimUp = true
+		return
+	}
+	if !e.onGround {
+		return
+	}
+	e.velocity = mgl32.Vec3{e.velocity.X(), JumpVelocity, e.velocity.Z()}
+	e.onGround = false
+}
+
+// IsFlying returns true if the entity is flying, rather than walking.
+//
+// Implements the `ctrl.Controllable` interface.
+func (e *Entity) IsFlying() bool {
+	return e.Flying
+}
+
+// SetFlying switches the entity between flying and walking. An entity that
+// stops flying starts falling from rest.
+//
+// Implements the `ctrl.Controllable` interface.
+func (e *Entity) SetFlying(flying bool) {
+	e.Flying = flying
+	e.velocity = mgl32.Vec3{}
+}
+
+// SetSprinting switches the entity between sprinting and walking.
+//
+// Implements the `ctrl.Controllable` interface.
+func (e *Entity) SetSprinting(sprinting bool) {
+	e.Sprinting = sprinting
+}
+
+// SetSneaking switches the entity between sneaking and walking.
+//
+// Implements the `ctrl.Controllable` interface.
+func (e *Entity) SetSneaking(sneaking bool) {
+	e.Sneaking = sneaking
+}
+
+// OnGround returns true if the entity is standing on a solid block, as of the
+// most recent update tick.
+func (e *Entity) OnGround() bool {
+	return e.onGround
+}
+
+// InterpolatedCenter returns the centre of the entity's AABB partway between
+// its position before and after the most recent update tick. `alpha` is how
+// far through the current tick we are, from 0 (the previous position) to 1
+// (the current position).
+func (e *Entity) InterpolatedCenter(alpha float32) mgl32.Vec3 {
+	return e.prevCenter.Add(e.AABB.Center.Sub(e.prevCenter).Mul(alpha))
+}
+
 // ApplyMovementAndResolveCollisions applies the accumulated movement delta
 // that's been collected since the previous update tick, and resolves
 // collisions between the entity and all solid blocks in the world.
 func (e *Entity) ApplyMovementAndResolveCollisions(w *world.World) {
-	// X axis
-	e.AABB.Offset(mgl32.Vec3{e.moveDelta.X(), 0.0, 0.0})
-	e.resolveBlockCollisions(w, axisX)
+	// Remember where the entity was, for interpolation
+	e.prevCenter = e.AABB.Center
+
+	// Entities hold on to any climbable block they're touching, unless
+	// they're flying
+	e.climbing = !e.Flying && e.touchingClimbable(w)
+
+	// Jump onto any single block step that's in the way
+	horizontal := mgl32.Vec3{e.moveDelta.X(), 0.0, e.moveDelta.Z()}
+	if e.AutoJump && horizontal.Len() > 0.0 && e.onGround &&
+		e.blockedByStep(w, horizontal) {
+		e.Jump()
+	}
+
+	// Stop a sneaking entity from walking off the edge of the block it's
+	// standing on. This has no effect once the entity is in the air
+	if e.Sneaking && e.onGround && !e.Flying {
+		e.stayOnLedge(w)
+	}
+
+	// Accelerate downwards due to gravity, up to the terminal velocity, and
+	// move by the entity's velocity. Flying entities aren't affected by
+	// gravity, climbing entities move up and down at a fixed speed, and
+	// fluids hold up and slow down entities in them
+	//
+	// An entity whose physics was skipped for some ticks (see `skipTick`)
+	// catches up on its velocity for each of them too
+	ticks := e.skippedTicks + 1
+	e.skippedTicks = 0
+	if !e.Flying {
+		for i := 0; i < ticks; i++ {
+			if e.climbing {
+				e.applyClimbing()
+			} else if e.submerged > 0.0 {
+				e.applySwimming()
+			} else {
+				vy := math32.Max(e.velocity.Y()-Gravity, -TerminalVelocity)
+				e.velocity = mgl32.Vec3{e.velocity.X(), vy, e.velocity.Z()}
+			}
+			e.moveDelta = e.moveDelta.Add(e.velocity)
+		}
+		if !e.climbing && e.submerged > 0.0 {
+			e.slowSwimming()
+		}
+	}
+	e.swimUp = false
+	e.climbInput = 0.0
+
+	// Move the entity, sliding along any blocks it hits
+	start := e.AABB
+	wasOnGround := e.onGround
+	normal := moveAndSlide(w, &e.AABB, e.moveDelta)
+
+	// Walking into a single block step lifts the entity up onto it
+	blocked := normal.X() != 0.0 || normal.Z() != 0.0
+	e.againstWall = blocked
+	if blocked && wasOnGround && !e.Flying {
+		if stepped, ok := stepUp(w, start, e.AABB, e.moveDelta); ok {
+			// The entity is left standing on top of the step
+			e.AABB = stepped
+			normal = mgl32.Vec3{0.0, 1.0, 0.0}
+		}
+	}
 
-	// Y axis
-	e.AABB.Offset(mgl32.Vec3{0.0, e.moveDelta.Y(), 0.0})
-	e.resolveBlockCollisions(w, axisY)
+	// Hitting the ground (or the ceiling) stops the entity moving vertically,
+	// so it rests on the ground rather than accumulating speed. The entity is
+	// only on the ground if it landed during this tick
+	e.onGround = false
+	if normal.Y() != 0.0 {
+		e.onGround = normal.Y() > 0.0
+		e.velocity = mgl32.Vec3{e.velocity.X(), 0.0, e.velocity.Z()}
+	}
 
-	// Z axis
-	e.AABB.Offset(mgl32.Vec3{0.0, 0.0, e.moveDelta.Z()})
-	e.resolveBlockCollisions(w, axisZ)
+	// Falling too far hurts, and walking along the ground makes footsteps
+	e.updateFall(w)
+	e.updateFootsteps()
+	e.submerged = e.submersion(w)
 
 	// Reset the movement delta
 	e.moveDelta = mgl32.Vec3{}
 }
 
-// ResolveBlockCollisions checks to see if the entity is colliding with any
-// solid blocks in the world, and if so resolves the collision by moving
-// the entity along the specified axis.
-func (e *Entity) resolveBlockCollisions(w *world.World, axis collisionAxis) {
-	// Calculate the bounds of the entity's AABB in block coordinates
-	x1, y1, z1 := world.ToWorldSpace(e.AABB.MinX(), e.AABB.MinY(),
-		e.AABB.MinZ())
-	x2, y2, z2 := world.ToWorldSpace(e.AABB.MaxX(), e.AABB.MaxY(),
-		e.AABB.MaxZ())
+// SkipTick is used instead of `ApplyMovementAndResolveCollisions` on the update
+// ticks in which the entity's physics isn't updated. The entity stays where it
+// is, and catches up on the skipped ticks the next time it's updated.
+func (e *Entity) skipTick() {
+	e.prevCenter = e.AABB.Center
+	e.skippedTicks++
+}
+
+// StayOnLedge cancels the horizontal components of the entity's movement delta
+// that would leave the entity with no solid block beneath it.
+func (e *Entity) stayOnLedge(w *world.World) {
+	dx, dz := e.moveDelta.X(), e.moveDelta.Z()
+
+	// Check the X and Z components separately, so the entity can still slide
+	// along the edge of a ledge
+	moved := e.AABB
+	moved.Offset(mgl32.Vec3{dx, 0.0, 0.0})
+	if !hasGroundBelow(w, moved) {
+		dx = 0.0
+	}
+	moved = e.AABB
+	moved.Offset(mgl32.Vec3{dx, 0.0, dz})
+	if !hasGroundBelow(w, moved) {
+		dz = 0.0
+	}
+	e.moveDelta = mgl32.Vec3{dx, e.moveDelta.Y(), dz}
+}
+
+// HasGroundBelow returns true if there's a solid block directly beneath any
+// part of the given AABB.
+func hasGroundBelow(w *world.World, aabb math.AABB) bool {
+	// Check a thin slab just below the AABB's footprint. The footprint is
+	// shrunk slightly, since blocks the AABB only just overlaps horizontally
+	// don't stop it falling (see `math.SweepAABB`)
+	below := math.AABB{
+		Center: mgl32.Vec3{aabb.Center.X(), aabb.MinY() - ledgeEpsilon/2.0,
+			aabb.Center.Z()},
+		Size: mgl32.Vec3{aabb.Size.X() - 2.0*math.SweepEpsilon, ledgeEpsilon,
+			aabb.Size.Z() - 2.0*math.SweepEpsilon},
+	}
+	return collidesWithBlocks(w, below)
+}
+
+// BlockedByStep returns true if moving the entity horizontally by `delta`
+// would be blocked by an obstacle that's at most one block tall, with enough
+// room above it for the entity to fit.
+func (e *Entity) blockedByStep(w *world.World, delta mgl32.Vec3) bool {
+	// Check the movement is actually blocked
+	moved := e.AABB
+	moved.Offset(delta)
+	if !collidesWithBlocks(w, moved) {
+		return false
+	}
 
-	// Iterate over all blocks that overlap the entity
+	// Check there's room to move the entity up a block, and then forward.
+	// Raise the entity slightly more than a block to avoid floating point
+	// error making it look like it's still touching the top of the step
+	raised := e.AABB
+	raised.Offset(mgl32.Vec3{0.0, 1.0 + stepEpsilon, 0.0})
+	if collidesWithBlocks(w, raised) {
+		return false
+	}
+	raised.Offset(delta)
+	return !collidesWithBlocks(w, raised)
+}
+
+// CollidesWithBlocks returns true if the given AABB overlaps any solid blocks
+// in the world.
+func collidesWithBlocks(w *world.World, aabb math.AABB) bool {
+	// Calculate the bounds of the AABB in block coordinates
+	x1, y1, z1 := world.ToWorldSpace(aabb.MinX(), aabb.MinY(), aabb.MinZ())
+	x2, y2, z2 := world.ToWorldSpace(aabb.MaxX(), aabb.MaxY(), aabb.MaxZ())
+	y1, y2 = w.ClampHeight(y1, y2)
+
+	// Check every block that overlaps the AABB
 	for x := x1; x <= x2; x++ {
 		for y := y1; y <= y2; y++ {
 			for z := z1; z <= z2; z++ {
-				e.resolveBlockCollision(w, axis, x, y, z)
+				block, ok := w.GetBlock(x, y, z)
+				if !ok {
+					continue
+				}
+				info := w.GetBlockInfo(block)
+				if !info.Collidable {
+					continue
+				}
+				p, q, cx, cy, cz := world.ToChunkSpace(x, y, z)
+				for _, box := range info.AABBs(p, q, cx, cy, cz) {
+					if aabb.Intersects(box) {
+						return true
+					}
+				}
 			}
 		}
 	}
+	return false
 }
 
-// ResolveBlockCollision checks to see if the entity is colliding with the
-// given block, and if so resolves the collision with this block by moving
-// the entity along the specified axis.
-func (e *Entity) resolveBlockCollision(w *world.World, axis collisionAxis,
-	x, y, z int) {
-	// Get the chunk containing the block
-	p, q, cx, cy, cz := world.ToChunkSpace(x, y, z)
-	chunk := w.FindChunk(p, q)
+// MaxSlideIterations is the maximum number of times an entity's movement is
+// redirected along the surfaces it hits in a single update tick. An entity
+// can hit at most one surface along each axis.
+const maxSlideIterations = 3
 
-	// Don't bother detecting collisions with chunks that haven't loaded
-	if chunk == nil || chunk.Blocks == nil {
-		return
-	}
+// MoveAndSlide moves an AABB by `delta`, stopping it at the first solid block
+// in its path. Any remaining movement is redirected along the surface of the
+// block, so entities slide along walls and floors rather than sticking to
+// them.
+//
+// Since the AABB's path is swept through the world, it can't pass through
+// blocks no matter how far it moves in a single tick.
+//
+// Returns a vector with a non-zero component along each axis on which the
+// AABB hit a block, pointing away from the surface that was hit.
+func moveAndSlide(w *world.World, aabb *math.AABB,
+	delta mgl32.Vec3) mgl32.Vec3 {
+	var hit mgl32.Vec3
+	for i := 0; i < maxSlideIterations && delta.Len() > 0.0; i++ {
+		// Move as far as we can before hitting something
+		t, normal := sweepBlocks(w, *aabb, delta)
+		aabb.Offset(delta.Mul(t))
+		if normal.Len() == 0.0 {
+			break
+		}
+		hit = hit.Add(normal)
 
-	// Get the block we're checking for collisions against
-	block := chunk.Blocks.At(cx, cy, cz)
-	if block == nil {
-		return
+		// Remove the part of the remaining movement going into the surface
+		delta = delta.Mul(1.0 - t)
+		for axis := 0; axis < 3; axis++ {
+			if normal[axis] != 0.0 {
+				delta[axis] = 0.0
+			}
+		}
 	}
+	return hit
+}
 
-	// Check the block we're colliding against is solid
-	info := w.GetBlockInfo(*block)
-	if !info.Collidable {
-		return
-	}
+// StepUp tries to repeat a horizontal movement that was blocked, starting
+// from the AABB `start` but first raising it by up to the step height, and
+// then lowering it back down onto whatever it stepped onto. `blocked` is where
+// the AABB ended up without stepping.
+//
+// Returns the stepped AABB, and true if stepping moved the AABB further
+// horizontally than it could move without stepping. Obstacles taller than the
+// step height block the stepped movement too, so they can't be climbed.
+func stepUp(w *world.World, start, blocked math.AABB,
+	delta mgl32.Vec3) (math.AABB, bool) {
+	// Raise the AABB as far as it'll go, up to the step height
+	stepped := start
+	moveAndSlide(w, &stepped, mgl32.Vec3{0.0, stepHeight, 0.0})
+	raised := stepped.Center.Y() - start.Center.Y()
 
-	// Resolve a collision with the block
-	aabb := info.AABB(p, q, cx, cy, cz)
-	e.resolveCollision(aabb, axis)
+	// Move horizontally, and then back down
+	horizontal := mgl32.Vec3{delta.X(), 0.0, delta.Z()}
+	moveAndSlide(w, &stepped, horizontal)
+	moveAndSlide(w, &stepped, mgl32.Vec3{0.0, -raised, 0.0})
+
+	// Only step if it gets us further
+	steppedDist := horizontalDistance(start, stepped)
+	blockedDist := horizontalDistance(start, blocked)
+	return stepped, steppedDist > blockedDist+math.SweepEpsilon
 }
 
-// ResolveCollision checks to see if the entity is colliding with the given
-// AABB, and if so resolves the collision by moving the entity along the
-// specified axis.
-func (e *Entity) resolveCollision(other math.AABB, axis collisionAxis) {
-	// Check the entity's AABB intersects the other AABB
-	if !e.AABB.Intersects(other) {
-		return
-	}
+// HorizontalDistance returns the distance between the centres of two AABBs,
+// ignoring the y axis.
+func horizontalDistance(a, b math.AABB) float32 {
+	dx := b.Center.X() - a.Center.X()
+	dz := b.Center.Z() - a.Center.Z()
+	return math32.Sqrt(dx*dx + dz*dz)
+}
+
+// SweepBlocks finds the earliest time at which the given AABB hits a solid
+// block, if it's moved by `delta`. Returns the time as a fraction of the
+// movement, and the surface normal of the block that was hit (see
+// `math.SweepAABB`).
+func sweepBlocks(w *world.World, aabb math.AABB,
+	delta mgl32.Vec3) (float32, mgl32.Vec3) {
+	// Find the bounds, in block coordinates, of the region swept out by the
+	// AABB
+	moved := aabb
+	moved.Offset(delta)
+	x1, y1, z1 := world.ToWorldSpace(
+		math32.Min(aabb.MinX(), moved.MinX()),
+		math32.Min(aabb.MinY(), moved.MinY()),
+		math32.Min(aabb.MinZ(), moved.MinZ()))
+	x2, y2, z2 := world.ToWorldSpace(
+		math32.Max(aabb.MaxX(), moved.MaxX()),
+		math32.Max(aabb.MaxY(), moved.MaxY()),
+		math32.Max(aabb.MaxZ(), moved.MaxZ()))
 
-	// Resolve the collision along the specified axis
-	var offset mgl32.Vec3
-	if axis == axisX {
-		offset = mgl32.Vec3{-e.AABB.IntersectionX(other), 0.0, 0.0}
-	} else if axis == axisY {
-		offset = mgl32.Vec3{0.0, -e.AABB.IntersectionY(other), 0.0}
-	} else if axis == axisZ {
-		offset = mgl32.Vec3{0.0, 0.0, -e.AABB.IntersectionZ(other)}
+	// There are no blocks above or below the world, so an entity far outside
+	// it doesn't need to check any
+	y1, y2 = w.ClampHeight(y1, y2)
+
+	// Find the earliest collision with any solid block in the region. Blocks
+	// in chunks that haven't loaded yet aren't solid
+	earliest := float32(1.0)
+	var normal mgl32.Vec3
+	for x := x1; x <= x2; x++ {
+		for y := y1; y <= y2; y++ {
+			for z := z1; z <= z2; z++ {
+				block, ok := w.GetBlock(x, y, z)
+				if !ok {
+					continue
+				}
+				info := w.GetBlockInfo(block)
+				if !info.Collidable {
+					continue
+				}
+				p, q, cx, cy, cz := world.ToChunkSpace(x, y, z)
+				for _, box := range info.AABBs(p, q, cx, cy, cz) {
+					t, n := math.SweepAABB(aabb, delta, box)
+					if t < earliest {
+						earliest, normal = t, n
+					}
+				}
+			}
+		}
 	}
-	e.AABB.Offset(offset)
+	return earliest, normal
 }
@@ -0,0 +1,346 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/math"
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The height of the worlds created for testing. Generated chunks have a
+// floor of stone whose top is at y = 3.
+const (
+	testHeight = 32
+	testFloorY = 3.0
+)
+
+// NewTestWorld creates a headless world with the chunks around the origin
+// loaded, so entities can walk around on its floor.
+func newTestWorld() *world.World {
+	w := world.NewHeadless(testHeight, 1)
+	for p := -2; p <= 2; p++ {
+		for q := -2; q <= 2; q++ {
+			w.LoadChunkHeadless(p, q)
+		}
+	}
+	return w
+}
+
+// NewTestEntity creates an entity the size of the player centred at the given
+// position, looking down the negative z axis.
+func newTestEntity(center mgl32.Vec3) *Entity {
+	aabb := math.AABB{Center: center,
+		Size: mgl32.Vec3{PlayerWidth, PlayerHeight, PlayerWidth}}
+	return NewEntity(aabb, mgl32.Vec2{}, playerMoveSpeed, playerLookSpeed)
+}
+
+// StandingAt returns the centre of an entity the size of the player standing
+// on the floor of a test world at the given horizontal position.
+func standingAt(x, z float32) mgl32.Vec3 {
+	return mgl32.Vec3{x, testFloorY + PlayerHeight/2.0, z}
+}
+
+// SetBlock changes a block in a test world, failing the test if the block
+// isn't loaded.
+func setBlock(t *testing.T, w *world.World, x, y, z int, name string) {
+	t.Helper()
+	block, ok := w.FindBlock(name)
+	if !ok {
+		t.Fatalf("no block named %q", name)
+	}
+	if !w.SetBlock(x, y, z, block) {
+		t.Fatalf("failed to set block at (%d, %d, %d)", x, y, z)
+	}
+}
+
+// Tick runs an entity's physics for the given number of update ticks.
+func tick(e *Entity, w *world.World, ticks int) {
+	for i := 0; i < ticks; i++ {
+		e.ApplyMovementAndResolveCollisions(w)
+	}
+}
+
+func TestEntityLandsOnFloor(t *testing.T) {
+	w := newTestWorld()
+	e := newTestEntity(mgl32.Vec3{0.5, 10.0, 0.5})
+	tick(e, w, 200)
+	if !e.OnGround() {
+		t.Error("entity isn't on the ground")
+	}
+	if got := e.AABB.MinY(); mgl32.Abs(got-testFloorY) > 0.01 {
+		t.Errorf("entity's feet at y = %v, want %v", got, testFloorY)
+	}
+}
+
+func TestEntityRestsOnBlock(t *testing.T) {
+	w := newTestWorld()
+	setBlock(t, w, 0, 8, 0, "Stone")
+	e := newTestEntity(mgl32.Vec3{0.5, 20.0, 0.5})
+	tick(e, w, 200)
+
+	// The entity comes to rest on top of the block, rather than falling
+	// through it or building up speed while standing on it
+	if got := e.AABB.MinY(); mgl32.Abs(got-9.0) > 0.01 {
+		t.Errorf("entity's feet at y = %v, want 9", got)
+	}
+	if e.velocity.Y() != 0.0 {
+		t.Errorf("got vertical velocity %v at rest, want 0", e.velocity.Y())
+	}
+	before := e.AABB.Center
+	tick(e, w, 50)
+	if e.AABB.Center != before {
+		t.Errorf("resting entity moved from %v to %v", before, e.AABB.Center)
+	}
+}
+
+func TestOnGroundAndJumping(t *testing.T) {
+	w := newTestWorld()
+	e := newTestEntity(mgl32.Vec3{0.5, 10.0, 0.5})
+	tick(e, w, 1)
+	if e.OnGround() {
+		t.Error("entity is on the ground in mid-air")
+	}
+
+	// Jumping in mid-air does nothing
+	e.Jump()
+	if e.velocity.Y() > 0.0 {
+		t.Errorf("got vertical velocity %v jumping in mid-air",
+			e.velocity.Y())
+	}
+	tick(e, w, 200)
+	if !e.OnGround() {
+		t.Fatal("entity isn't on the ground after landing")
+	}
+
+	// Jumping from the ground moves the entity upwards, and only once
+	e.Jump()
+	e.Jump()
+	if e.velocity.Y() != JumpVelocity {
+		t.Errorf("got vertical velocity %v after jumping, want %v",
+			e.velocity.Y(), JumpVelocity)
+	}
+	before := e.AABB.MinY()
+	tick(e, w, 1)
+	if e.AABB.MinY() <= before {
+		t.Errorf("entity's feet at y = %v after jumping, not above %v",
+			e.AABB.MinY(), before)
+	}
+	if e.OnGround() {
+		t.Error("entity is on the ground after jumping")
+	}
+}
+
+func TestFlyingIgnoresGravity(t *testing.T) {
+	w := newTestWorld()
+	start := mgl32.Vec3{0.5, 10.0, 0.5}
+	flying := newTestEntity(start)
+	flying.SetFlying(true)
+	walking := newTestEntity(start)
+	tick(flying, w, 100)
+	tick(walking, w, 100)
+	if flying.AABB.Center != start {
+		t.Errorf("flying entity moved from %v to %v", start,
+			flying.AABB.Center)
+	}
+	if walking.AABB.Center.Y() >= start.Y() {
+		t.Errorf("walking entity at y = %v didn't fall from %v",
+			walking.AABB.Center.Y(), start.Y())
+	}
+
+	// An entity that stops flying falls again
+	flying.SetFlying(false)
+	tick(flying, w, 1)
+	if flying.AABB.Center.Y() >= start.Y() {
+		t.Error("entity didn't fall after it stopped flying")
+	}
+}
+
+func TestAutoJumpClimbsStep(t *testing.T) {
+	w := newTestWorld()
+	setBlock(t, w, 0, 3, -2, "Stone")
+	e := newTestEntity(standingAt(0.5, 0.5))
+	e.AutoJump = true
+	tick(e, w, 10)
+
+	// Walk forward (down the negative z axis) into the step and onto it
+	for i := 0; i < 40; i++ {
+		e.Move(mgl32.Vec3{0.0, 0.0, 1.0})
+		e.ApplyMovementAndResolveCollisions(w)
+	}
+	if got := e.AABB.MinY(); mgl32.Abs(got-(testFloorY+1.0)) > 0.01 {
+		t.Errorf("entity's feet at y = %v, want %v", got, testFloorY+1.0)
+	}
+	if e.AABB.Center.Z() > -1.0 {
+		t.Errorf("entity stopped at z = %v, before the step", e.AABB.Center.Z())
+	}
+}
+
+// WalkForward moves an entity forward (down the negative z axis) for the given
+// number of update ticks, after letting it settle on the floor.
+func walkForward(e *Entity, w *world.World, ticks int) {
+	tick(e, w, 10)
+	for i := 0; i < ticks; i++ {
+		e.Move(mgl32.Vec3{0.0, 0.0, 1.0})
+		e.ApplyMovementAndResolveCollisions(w)
+	}
+}
+
+func TestStepUpOneBlockHigh(t *testing.T) {
+	w := newTestWorld()
+	buildPlatform(t, w, 0, -6, 0, -2)
+	e := newTestEntity(standingAt(0.5, 0.5))
+	walkForward(e, w, 40)
+	if got := e.AABB.MinY(); mgl32.Abs(got-(testFloorY+1.0)) > 0.01 {
+		t.Errorf("entity's feet at y = %v, want %v", got, testFloorY+1.0)
+	}
+	if e.AABB.Center.Z() > -1.0 {
+		t.Errorf("entity stopped at z = %v, before the step", e.AABB.Center.Z())
+	}
+}
+
+func TestStepUpBlockedByWall(t *testing.T) {
+	w := newTestWorld()
+	buildWall(t, w, 0, -2, 0, -2)
+	e := newTestEntity(standingAt(0.5, 0.5))
+	walkForward(e, w, 40)
+	if got := e.AABB.MinY(); mgl32.Abs(got-testFloorY) > 0.01 {
+		t.Errorf("entity's feet at y = %v, want %v", got, testFloorY)
+	}
+	if got := e.AABB.MinZ(); got < -1.0-0.01 {
+		t.Errorf("entity walked into the wall to z = %v", got)
+	}
+}
+
+func TestInterpolatedCenter(t *testing.T) {
+	w := newTestWorld()
+	e := newTestEntity(standingAt(0.5, 0.5))
+	tick(e, w, 10)
+	before := e.AABB.Center
+	e.Move(mgl32.Vec3{0.0, 0.0, 1.0})
+	e.ApplyMovementAndResolveCollisions(w)
+	after := e.AABB.Center
+	if before == after {
+		t.Fatal("entity didn't move")
+	}
+
+	tests := []struct {
+		alpha float32
+		want  mgl32.Vec3
+	}{
+		{0.0, before},
+		{1.0, after},
+		{0.5, before.Add(after).Mul(0.5)},
+	}
+	for _, test := range tests {
+		got := e.InterpolatedCenter(test.alpha)
+		if !got.ApproxEqual(test.want) {
+			t.Errorf("got centre %v at alpha %v, want %v", got, test.alpha,
+				test.want)
+		}
+	}
+}
+
+// BuildPlatform places a platform of stone 1 block tall on the floor of a test
+// world, covering the blocks from (x1, z1) to (x2, z2) inclusive.
+func buildPlatform(t *testing.T, w *world.World, x1, z1, x2, z2 int) {
+	t.Helper()
+	for x := x1; x <= x2; x++ {
+		for z := z1; z <= z2; z++ {
+			setBlock(t, w, x, testFloorY, z, "Stone")
+		}
+	}
+}
+
+func TestSneakingStopsAtLedge(t *testing.T) {
+	w := newTestWorld()
+	buildPlatform(t, w, -2, -2, 2, 0)
+	top := float32(testFloorY + 1.0)
+	center := mgl32.Vec3{0.5, top + PlayerHeight/2.0, -0.5}
+
+	// Walk forward (down the negative z axis) towards the edge of the
+	// platform at z = -2
+	sneaking := newTestEntity(center)
+	sneaking.SetSneaking(true)
+	walking := newTestEntity(center)
+	for _, e := range []*Entity{sneaking, walking} {
+		tick(e, w, 5)
+		for i := 0; i < 100; i++ {
+			e.Move(mgl32.Vec3{0.0, 0.0, 1.0})
+			e.ApplyMovementAndResolveCollisions(w)
+		}
+	}
+
+	// The sneaking entity stays on the platform, with part of it still over
+	// the edge block
+	if got := sneaking.AABB.MinY(); mgl32.Abs(got-top) > 0.01 {
+		t.Errorf("sneaking entity's feet at y = %v, want %v", got, top)
+	}
+	if got := sneaking.AABB.MaxZ(); got <= -2.0 {
+		t.Errorf("sneaking entity walked past the edge to z = %v", got)
+	}
+
+	// The walking entity falls off
+	if got := walking.AABB.MinY(); got >= top {
+		t.Errorf("walking entity's feet at y = %v, want below %v", got, top)
+	}
+}
+
+// BuildWall places a wall of stone 2 blocks tall on the floor of a test world,
+// covering the blocks from (x1, z1) to (x2, z2) inclusive.
+func buildWall(t *testing.T, w *world.World, x1, z1, x2, z2 int) {
+	t.Helper()
+	for x := x1; x <= x2; x++ {
+		for z := z1; z <= z2; z++ {
+			setBlock(t, w, x, testFloorY, z, "Stone")
+			setBlock(t, w, x, testFloorY+1, z, "Stone")
+		}
+	}
+}
+
+func TestMoveAndSlideAlongWall(t *testing.T) {
+	w := newTestWorld()
+	buildWall(t, w, 3, -5, 3, 5)
+	aabb := newTestEntity(standingAt(1.5, 0.5)).AABB
+	start := aabb.Center
+
+	// Moving diagonally into the wall stops along x, but slides the whole way
+	// along z
+	hit := moveAndSlide(w, &aabb, mgl32.Vec3{3.0, 0.0, 2.0})
+	if hit.X() >= 0.0 || hit.Y() != 0.0 || hit.Z() != 0.0 {
+		t.Errorf("got hit normal %v, want one facing away from the wall", hit)
+	}
+	if got := aabb.MaxX(); got < 2.99 || got > 3.0+math.SweepEpsilon {
+		t.Errorf("entity stopped at x = %v, want against the wall at 3", got)
+	}
+	want := start.Z() + 2.0
+	if got := aabb.Center.Z(); mgl32.Abs(got-want) > 1e-4 {
+		t.Errorf("entity slid to z = %v, want %v", got, want)
+	}
+	if aabb.Center.Y() != start.Y() {
+		t.Errorf("entity moved vertically to y = %v", aabb.Center.Y())
+	}
+}
+
+func TestMoveAndSlideIntoInsideCorner(t *testing.T) {
+	w := newTestWorld()
+	buildWall(t, w, 3, -5, 3, 3)
+	buildWall(t, w, -5, 3, 3, 3)
+	aabb := newTestEntity(standingAt(1.5, 1.5)).AABB
+
+	// Moving into the corner stops against both walls, without passing
+	// through either of them
+	hit := moveAndSlide(w, &aabb, mgl32.Vec3{2.0, 0.0, 3.0})
+	if hit.X() >= 0.0 || hit.Z() >= 0.0 {
+		t.Errorf("got hit normal %v, want one facing away from both walls",
+			hit)
+	}
+	limit := float32(3.0) + math.SweepEpsilon
+	if aabb.MaxX() > limit || aabb.MaxZ() > limit {
+		t.Errorf("entity passed into the corner, to %v", aabb.Center)
+	}
+	if aabb.MaxX() < 2.99 || aabb.MaxZ() < 2.99 {
+		t.Errorf("entity stopped short of the corner, at %v", aabb.Center)
+	}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// FootstepDistance is the horizontal distance, in blocks, an entity walks
+// between footsteps. Since footsteps are measured by distance rather than
+// time, they come quicker the faster the entity moves (e.g. while sprinting).
+const footstepDistance = 1.7
+
+// UpdateFootsteps keeps track of how far the entity has walked along the
+// ground, and sets `stepped` if the entity took a step during this tick. It's
+// called after the entity has moved and collided with blocks. Entities don't
+// take steps while they're in the air, flying, or standing still.
+func (e *Entity) updateFootsteps() {
+	e.stepped = false
+	if !e.onGround || e.Flying {
+		return
+	}
+	moved := e.AABB.Center.Sub(e.prevCenter)
+	distance := mgl32.Vec2{moved.X(), moved.Z()}.Len()
+	if distance == 0.0 {
+		return
+	}
+	e.stepDistance += distance
+	if e.stepDistance >= footstepDistance {
+		e.stepDistance -= footstepDistance
+		e.stepped = true
+	}
+}
+
+// Stepped returns true if the entity took a step during the most recent
+// update tick, in which case a footstep sound should be played.
+func (e *Entity) Stepped() bool {
+	return e.stepped
+}
+
+// GroundBlock returns the block directly beneath the middle of the entity's
+// AABB, which the entity is standing on (if it's on the ground). Returns false
+// if the block isn't loaded.
+func (e *Entity) GroundBlock(w *world.World) (world.Block, bool) {
+	x, y, z := world.ToWorldSpace(e.AABB.Center.X(), e.AABB.MinY()-0.01,
+		e.AABB.Center.Z())
+	return w.GetBlock(x, y, z)
+}
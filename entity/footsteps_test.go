@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// StepTicks moves an entity along the x axis by `speed` blocks every update
+// tick for the given number of ticks, and returns the ticks on which it took
+// a step.
+func stepTicks(e *Entity, speed float32, ticks int) []int {
+	var steps []int
+	for i := 0; i < ticks; i++ {
+		e.prevCenter = e.AABB.Center
+		e.AABB.Center = e.AABB.Center.Add(mgl32.Vec3{speed, 0.0, 0.0})
+		e.updateFootsteps()
+		if e.Stepped() {
+			steps = append(steps, i)
+		}
+	}
+	return steps
+}
+
+func TestFootstepInterval(t *testing.T) {
+	speeds := []float32{0.1, 0.13, 0.3}
+	last := 0
+	for _, speed := range speeds {
+		e := newTestEntity(standingAt(0.5, 0.5))
+		e.onGround = true
+		steps := stepTicks(e, speed, 200)
+		if len(steps) < 2 {
+			t.Fatalf("took %d steps at a speed of %v", len(steps), speed)
+		}
+
+		// Steps are evenly spaced by the footstep distance, so come quicker
+		// the faster the entity moves
+		want := footstepDistance / speed
+		for i := 1; i < len(steps); i++ {
+			interval := float32(steps[i] - steps[i-1])
+			if mgl32.Abs(interval-want) > 1.0 {
+				t.Errorf("got %v ticks between steps at a speed of %v, want "+
+					"about %v", interval, speed, want)
+			}
+		}
+		if len(steps) <= last {
+			t.Errorf("took %d steps at a speed of %v, no more than at a "+
+				"slower speed", len(steps), speed)
+		}
+		last = len(steps)
+	}
+}
+
+func TestNoFootstepsWithoutWalking(t *testing.T) {
+	// Standing still on the ground
+	e := newTestEntity(standingAt(0.5, 0.5))
+	e.onGround = true
+	if steps := stepTicks(e, 0.0, 200); len(steps) != 0 {
+		t.Errorf("took %d steps standing still", len(steps))
+	}
+
+	// Moving through the air
+	e = newTestEntity(standingAt(0.5, 0.5))
+	if steps := stepTicks(e, 0.2, 200); len(steps) != 0 {
+		t.Errorf("took %d steps in the air", len(steps))
+	}
+
+	// Flying just above the ground
+	e = newTestEntity(standingAt(0.5, 0.5))
+	e.onGround = true
+	e.Flying = true
+	if steps := stepTicks(e, 0.2, 200); len(steps) != 0 {
+		t.Errorf("took %d steps while flying", len(steps))
+	}
+}
+
+func TestGroundBlock(t *testing.T) {
+	w := newTestWorld()
+	setBlock(t, w, 4, int(testFloorY), 4, "Glowstone")
+	e := newTestEntity(standingAt(4.5, 4.5).Add(mgl32.Vec3{0.0, 1.0, 0.0}))
+	glowstone, _ := w.FindBlock("Glowstone")
+	if block, ok := e.GroundBlock(w); !ok || block != glowstone {
+		t.Errorf("got ground block %v, want glowstone %v", block, glowstone)
+	}
+}
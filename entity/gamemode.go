@@ -0,0 +1,47 @@
+package entity
+
+// GameMode controls how the player interacts with the world.
+type GameMode int
+
+// All possible game modes.
+const (
+	// Survival players are affected by gravity, can't fly, take damage, and
+	// take time to break blocks.
+	Survival GameMode = iota
+
+	// Creative players fly, never take damage, and break blocks instantly.
+	Creative
+)
+
+// String returns the name of the game mode, for display.
+func (m GameMode) String() string {
+	if m == Creative {
+		return "Creative"
+	}
+	return "Survival"
+}
+
+// GameMode returns the player's current game mode.
+func (p *Player) GameMode() GameMode {
+	return p.mode
+}
+
+// SetGameMode switches the player's game mode. Players start flying when they
+// switch to creative, and stop flying (falling from where they are) when they
+// switch to survival.
+func (p *Player) SetGameMode(mode GameMode) {
+	p.mode = mode
+	p.Invulnerable = mode == Creative
+	p.Entity.SetFlying(mode == Creative)
+}
+
+// SetFlying switches the player between flying and walking. Only creative
+// players can fly.
+//
+// Implements the `ctrl.Controllable` interface.
+func (p *Player) SetFlying(flying bool) {
+	if flying && p.mode != Creative {
+		return
+	}
+	p.Entity.SetFlying(flying)
+}
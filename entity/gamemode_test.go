@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestSurvivalPlayersCantFly(t *testing.T) {
+	p := NewPlayer(standingAt(0.5, 0.5), mgl32.Vec2{})
+	if p.GameMode() != Survival {
+		t.Fatalf("player starts in %v, want survival", p.GameMode())
+	}
+	p.SetFlying(true)
+	if p.IsFlying() {
+		t.Error("survival player started flying")
+	}
+	p.TakeDamage(5.0)
+	if p.Health != MaxHealth-5.0 {
+		t.Errorf("survival player has %v health after 5 damage, want %v",
+			p.Health, MaxHealth-5.0)
+	}
+}
+
+func TestSwitchingGameMode(t *testing.T) {
+	p := NewPlayer(standingAt(0.5, 0.5), mgl32.Vec2{})
+
+	// Creative players fly straight away, and can't be hurt
+	p.SetGameMode(Creative)
+	if !p.IsFlying() {
+		t.Error("creative player isn't flying")
+	}
+	p.TakeDamage(5.0)
+	if p.Health != MaxHealth {
+		t.Errorf("creative player has %v health after 5 damage, want %v",
+			p.Health, MaxHealth)
+	}
+	p.SetFlying(false)
+	p.SetFlying(true)
+	if !p.IsFlying() {
+		t.Error("creative player can't start flying again")
+	}
+
+	// Switching back to survival stops the player flying
+	p.SetGameMode(Survival)
+	if p.IsFlying() || p.Invulnerable {
+		t.Error("survival player is still flying or invulnerable")
+	}
+}
+
+func TestGameModeNames(t *testing.T) {
+	if Survival.String() != "Survival" || Creative.String() != "Creative" {
+		t.Errorf("got game mode names %q and %q", Survival, Creative)
+	}
+}
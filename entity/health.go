@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"github.com/benanders/mineral/world"
+
+	"github.com/chewxy/math32"
+)
+
+const (
+	// MaxHealth is the health every entity starts with.
+	MaxHealth = 20.0
+
+	// SafeFallDistance is the furthest an entity can fall, in blocks, without
+	// taking any damage when it lands.
+	safeFallDistance = 3.0
+
+	// FallDamagePerBlock is the damage taken for each block fallen beyond the
+	// safe fall distance.
+	fallDamagePerBlock = 1.0
+)
+
+// TakeDamage reduces the entity's health by `amount`. Health never drops below
+// 0, and invulnerable entities aren't affected.
+func (e *Entity) TakeDamage(amount float32) {
+	if e.Invulnerable {
+		return
+	}
+	e.Health = math32.Max(e.Health-amount, 0.0)
+}
+
+// FallDamage returns the damage taken by an entity landing after falling
+// `distance` blocks. There's no damage for short falls.
+func fallDamage(distance float32) float32 {
+	return math32.Max(distance-safeFallDistance, 0.0) * fallDamagePerBlock
+}
+
+// UpdateFall keeps track of how far the entity has fallen since it was last
+// on the ground, and damages the entity when it lands. It's called after the
+// entity has moved and collided with blocks.
+//
+// The fall is measured from the highest point the entity reached in the air,
+// so jumping off a ledge counts the height of the jump too.
+func (e *Entity) updateFall(w *world.World) {
+	// Flying, climbing, and swimming stop a fall without hurting the entity
+	if e.Flying || e.climbing || e.inFluid(w) {
+		e.falling = false
+		return
+	}
+
+	bottom := e.AABB.MinY()
+	if e.onGround {
+		if e.falling {
+			if damage := fallDamage(e.fallPeak - bottom); damage > 0.0 {
+				e.TakeDamage(damage)
+			}
+		}
+		e.falling = false
+		return
+	}
+
+	// The fall started where the entity was before this tick's movement
+	if !e.falling {
+		e.falling = true
+		e.fallPeak = e.prevCenter.Y() - e.AABB.Size.Y()/2.0
+	}
+	e.fallPeak = math32.Max(e.fallPeak, bottom)
+}
+
+// InFluid returns true if the block at the entity's feet is a fluid (like
+// water).
+func (e *Entity) inFluid(w *world.World) bool {
+	x, y, z := world.ToWorldSpace(e.AABB.Center.X(), e.AABB.MinY(),
+		e.AABB.Center.Z())
+	block, ok := w.GetBlock(x, y, z)
+	return ok && w.GetBlockInfo(block).Fluid
+}
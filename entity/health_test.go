@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestFallDamage(t *testing.T) {
+	tests := []struct {
+		distance, want float32
+	}{
+		{0.0, 0.0},
+		{2.5, 0.0},
+		{safeFallDistance, 0.0},
+		{safeFallDistance + 0.5, 0.5},
+		{safeFallDistance + 1.0, 1.0},
+		{safeFallDistance + 7.0, 7.0},
+	}
+	for _, test := range tests {
+		if got := fallDamage(test.distance); got != test.want {
+			t.Errorf("got damage %v for a fall of %v blocks, want %v", got,
+				test.distance, test.want)
+		}
+	}
+}
+
+// DropDamage drops an entity from `height` blocks above the floor of a test
+// world at the given horizontal position, and returns the damage it takes
+// once it's landed.
+func dropDamage(x, z, height float32) float32 {
+	return dropDamageIn(newTestWorld(), x, z, height)
+}
+
+// DropDamageIn is like `dropDamage`, but drops the entity in the given world.
+func dropDamageIn(w *world.World, x, z, height float32) float32 {
+	e := newTestEntity(standingAt(x, z).Add(mgl32.Vec3{0.0, height, 0.0}))
+	tick(e, w, 200)
+	return MaxHealth - e.Health
+}
+
+func TestFallDamageFromHeights(t *testing.T) {
+	// Falls up to the safe distance do no damage
+	for _, height := range []float32{1.0, 2.0, safeFallDistance} {
+		if damage := dropDamage(0.5, 0.5, height); damage != 0.0 {
+			t.Errorf("took %v damage falling %v blocks, want none", damage,
+				height)
+		}
+	}
+
+	// Beyond that, the damage grows with the height
+	last := float32(0.0)
+	for _, height := range []float32{4.0, 6.0, 10.0} {
+		damage := dropDamage(0.5, 0.5, height)
+		if want := fallDamage(height); mgl32.Abs(damage-want) > 0.05 {
+			t.Errorf("took %v damage falling %v blocks, want %v", damage,
+				height, want)
+		}
+		if damage <= last {
+			t.Errorf("took %v damage falling %v blocks, no more than %v "+
+				"from a lower height", damage, height, last)
+		}
+		last = damage
+	}
+}
+
+func TestFallIntoWaterOrOntoLadder(t *testing.T) {
+	const height = 10.0
+
+	// Land on stone
+	w := newTestWorld()
+	setBlock(t, w, 0, testFloorY-1, 0, "Stone")
+	onStone := dropDamageIn(w, 0.5, 0.5, height)
+	if onStone == 0.0 {
+		t.Fatalf("took no damage falling %v blocks onto stone", height)
+	}
+
+	// Land in a pool of water on top of the same stone
+	w = newTestWorld()
+	setBlock(t, w, 0, testFloorY-1, 0, "Stone")
+	setBlock(t, w, 0, testFloorY, 0, "Water")
+	if damage := dropDamageIn(w, 0.5, 0.5, height); damage >= onStone {
+		t.Errorf("took %v damage falling into water, no less than the %v "+
+			"falling onto stone", damage, onStone)
+	}
+
+	// Grab on to a ladder on the way down
+	w = newTestWorld()
+	setBlock(t, w, 0, testFloorY-1, 0, "Stone")
+	buildLadder(t, w, 0, 0, 4)
+	if damage := dropDamageIn(w, 0.5, 0.5, height); damage >= onStone {
+		t.Errorf("took %v damage falling onto a ladder, no less than the %v "+
+			"falling onto stone", damage, onStone)
+	}
+}
+
+func TestTakeDamage(t *testing.T) {
+	e := newTestEntity(standingAt(0.5, 0.5))
+	e.TakeDamage(5.0)
+	if e.Health != MaxHealth-5.0 {
+		t.Errorf("got health %v after 5 damage, want %v", e.Health,
+			MaxHealth-5.0)
+	}
+
+	// Health doesn't drop below 0
+	e.TakeDamage(MaxHealth * 2.0)
+	if e.Health != 0.0 {
+		t.Errorf("got health %v after too much damage, want 0", e.Health)
+	}
+
+	// Invulnerable entities aren't hurt
+	e = newTestEntity(standingAt(0.5, 0.5))
+	e.Invulnerable = true
+	e.TakeDamage(5.0)
+	if e.Health != MaxHealth {
+		t.Errorf("invulnerable entity's health dropped to %v", e.Health)
+	}
+}
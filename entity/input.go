@@ -1,6 +1,10 @@
 package entity
 
 import (
+	"github.com/benanders/mineral/config"
+	"github.com/benanders/mineral/inventory"
+	"github.com/benanders/mineral/math"
+
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -17,6 +21,21 @@ type Controllable interface {
 	// normalized, and should be multiplied by the entity's look speed prior to
 	// applying the rotation.
 	Look(delta mgl32.Vec2)
+
+	// Jump makes the entity jump, if it's standing on the ground.
+	Jump()
+
+	// IsFlying returns true if the entity is flying, rather than walking.
+	IsFlying() bool
+
+	// SetFlying switches the entity between flying and walking.
+	SetFlying(flying bool)
+
+	// SetSprinting switches the entity between sprinting and walking.
+	SetSprinting(sprinting bool)
+
+	// SetSneaking switches the entity between sneaking and walking.
+	SetSneaking(sneaking bool)
 }
 
 // Controller is implemented by all entity controllers (e.g. the input
@@ -36,11 +55,115 @@ type Controller interface {
 type InputController struct {
 	IsKeyDown      [256]bool // Whether a key is pressed
 	mouseX, mouseY int32     // Accumulates mouse movement over a frame
+
+	// Double tapping the jump key toggles flying. `lastJumpPress` is the time
+	// (in milliseconds, from the SDL event) at which the jump key was last
+	// pressed, and `toggleFlying` is set when a double tap is detected, until
+	// the next update
+	lastJumpPress uint32
+	toggleFlying  bool
+
+	// The keys bound to each action
+	bindings Keybindings
+
+	// How mouse movement is turned into changes in look direction
+	settings LookSettings
+
+	// The change in look direction from the mouse movement in the current
+	// frame that's yet to be applied, and the number of update ticks left in
+	// the frame to apply it over (see `SpreadLook`)
+	frameLook mgl32.Vec2
+	ticksLeft int
+
+	// The change in look direction yet to be applied, when look smoothing is
+	// enabled
+	pendingLook mgl32.Vec2
+
+	// Set when the break or place mouse buttons are clicked, until the clicks
+	// are handled by the next update (see `BlockActions`)
+	breakBlock bool
+	placeBlock bool
+
+	// True while the break mouse button is held down (see `IsBreaking`)
+	breakHeld bool
+
+	// The number keys and mouse wheel change the selected slot in the hotbar,
+	// if there is one
+	Hotbar *inventory.Hotbar
+}
+
+// LookSettings controls how mouse movement changes the direction in which an
+// entity is looking.
+type LookSettings struct {
+	// MouseSensitivity scales mouse movement before it's applied, where 1 is
+	// the default speed.
+	MouseSensitivity float32
+
+	// InvertY makes moving the mouse up look down, and vice versa.
+	InvertY bool
+
+	// LookSmoothing eases changes in look direction over a few update ticks,
+	// rather than applying them all at once. It's the fraction of the
+	// outstanding change held back each tick, from 0 (no smoothing) up to
+	// `maxLookSmoothing`.
+	LookSmoothing float32
+}
+
+// DefaultLookSettings returns the look settings used until they're changed.
+func DefaultLookSettings() LookSettings {
+	return LookSettings{MouseSensitivity: 1.0, InvertY: false,
+		LookSmoothing: 0.0}
+}
+
+const (
+	// MaxLookSmoothing is the largest allowed look smoothing (see
+	// `config.MaxLookSmoothing`).
+	maxLookSmoothing = config.MaxLookSmoothing
+
+	// MaxLookLag is the largest change in look direction, before being
+	// multiplied by the entity's look speed, that smoothing can hold back. Any
+	// more is applied immediately, so fast mouse movements don't leave the
+	// look direction trailing behind for a long time.
+	maxLookLag = 50.0
+
+	// LookSettleThreshold is the size below which any remaining change in look
+	// direction is applied immediately, so the smoothing settles in a finite
+	// number of ticks.
+	lookSettleThreshold = 0.01
+)
+
+// DoubleTapTime is the maximum time between two presses of the jump key, in
+// milliseconds, for them to count as a double tap.
+const doubleTapTime = 300
+
+// NewInputController creates a new input controller instance, which uses the
+// given key bindings.
+func NewInputController(bindings Keybindings) *InputController {
+	return &InputController{bindings: bindings,
+		settings: DefaultLookSettings()}
+}
+
+// LookSettings returns the controller's current look settings.
+func (c *InputController) LookSettings() LookSettings {
+	return c.settings
+}
+
+// SetMouseSensitivity changes how much mouse movement affects the look
+// direction, where 1 is the default speed.
+func (c *InputController) SetMouseSensitivity(sensitivity float32) {
+	c.settings.MouseSensitivity = sensitivity
+}
+
+// SetInvertY switches whether moving the mouse up looks down.
+func (c *InputController) SetInvertY(invert bool) {
+	c.settings.InvertY = invert
 }
 
-// NewInputController creates a new input controller instance.
-func NewInputController() *InputController {
-	return &InputController{}
+// SetLookSmoothing changes how much changes in look direction are eased over
+// time, from 0 (no smoothing) to `maxLookSmoothing`. Values outside this range
+// are clamped.
+func (c *InputController) SetLookSmoothing(smoothing float32) {
+	c.settings.LookSmoothing = math.Clamp(smoothing, 0.0, maxLookSmoothing)
 }
 
 // HandleEvent implements the `Controller` interface.
@@ -51,41 +174,183 @@ func (c *InputController) HandleEvent(evt sdl.Event) {
 		if int(e.Keysym.Scancode) < len(c.IsKeyDown) {
 			c.IsKeyDown[e.Keysym.Scancode] = (e.State == sdl.PRESSED)
 		}
+
+		// Check for a double tap of the jump key, ignoring key repeats
+		if c.bindings.Jump.contains(e.Keysym.Scancode) &&
+			e.State == sdl.PRESSED && e.Repeat == 0 {
+			if c.lastJumpPress != 0 &&
+				e.Timestamp-c.lastJumpPress <= doubleTapTime {
+				c.toggleFlying = !c.toggleFlying
+				c.lastJumpPress = 0
+			} else {
+				c.lastJumpPress = e.Timestamp
+			}
+		}
+		// Select a hotbar slot with the number keys 1 to 9
+		if c.Hotbar != nil && e.State == sdl.PRESSED &&
+			e.Keysym.Scancode >= sdl.SCANCODE_1 &&
+			e.Keysym.Scancode <= sdl.SCANCODE_9 {
+			c.Hotbar.Select(int(e.Keysym.Scancode - sdl.SCANCODE_1))
+		}
 	case *sdl.MouseMotionEvent:
 		c.mouseX += e.XRel
 		c.mouseY += e.YRel
+	case *sdl.MouseButtonEvent:
+		// Break blocks with the left mouse button, and place them with the
+		// right
+		if e.Button == sdl.BUTTON_LEFT {
+			c.breakHeld = e.State == sdl.PRESSED
+		}
+		if e.State == sdl.PRESSED {
+			switch e.Button {
+			case sdl.BUTTON_LEFT:
+				c.breakBlock = true
+			case sdl.BUTTON_RIGHT:
+				c.placeBlock = true
+			}
+		}
+	case *sdl.MouseWheelEvent:
+		// Scrolling up moves the selection left along the hotbar
+		if c.Hotbar != nil {
+			c.Hotbar.Scroll(-int(e.Y))
+		}
+	}
+}
+
+// SpreadLook tells the controller how many update ticks are about to be run
+// in the current frame, so that the mouse movement accumulated since the last
+// frame is split evenly between them. Keyboard movement is applied in full
+// every tick, since keys are held down for the whole frame, but each bit of
+// mouse movement only happens once; without this the whole movement would be
+// applied in the first tick and none in the rest.
+//
+// Does nothing if no ticks are run in the frame, so the mouse movement carries
+// on accumulating until the next frame that does run a tick.
+func (c *InputController) SpreadLook(ticks int) {
+	if ticks <= 0 {
+		return
 	}
+	delta := getLookDelta(c.mouseX, c.mouseY, c.settings)
+	c.mouseX, c.mouseY = 0, 0
+	c.frameLook = c.frameLook.Add(delta)
+	c.ticksLeft = ticks
 }
 
 // Update implements the `Controller` interface.
 func (c *InputController) Update(entity Controllable) {
 	// Update the entity's look direction based on mouse input. We do this
 	// first so that the entity's local coordinate system is updated before
-	// applying movement
-	horizontalDelta := float32(c.mouseX)
-	verticalDelta := float32(c.mouseY)
-	entity.Look(mgl32.Vec2{horizontalDelta, verticalDelta})
-	c.mouseX, c.mouseY = 0.0, 0.0
+	// applying movement. If `SpreadLook` wasn't called for this frame, all the
+	// mouse movement so far is applied in this tick
+	if c.ticksLeft <= 0 {
+		c.SpreadLook(1)
+	}
+	delta := c.frameLook.Mul(1.0 / float32(c.ticksLeft))
+	c.frameLook = c.frameLook.Sub(delta)
+	c.ticksLeft--
+	var look mgl32.Vec2
+	look, c.pendingLook = smoothLook(c.pendingLook.Add(delta),
+		c.settings.LookSmoothing)
+	entity.Look(look)
+
+	// Toggle flying if the jump key was double tapped
+	if c.toggleFlying {
+		entity.SetFlying(!entity.IsFlying())
+		c.toggleFlying = false
+	}
 
 	// Update position based on keyboard input
 	x, y, z := float32(0.0), float32(0.0), float32(0.0)
-	if c.IsKeyDown[sdl.SCANCODE_W] {
+	if c.isActionDown(c.bindings.Forward) {
 		z += 1.0
 	}
-	if c.IsKeyDown[sdl.SCANCODE_S] {
+	if c.isActionDown(c.bindings.Back) {
 		z -= 1.0
 	}
-	if c.IsKeyDown[sdl.SCANCODE_A] {
+	if c.isActionDown(c.bindings.Left) {
 		x -= 1.0
 	}
-	if c.IsKeyDown[sdl.SCANCODE_D] {
+	if c.isActionDown(c.bindings.Right) {
 		x += 1.0
 	}
-	if c.IsKeyDown[sdl.SCANCODE_SPACE] {
-		y += 1.0
-	}
-	if c.IsKeyDown[sdl.SCANCODE_LSHIFT] || c.IsKeyDown[sdl.SCANCODE_RSHIFT] {
-		y -= 1.0
+
+	// Sprint while the sprint key is held down, but only when moving forwards
+	entity.SetSprinting(c.isActionDown(c.bindings.Sprint) && z > 0.0)
+
+	// While flying, the jump and sneak keys move straight up and down.
+	// Otherwise, jump while the jump key is held down, and sneak while the
+	// sneak key is held down
+	jump := c.isActionDown(c.bindings.Jump)
+	sneak := c.isActionDown(c.bindings.Sneak)
+	if entity.IsFlying() {
+		if jump {
+			y += 1.0
+		}
+		if sneak {
+			y -= 1.0
+		}
+		entity.SetSneaking(false)
+	} else {
+		if jump {
+			entity.Jump()
+		}
+		entity.SetSneaking(sneak)
 	}
 	entity.Move(mgl32.Vec3{x, y, z})
 }
+
+// BlockActions returns whether the player clicked to break or place a block
+// since the previous call. Clicks are only reported once.
+func (c *InputController) BlockActions() (breakBlock, placeBlock bool) {
+	breakBlock, placeBlock = c.breakBlock, c.placeBlock
+	c.breakBlock, c.placeBlock = false, false
+	return breakBlock, placeBlock
+}
+
+// IsBreaking returns true while the break mouse button is held down. Blocks
+// that take time to break are only broken while it's held.
+func (c *InputController) IsBreaking() bool {
+	return c.breakHeld
+}
+
+// GetLookDelta converts the mouse movement accumulated over a frame into a
+// change in look direction, applying the look settings.
+func getLookDelta(mouseX, mouseY int32, settings LookSettings) mgl32.Vec2 {
+	horizontalDelta := float32(mouseX) * settings.MouseSensitivity
+	verticalDelta := float32(mouseY) * settings.MouseSensitivity
+	if settings.InvertY {
+		verticalDelta = -verticalDelta
+	}
+	return mgl32.Vec2{horizontalDelta, verticalDelta}
+}
+
+// SmoothLook splits an outstanding change in look direction into the part to
+// apply this tick and the part to hold back until later ticks, given the look
+// smoothing. The applied part is always in the same direction as, and no
+// larger than, the outstanding change, so the look direction never overshoots
+// its target.
+func smoothLook(pending mgl32.Vec2, smoothing float32) (apply,
+	remaining mgl32.Vec2) {
+	smoothing = math.Clamp(smoothing, 0.0, maxLookSmoothing)
+	remaining = pending.Mul(smoothing)
+
+	// Cap how far the look direction can lag behind, and finish off any tiny
+	// remainder
+	if length := remaining.Len(); length > maxLookLag {
+		remaining = remaining.Mul(maxLookLag / length)
+	} else if length < lookSettleThreshold {
+		remaining = mgl32.Vec2{}
+	}
+	return pending.Sub(remaining), remaining
+}
+
+// IsActionDown returns true if any of the keys bound to an action are held
+// down.
+func (c *InputController) isActionDown(keys Keys) bool {
+	for _, key := range keys {
+		if int(key) < len(c.IsKeyDown) && c.IsKeyDown[key] {
+			return true
+		}
+	}
+	return false
+}
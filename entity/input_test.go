@@ -0,0 +1,191 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TestControllable records how a controller moves it, without any physics.
+type testControllable struct {
+	moved     mgl32.Vec3
+	looked    mgl32.Vec2
+	flying    bool
+	sneaking  bool
+	sprinting bool
+}
+
+func (c *testControllable) Move(delta mgl32.Vec3) {
+	c.moved = c.moved.Add(delta)
+}
+
+func (c *testControllable) Look(delta mgl32.Vec2) {
+	c.looked = c.looked.Add(delta)
+}
+
+func (c *testControllable) Jump() {}
+
+func (c *testControllable) IsFlying() bool {
+	return c.flying
+}
+
+func (c *testControllable) SetFlying(flying bool) {
+	c.flying = flying
+}
+
+func (c *testControllable) SetSprinting(sprinting bool) {
+	c.sprinting = sprinting
+}
+
+func (c *testControllable) SetSneaking(sneaking bool) {
+	c.sneaking = sneaking
+}
+
+// PressKey sends a controller the event for a key being pressed or released.
+func pressKey(c *InputController, key sdl.Scancode, pressed bool) {
+	evt := sdl.KeyboardEvent{Type: sdl.KEYUP, State: sdl.RELEASED,
+		Keysym: sdl.Keysym{Scancode: key}}
+	if pressed {
+		evt.Type, evt.State = sdl.KEYDOWN, sdl.PRESSED
+	}
+	c.HandleEvent(&evt)
+}
+
+func TestRemappedKeys(t *testing.T) {
+	bindings, err := ParseKeybindings(`forward = ["Up", "W"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewInputController(bindings)
+
+	// Either key bound to an action triggers it
+	for _, key := range []sdl.Scancode{sdl.SCANCODE_UP, sdl.SCANCODE_W} {
+		var entity testControllable
+		pressKey(c, key, true)
+		c.Update(&entity)
+		pressKey(c, key, false)
+		if entity.moved.Z() != 1.0 {
+			t.Errorf("key %v moved entity by %v, want forwards", key,
+				entity.moved)
+		}
+	}
+
+	// Actions that weren't remapped keep their default keys
+	var entity testControllable
+	pressKey(c, sdl.SCANCODE_RSHIFT, true)
+	c.Update(&entity)
+	if !entity.sneaking {
+		t.Error("right shift didn't sneak")
+	}
+}
+
+func TestGetLookDelta(t *testing.T) {
+	tests := []struct {
+		sensitivity float32
+		invertY     bool
+		want        mgl32.Vec2
+	}{
+		{1.0, false, mgl32.Vec2{10.0, -4.0}},
+		{2.5, false, mgl32.Vec2{25.0, -10.0}},
+		{1.0, true, mgl32.Vec2{10.0, 4.0}},
+		{0.5, true, mgl32.Vec2{5.0, 2.0}},
+	}
+	for _, test := range tests {
+		settings := LookSettings{MouseSensitivity: test.sensitivity,
+			InvertY: test.invertY}
+		if got := getLookDelta(10, -4, settings); got != test.want {
+			t.Errorf("got look delta %v with sensitivity %v and invert %v, "+
+				"want %v", got, test.sensitivity, test.invertY, test.want)
+		}
+	}
+}
+
+func TestSmoothLookWithoutSmoothing(t *testing.T) {
+	pending := mgl32.Vec2{12.0, -3.0}
+	apply, remaining := smoothLook(pending, 0.0)
+	if apply != pending || remaining != (mgl32.Vec2{}) {
+		t.Errorf("applied %v and held back %v, want all applied", apply,
+			remaining)
+	}
+}
+
+func TestSmoothLookSettles(t *testing.T) {
+	// The whole change is applied over a finite number of ticks, never
+	// overshooting it
+	target := mgl32.Vec2{20.0, -8.0}
+	var applied, pending mgl32.Vec2
+	pending = target
+	ticks := 0
+	for ; pending != (mgl32.Vec2{}) && ticks < 1000; ticks++ {
+		var apply mgl32.Vec2
+		apply, pending = smoothLook(pending, 0.5)
+		if apply.X() < 0.0 || apply.Y() > 0.0 {
+			t.Fatalf("applied %v, in the wrong direction", apply)
+		}
+		applied = applied.Add(apply)
+	}
+	if ticks < 2 || ticks == 1000 {
+		t.Errorf("smoothing settled after %d ticks", ticks)
+	}
+	if !applied.ApproxEqual(target) {
+		t.Errorf("applied %v in total, want %v", applied, target)
+	}
+}
+
+func TestSmoothLookLimitsLag(t *testing.T) {
+	// Huge mouse movements aren't held back by more than the maximum lag,
+	// even with more than the maximum smoothing
+	apply, remaining := smoothLook(mgl32.Vec2{1000.0, 0.0}, 2.0)
+	if remaining.Len() > maxLookLag+0.001 {
+		t.Errorf("held back %v, more than the maximum lag", remaining)
+	}
+	if got := apply.Add(remaining); !got.ApproxEqual(mgl32.Vec2{1000.0, 0.0}) {
+		t.Errorf("split the change into %v and %v", apply, remaining)
+	}
+}
+
+// MoveMouse sends a controller the event for the mouse moving by the given
+// amount.
+func moveMouse(c *InputController, dx, dy int32) {
+	c.HandleEvent(&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, XRel: dx,
+		YRel: dy})
+}
+
+func TestSpreadLookSplitsMovementBetweenTicks(t *testing.T) {
+	c := NewInputController(DefaultKeybindings())
+	moveMouse(c, 6, -2)
+	moveMouse(c, 4, -2)
+	want := getLookDelta(10, -4, c.LookSettings())
+
+	// Each of the two ticks in the frame applies half the movement
+	c.SpreadLook(2)
+	var total mgl32.Vec2
+	for i := 0; i < 2; i++ {
+		var entity testControllable
+		c.Update(&entity)
+		if !entity.looked.ApproxEqual(want.Mul(0.5)) {
+			t.Errorf("tick %d looked %v, want %v", i, entity.looked,
+				want.Mul(0.5))
+		}
+		total = total.Add(entity.looked)
+	}
+	if !total.ApproxEqual(want) {
+		t.Errorf("looked %v in total, want %v", total, want)
+	}
+}
+
+func TestSpreadLookWithoutTicks(t *testing.T) {
+	c := NewInputController(DefaultKeybindings())
+	moveMouse(c, 10, -4)
+	want := getLookDelta(10, -4, c.LookSettings())
+
+	// The movement carries on to the next frame that runs a tick
+	c.SpreadLook(0)
+	c.SpreadLook(1)
+	var entity testControllable
+	c.Update(&entity)
+	if !entity.looked.ApproxEqual(want) {
+		t.Errorf("looked %v, want %v", entity.looked, want)
+	}
+}
@@ -0,0 +1,195 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/benanders/mineral/asset"
+	"github.com/benanders/mineral/config"
+
+	"github.com/BurntSushi/toml"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	// KeybindingsFile is the name of the key bindings file in the user's
+	// config directory (see `config.Dir`).
+	keybindingsFile = "keybindings.toml"
+
+	// KeybindingsAsset is the path of the asset file that's copied into the
+	// user's config directory if they don't have a key bindings file yet. It
+	// lists the default key bindings, and explains how to change them.
+	keybindingsAsset = "keybindings.toml"
+)
+
+// Keys is the set of keys bound to a single action. Pressing any one of them
+// triggers the action.
+type Keys []sdl.Scancode
+
+// Contains returns true if the given key is one of the keys.
+func (k Keys) contains(key sdl.Scancode) bool {
+	for _, bound := range k {
+		if bound == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Keybindings maps each action the player can perform to the keys that
+// trigger it.
+type Keybindings struct {
+	Forward Keys
+	Back    Keys
+	Left    Keys
+	Right   Keys
+	Jump    Keys
+	Sneak   Keys
+	Sprint  Keys
+}
+
+// DefaultKeybindings returns the key bindings used for any action that isn't
+// bound to a key in the key bindings file.
+func DefaultKeybindings() Keybindings {
+	return Keybindings{
+		Forward: Keys{sdl.SCANCODE_W},
+		Back:    Keys{sdl.SCANCODE_S},
+		Left:    Keys{sdl.SCANCODE_A},
+		Right:   Keys{sdl.SCANCODE_D},
+		Jump:    Keys{sdl.SCANCODE_SPACE},
+		Sneak:   Keys{sdl.SCANCODE_LSHIFT, sdl.SCANCODE_RSHIFT},
+		Sprint:  Keys{sdl.SCANCODE_LCTRL},
+	}
+}
+
+// Actions maps the name of each action, as used in the key bindings file, to
+// the keys it's bound to.
+func (k *Keybindings) actions() map[string]*Keys {
+	return map[string]*Keys{
+		"forward": &k.Forward,
+		"back":    &k.Back,
+		"left":    &k.Left,
+		"right":   &k.Right,
+		"jump":    &k.Jump,
+		"sneak":   &k.Sneak,
+		"sprint":  &k.Sprint,
+	}
+}
+
+// LoadKeybindings reads the key bindings from the key bindings file in the
+// user's config directory. If there's no key bindings file, one is created
+// listing the default key bindings, so the player can change them.
+//
+// The default key bindings are returned along with any error, so the game can
+// still be played if the key bindings can't be loaded.
+func LoadKeybindings() (Keybindings, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return DefaultKeybindings(), err
+	}
+	return LoadKeybindingsFile(filepath.Join(dir, keybindingsFile))
+}
+
+// LoadKeybindingsFile reads the key bindings from the given TOML file. If the
+// file doesn't exist, it's created holding the default key bindings.
+func LoadKeybindingsFile(path string) (Keybindings, error) {
+	source, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return DefaultKeybindings(), createKeybindingsFile(path)
+	} else if err != nil {
+		return DefaultKeybindings(), fmt.Errorf(
+			"failed to read key bindings `%v`: %v", path, err)
+	}
+
+	bindings, err := ParseKeybindings(string(source))
+	if err != nil {
+		return DefaultKeybindings(), fmt.Errorf(
+			"failed to load key bindings `%v`: %v", path, err)
+	}
+	return bindings, nil
+}
+
+// CreateKeybindingsFile copies the default key bindings file from the assets
+// to the given path, creating the folder it's in if necessary.
+func createKeybindingsFile(path string) error {
+	source, err := asset.Asset(keybindingsAsset)
+	if err != nil {
+		return fmt.Errorf("failed to load `asset/data/%v`: %v",
+			keybindingsAsset, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create key bindings folder: %v", err)
+	}
+	if err := os.WriteFile(path, source, 0644); err != nil {
+		return fmt.Errorf("failed to write key bindings `%v`: %v", path, err)
+	}
+	return nil
+}
+
+// ParseKeybindings decodes key bindings from TOML source, which maps action
+// names to an SDL key name (e.g. `forward = "W"`), or a list of key names for
+// actions bound to several keys (e.g. `sneak = ["Left Shift", "Right
+// Shift"]`). Actions not listed keep their default key bindings.
+func ParseKeybindings(source string) (Keybindings, error) {
+	bindings := DefaultKeybindings()
+	var keys map[string]interface{}
+	if _, err := toml.Decode(source, &keys); err != nil {
+		return bindings, err
+	}
+
+	// Bind the keys in a consistent order, so the same error is reported each
+	// time for a file with multiple problems
+	actions := bindings.actions()
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		binding, ok := actions[name]
+		if !ok {
+			return bindings, fmt.Errorf("unknown action `%v`", name)
+		}
+		bound, err := parseKeys(keys[name])
+		if err != nil {
+			return bindings, fmt.Errorf("%v for action `%v`", err, name)
+		}
+		*binding = bound
+	}
+	return bindings, nil
+}
+
+// ParseKeys converts a key name, or a list of key names, decoded from a key
+// bindings file into the keys they name.
+func parseKeys(value interface{}) (Keys, error) {
+	var names []interface{}
+	switch v := value.(type) {
+	case string:
+		names = []interface{}{v}
+	case []interface{}:
+		names = v
+	default:
+		return nil, fmt.Errorf("expected a key name or a list of key names")
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no keys given")
+	}
+
+	keys := make(Keys, 0, len(names))
+	for _, value := range names {
+		name, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a key name")
+		}
+		scancode := sdl.GetScancodeFromName(name)
+		if scancode == sdl.SCANCODE_UNKNOWN {
+			return nil, fmt.Errorf("unknown key `%v`", name)
+		}
+		keys = append(keys, scancode)
+	}
+	return keys, nil
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// EqualKeys returns true if two sets of keys hold the same keys in the same
+// order.
+func equalKeys(a, b Keys) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseKeybindings(t *testing.T) {
+	bindings, err := ParseKeybindings(`
+forward = "Up"
+sneak = ["Left Shift", "Right Ctrl"]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Keys{sdl.SCANCODE_UP}); !equalKeys(bindings.Forward, want) {
+		t.Errorf("got forward keys %v, want %v", bindings.Forward, want)
+	}
+	want := Keys{sdl.SCANCODE_LSHIFT, sdl.SCANCODE_RCTRL}
+	if !equalKeys(bindings.Sneak, want) {
+		t.Errorf("got sneak keys %v, want %v", bindings.Sneak, want)
+	}
+
+	// Actions that aren't listed keep their default keys
+	defaults := DefaultKeybindings()
+	if !equalKeys(bindings.Back, defaults.Back) {
+		t.Errorf("got back keys %v, want %v", bindings.Back, defaults.Back)
+	}
+}
+
+func TestParseKeybindingsErrors(t *testing.T) {
+	sources := []string{
+		`fly = "F"`,
+		`forward = "Not A Key"`,
+		`forward = ["W", "Not A Key"]`,
+		`forward = []`,
+		`forward = 1`,
+		`forward = "W`,
+	}
+	for _, source := range sources {
+		if _, err := ParseKeybindings(source); err == nil {
+			t.Errorf("parsed invalid key bindings %q", source)
+		}
+	}
+}
+
+func TestDefaultSneakKeys(t *testing.T) {
+	// Either shift key sneaks
+	sneak := DefaultKeybindings().Sneak
+	if !sneak.contains(sdl.SCANCODE_LSHIFT) ||
+		!sneak.contains(sdl.SCANCODE_RSHIFT) {
+		t.Errorf("got sneak keys %v, want both shift keys", sneak)
+	}
+}
+
+func TestLoadKeybindingsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mineral", keybindingsFile)
+
+	// A missing file is created holding the default key bindings
+	bindings, err := LoadKeybindingsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalKeys(bindings.Sneak, DefaultKeybindings().Sneak) {
+		t.Errorf("got sneak keys %v, want the defaults", bindings.Sneak)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("key bindings file wasn't created: %v", err)
+	}
+	bindings, err = LoadKeybindingsFile(path)
+	if err != nil {
+		t.Fatalf("failed to load the created key bindings file: %v", err)
+	}
+	if !equalKeys(bindings.Sneak, DefaultKeybindings().Sneak) {
+		t.Errorf("got sneak keys %v, want the defaults", bindings.Sneak)
+	}
+
+	// The player's changes are loaded from the file
+	if err := os.WriteFile(path, []byte(`jump = "Return"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bindings, err = LoadKeybindingsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Keys{sdl.SCANCODE_RETURN}); !equalKeys(bindings.Jump, want) {
+		t.Errorf("got jump keys %v, want %v", bindings.Jump, want)
+	}
+
+	// The defaults are used if the file is invalid
+	if err := os.WriteFile(path, []byte(`jump = "?"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bindings, err = LoadKeybindingsFile(path)
+	if err == nil {
+		t.Error("loaded invalid key bindings")
+	}
+	if !equalKeys(bindings.Jump, DefaultKeybindings().Jump) {
+		t.Errorf("got jump keys %v, want the defaults", bindings.Jump)
+	}
+}
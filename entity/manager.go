@@ -0,0 +1,206 @@
+package entity
+
+import (
+	"github.com/benanders/mineral/world"
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// DefaultDespawnRadius is the default horizontal distance from the player, in
+// blocks, beyond which entities are removed from the world. It's a little
+// further than the default render radius, so entities aren't removed while
+// the player can still see them.
+const DefaultDespawnRadius = 160.0
+
+// Manager keeps track of every entity in the world other than the player,
+// along with the controller that moves each one. Entities that wander too far
+// from the player are despawned.
+type Manager struct {
+	// Entities further than this from the player horizontally, in blocks, are
+	// removed on the next update
+	DespawnRadius float32
+
+	// The entities, and the controller for the entity at the same index in
+	// `entities` (or nil for entities that only move under gravity)
+	entities    []*Entity
+	controllers []Controller
+
+	// Blocks dropped in the world, waiting to be picked up by the player.
+	// These aren't pushed around by other entities
+	drops []*Drop
+
+	physics *PhysicsBatcher // Spreads entity physics across ticks
+}
+
+// NewManager creates an entity manager with no entities, which uses the given
+// physics batcher to move the player and entities every update tick.
+func NewManager(despawnRadius float32, physics *PhysicsBatcher) *Manager {
+	return &Manager{DespawnRadius: despawnRadius, physics: physics}
+}
+
+// Spawn adds an entity to the world, controlled by `c`. The controller can be
+// nil for entities that don't move by themselves.
+func (m *Manager) Spawn(e *Entity, c Controller) {
+	m.entities = append(m.entities, e)
+	m.controllers = append(m.controllers, c)
+}
+
+// Entities returns every entity in the world other than the player. The slice
+// must not be modified.
+func (m *Manager) Entities() []*Entity {
+	return m.entities
+}
+
+// SpawnDrop adds a dropped block to the world.
+func (m *Manager) SpawnDrop(d *Drop) {
+	m.drops = append(m.drops, d)
+}
+
+// Drops returns every dropped block in the world. The slice must not be
+// modified.
+func (m *Manager) Drops() []*Drop {
+	return m.drops
+}
+
+// Update lets each entity's controller decide how it moves, then applies the
+// movement and resolves collisions for the player and all the other entities,
+// first with blocks and then with each other. Drops near the player are
+// pulled towards them. Afterwards, entities and drops too far from the player
+// are despawned.
+//
+// The player's own controller isn't run here, since it also handles the
+// player's interactions with the world (like breaking blocks).
+func (m *Manager) Update(w *world.World, player *Player) {
+	for i, c := range m.controllers {
+		if c != nil {
+			c.Update(m.entities[i])
+		}
+	}
+	m.physics.Update(w, player, m.entities)
+	m.separateEntities(w, player)
+	m.updateDrops(w, player)
+	m.despawn(player)
+}
+
+// UpdateDrops pulls drops that can be picked up towards the player, and moves
+// every drop under gravity. Drops are cheap, so they're all updated every
+// tick rather than being batched with the other entities.
+func (m *Manager) updateDrops(w *world.World, player *Player) {
+	for _, d := range m.drops {
+		d.age++
+		if d.canBePickedUp() {
+			d.pullTowards(player)
+		}
+		d.ApplyMovementAndResolveCollisions(w)
+	}
+}
+
+// CollectDrops picks up every drop that's touching the player, adding its
+// block to `c` and removing the drop from the world. Drops that don't fit in
+// `c` are left where they are.
+func (m *Manager) CollectDrops(player *Player, c Collector) {
+	kept := 0
+	for _, d := range m.drops {
+		if !(d.canBePickedUp() && d.AABB.Intersects(player.AABB) &&
+			c.Add(d.Block)) {
+			m.drops[kept] = d
+			kept++
+		}
+	}
+	m.truncateDrops(kept)
+}
+
+// SeparateEntities pushes apart every pair of overlapping entities (including
+// the player). This happens after the entities have moved and collided with
+// blocks, and the pushes themselves can't move an entity into a block.
+//
+// Every pair of entities is checked, which is fine for the handful of
+// entities around at the moment, but will need some sort of spatial
+// partitioning (like bucketing entities by chunk) if there are ever many more.
+func (m *Manager) separateEntities(w *world.World, player *Player) {
+	for i, a := range m.entities {
+		if a.AABB.Intersects(player.AABB) {
+			separate(w, &player.Entity, a)
+		}
+		for _, b := range m.entities[i+1:] {
+			if a.AABB.Intersects(b.AABB) {
+				separate(w, a, b)
+			}
+		}
+	}
+}
+
+// Separate pushes two overlapping entities apart along the axis on which they
+// overlap the least, each moving half the distance. An entity that's blocked
+// by a wall doesn't move the whole way, so the two may still overlap a little
+// afterwards; they'll continue to be pushed apart over the following ticks.
+func separate(w *world.World, a, b *Entity) {
+	overlap := mgl32.Vec3{
+		a.AABB.IntersectionX(b.AABB),
+		a.AABB.IntersectionY(b.AABB),
+		a.AABB.IntersectionZ(b.AABB),
+	}
+
+	// Only push along the axis of least penetration, which is the shortest
+	// way out
+	axis := 0
+	for i := 1; i < 3; i++ {
+		if math32.Abs(overlap[i]) < math32.Abs(overlap[axis]) {
+			axis = i
+		}
+	}
+	var push mgl32.Vec3
+	push[axis] = overlap[axis] / 2.0
+
+	// The overlap is measured from `a` into `b`, so `a` moves back along it
+	moveAndSlide(w, &a.AABB, push.Mul(-1.0))
+	moveAndSlide(w, &b.AABB, push)
+}
+
+// Despawn removes all entities and drops further than the despawn radius from
+// the player horizontally, keeping the rest in the same order.
+func (m *Manager) despawn(player *Player) {
+	kept := 0
+	for i, e := range m.entities {
+		if m.withinDespawnRadius(e, player) {
+			m.entities[kept] = e
+			m.controllers[kept] = m.controllers[i]
+			kept++
+		}
+	}
+
+	// Clear the leftover references so despawned entities can be freed
+	for i := kept; i < len(m.entities); i++ {
+		m.entities[i] = nil
+		m.controllers[i] = nil
+	}
+	m.entities = m.entities[:kept]
+	m.controllers = m.controllers[:kept]
+
+	kept = 0
+	for _, d := range m.drops {
+		if m.withinDespawnRadius(&d.Entity, player) {
+			m.drops[kept] = d
+			kept++
+		}
+	}
+	m.truncateDrops(kept)
+}
+
+// WithinDespawnRadius returns true if an entity is no further than the
+// despawn radius from the player horizontally.
+func (m *Manager) withinDespawnRadius(e *Entity, player *Player) bool {
+	offset := e.AABB.Center.Sub(player.AABB.Center)
+	distSq := offset.X()*offset.X() + offset.Z()*offset.Z()
+	return distSq <= m.DespawnRadius*m.DespawnRadius
+}
+
+// TruncateDrops shrinks the list of drops to its first `kept` drops, clearing
+// the leftover references so removed drops can be freed.
+func (m *Manager) truncateDrops(kept int) {
+	for i := kept; i < len(m.drops); i++ {
+		m.drops[i] = nil
+	}
+	m.drops = m.drops[:kept]
+}
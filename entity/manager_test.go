@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestManagerDespawnsDistantEntities(t *testing.T) {
+	w := newTestWorld()
+	player := NewPlayer(standingAt(0.5, 0.5), mgl32.Vec2{})
+	m := NewManager(10.0, NewPhysicsBatcher(8, 8))
+
+	near := newTestEntity(standingAt(3.5, 0.5))
+	nearCtrl := NewWanderController(1)
+	m.Spawn(newTestEntity(standingAt(20.5, 0.5)), NewWanderController(2))
+	m.Spawn(near, nearCtrl)
+	m.Spawn(newTestEntity(standingAt(0.5, -20.5)), nil)
+	m.Update(w, player)
+
+	entities := m.Entities()
+	if len(entities) != 1 || entities[0] != near {
+		t.Fatalf("got %d entities after despawning, want only the near one",
+			len(entities))
+	}
+	if m.controllers[0] != nearCtrl {
+		t.Error("near entity lost its controller")
+	}
+}
+
+func TestManagerSeparatesOverlappingEntities(t *testing.T) {
+	w := newTestWorld()
+	player := NewPlayer(standingAt(0.5, 0.5), mgl32.Vec2{})
+	m := NewManager(DefaultDespawnRadius, NewPhysicsBatcher(8, 8))
+
+	// The two entities overlap by a little along the x axis
+	a := newTestEntity(standingAt(5.5, 5.5))
+	b := newTestEntity(standingAt(5.8, 5.5))
+	m.Spawn(a, nil)
+	m.Spawn(b, nil)
+	for i := 0; i < 5; i++ {
+		m.Update(w, player)
+	}
+
+	if a.AABB.Intersects(b.AABB) {
+		t.Errorf("entities at %v and %v still overlap", a.AABB.Center,
+			b.AABB.Center)
+	}
+	if a.AABB.Center.X() >= b.AABB.Center.X() {
+		t.Error("entities pushed past each other")
+	}
+	if a.AABB.Center.Z() != 5.5 || b.AABB.Center.Z() != 5.5 {
+		t.Error("entities pushed along the wrong axis")
+	}
+}
+
+// TestCollector collects blocks until it has room for no more.
+type testCollector struct {
+	room   int
+	blocks []world.Block
+}
+
+func (c *testCollector) Add(block world.Block) bool {
+	if len(c.blocks) >= c.room {
+		return false
+	}
+	c.blocks = append(c.blocks, block)
+	return true
+}
+
+// NewTestDrop creates a drop of the given block at `center`, which is `age`
+// update ticks old.
+func newTestDrop(block world.Block, center mgl32.Vec3, age int) *Drop {
+	d := NewDrop(block, center)
+	d.age = age
+	return d
+}
+
+func TestCollectDrops(t *testing.T) {
+	player := NewPlayer(standingAt(0.5, 0.5), mgl32.Vec2{})
+	m := NewManager(DefaultDespawnRadius, NewPhysicsBatcher(8, 8))
+	center := player.AABB.Center
+	young := newTestDrop(1, center, dropPickupDelay-1)
+	far := newTestDrop(2, standingAt(5.5, 5.5), dropPickupDelay)
+	m.SpawnDrop(young)
+	m.SpawnDrop(far)
+	m.SpawnDrop(newTestDrop(3, center, dropPickupDelay))
+
+	// Only the old enough drop touching the player is picked up
+	c := testCollector{room: 10}
+	m.CollectDrops(player, &c)
+	if len(c.blocks) != 1 || c.blocks[0] != 3 {
+		t.Errorf("collected %v, want only block 3", c.blocks)
+	}
+	drops := m.Drops()
+	if len(drops) != 2 || drops[0] != young || drops[1] != far {
+		t.Errorf("got %d drops left, want the young and far ones",
+			len(drops))
+	}
+}
+
+func TestCollectDropsWithoutRoom(t *testing.T) {
+	player := NewPlayer(standingAt(0.5, 0.5), mgl32.Vec2{})
+	m := NewManager(DefaultDespawnRadius, NewPhysicsBatcher(8, 8))
+	for i := 0; i < 3; i++ {
+		m.SpawnDrop(newTestDrop(1, player.AABB.Center, dropPickupDelay))
+	}
+
+	// Drops that don't fit are left where they are
+	c := testCollector{room: 2}
+	m.CollectDrops(player, &c)
+	if len(c.blocks) != 2 || len(m.Drops()) != 1 {
+		t.Errorf("collected %d drops and left %d, want 2 and 1",
+			len(c.blocks), len(m.Drops()))
+	}
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"github.com/benanders/mineral/world"
+)
+
+const (
+	// DefaultBatchThreshold is the default number of entities (excluding the
+	// player) above which entity physics is spread over multiple ticks.
+	DefaultBatchThreshold = 64
+
+	// DefaultBatchSize is the default number of entities (excluding the
+	// player) whose physics is processed each tick when batching.
+	DefaultBatchSize = 16
+)
+
+// PhysicsBatcher applies movement and resolves collisions for every entity in
+// the world. Once the number of entities exceeds a threshold, entities are
+// processed in round-robin batches across update ticks to bound the cost of
+// each tick. The player is always updated every tick, so their movement stays
+// responsive.
+//
+// An entity's movement accumulates over the ticks in which it isn't updated,
+// and gravity and velocity are applied once for each of those ticks when it
+// next is. So batched entities move the same distance overall, just in larger
+// steps. Collisions are only resolved once per step though, so a batched
+// entity's path around obstacles may differ a little.
+type PhysicsBatcher struct {
+	Threshold int // Entity count above which entities are batched
+	BatchSize int // Number of entities processed per tick when batching
+
+	next int // Index of the next entity to process when batching
+}
+
+// NewPhysicsBatcher creates a new batcher with the given threshold and batch
+// size.
+func NewPhysicsBatcher(threshold, batchSize int) *PhysicsBatcher {
+	return &PhysicsBatcher{Threshold: threshold, BatchSize: batchSize}
+}
+
+// Update applies movement and resolves collisions for the player, and for
+// either all the other entities or the next batch of them.
+func (b *PhysicsBatcher) Update(w *world.World, player *Player,
+	entities []*Entity) {
+	// The player is always updated
+	player.ApplyMovementAndResolveCollisions(w)
+
+	// Update every entity if there aren't too many of them
+	if len(entities) <= b.Threshold || b.BatchSize <= 0 {
+		for _, e := range entities {
+			e.ApplyMovementAndResolveCollisions(w)
+		}
+		return
+	}
+
+	// Otherwise only update the next batch, wrapping around to the start of
+	// the list. The list may have shrunk since the previous tick
+	if b.next >= len(entities) {
+		b.next = 0
+	}
+	count := b.BatchSize
+	if count > len(entities) {
+		count = len(entities)
+	}
+	start := b.next
+	for i := 0; i < count; i++ {
+		entities[b.next].ApplyMovementAndResolveCollisions(w)
+		b.next = (b.next + 1) % len(entities)
+	}
+
+	// Every other entity skips this tick
+	for i := count; i < len(entities); i++ {
+		entities[(start+i)%len(entities)].skipTick()
+	}
+}
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Updated returns true if an entity floating in an empty world has had its
+// physics updated, since gravity gives it some vertical velocity.
+func updated(e *Entity) bool {
+	return e.velocity.Y() < 0.0
+}
+
+func TestPhysicsBatcherUpdatesEveryEntityWithinBatches(t *testing.T) {
+	const numEntities, threshold, batchSize = 10, 4, 3
+	w := world.NewHeadless(testHeight, 1)
+	player := NewPlayer(mgl32.Vec3{0.0, 20.0, 0.0}, mgl32.Vec2{})
+	entities := make([]*Entity, numEntities)
+	for i := range entities {
+		entities[i] = newTestEntity(mgl32.Vec3{float32(i) * 2.0, 20.0, 0.0})
+	}
+
+	// Every entity is updated within ceil(N / B) ticks, and the player every
+	// tick
+	batcher := NewPhysicsBatcher(threshold, batchSize)
+	ticks := (numEntities + batchSize - 1) / batchSize
+	for i := 1; i <= ticks; i++ {
+		batcher.Update(w, player, entities)
+		want := -float32(i) * Gravity
+		if got := player.velocity.Y(); mgl32.Abs(got-want) > 1e-6 {
+			t.Fatalf("player velocity %v after %d ticks, want %v", got, i,
+				want)
+		}
+	}
+	for i, e := range entities {
+		if !updated(e) {
+			t.Errorf("entity %d not updated within %d ticks", i, ticks)
+		}
+	}
+}
+
+func TestPhysicsBatcherUpdatesAllBelowThreshold(t *testing.T) {
+	w := world.NewHeadless(testHeight, 1)
+	player := NewPlayer(mgl32.Vec3{0.0, 20.0, 0.0}, mgl32.Vec2{})
+	entities := []*Entity{
+		newTestEntity(mgl32.Vec3{2.0, 20.0, 0.0}),
+		newTestEntity(mgl32.Vec3{4.0, 20.0, 0.0}),
+	}
+	NewPhysicsBatcher(2, 1).Update(w, player, entities)
+	for i, e := range entities {
+		if !updated(e) {
+			t.Errorf("entity %d not updated", i)
+		}
+	}
+}
+
+func TestPhysicsBatcherFallsTheSameDistance(t *testing.T) {
+	const numEntities, batchSize, ticks = 3, 1, 9
+	w := world.NewHeadless(testHeight, 1)
+	player := NewPlayer(mgl32.Vec3{0.0, 20.0, 0.0}, mgl32.Vec2{})
+	entities := make([]*Entity, numEntities)
+	for i := range entities {
+		entities[i] = newTestEntity(mgl32.Vec3{float32(i) * 2.0, 20.0, 0.0})
+	}
+
+	// Record how far an entity that's updated every tick falls
+	unbatched := newTestEntity(mgl32.Vec3{-2.0, 20.0, 0.0})
+	heights := make([]float32, ticks)
+	batcher := NewPhysicsBatcher(0, batchSize)
+	for i := range heights {
+		unbatched.ApplyMovementAndResolveCollisions(w)
+		heights[i] = unbatched.AABB.Center.Y()
+		batcher.Update(w, player, entities)
+
+		// Entities skipping this tick stay still when rendered
+		for j, e := range entities {
+			skipped := j != i%numEntities
+			if skipped && e.InterpolatedCenter(0.0) != e.AABB.Center {
+				t.Fatalf("entity %d skipping tick %d would move", j, i)
+			}
+		}
+	}
+
+	// Each batched entity was last updated on one of the final ticks, when it
+	// should have caught up with the unbatched entity
+	for i, e := range entities {
+		want := heights[ticks-numEntities+i]
+		if got := e.AABB.Center.Y(); mgl32.Abs(got-want) > 1e-4 {
+			t.Errorf("batched entity %d at height %v, want %v", i, got, want)
+		}
+	}
+}
@@ -12,21 +12,30 @@ const (
 	// PlayerLookSpeed is the default speed at which the player can look
 	// around.
 	playerLookSpeed = 0.003
+
+	// PlayerWidth and PlayerHeight are the size of the player's AABB, in
+	// blocks.
+	PlayerWidth  = 0.6
+	PlayerHeight = 1.8
 )
 
 // Player is an entity controlled by the user, which the camera follows as they
 // move.
 type Player struct {
 	Entity
+
+	// Controls whether the player can fly, take damage, and break blocks
+	// instantly. Players start in survival
+	mode GameMode
 }
 
 // NewPlayer creates a new instance of the player with an initial position and
 // rotation.
 func NewPlayer(center mgl32.Vec3, rotation mgl32.Vec2) *Player {
-	// Default player size is 0.6 x 1.8 x 0.6 blocks
-	aabb := math.AABB{Center: center, Size: mgl32.Vec3{0.6, 1.8, 0.6}}
+	size := mgl32.Vec3{PlayerWidth, PlayerHeight, PlayerWidth}
+	aabb := math.AABB{Center: center, Size: size}
 	entity := NewEntity(aabb, rotation, playerMoveSpeed, playerLookSpeed)
-	p := Player{*entity}
+	p := Player{Entity: *entity}
 	p.updateAxes()
 	return &p
 }
@@ -38,9 +47,21 @@ func (p *Player) Sight() mgl32.Vec3 {
 
 // EyePosition implements the camera.ViewPoint interface for the player.
 func (p *Player) EyePosition() mgl32.Vec3 {
+	return p.eyeAt(p.AABB.Center)
+}
+
+// InterpolatedEyePosition returns the position of the player's eye partway
+// between its position before and after the most recent update tick (see
+// `Entity.InterpolatedCenter`).
+func (p *Player) InterpolatedEyePosition(alpha float32) mgl32.Vec3 {
+	return p.eyeAt(p.InterpolatedCenter(alpha))
+}
+
+// EyeAt returns the position of the player's eye if their AABB was centred at
+// the given position.
+func (p *Player) eyeAt(center mgl32.Vec3) mgl32.Vec3 {
 	// The player's eye sits slightly below the top of their AABB, 90% of the
 	// way up their body
-	return mgl32.Vec3{p.AABB.Center.X(),
-		p.AABB.Center.Y() + p.AABB.Size.Y()*0.4,
-		p.AABB.Center.Z()}
+	return mgl32.Vec3{center.X(), center.Y() + p.AABB.Size.Y()*0.4,
+		center.Z()}
 }
@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"github.com/benanders/mineral/math"
+	"github.com/benanders/mineral/world"
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// Buoyancy is the fraction of gravity cancelled out for an entity that's
+	// completely submerged in a fluid. Entities that are only partly submerged
+	// get a proportion of this.
+	buoyancy = 0.75
+
+	// FluidDrag is the fraction of its vertical velocity a submerged entity
+	// keeps each update tick.
+	fluidDrag = 0.8
+
+	// MaxSwimSpeed is the fastest a submerged entity can move vertically, in
+	// blocks per update tick. Falling into a fluid slows an entity down to
+	// this speed straight away.
+	maxSwimSpeed = 0.1
+
+	// SwimAcceleration is the upward acceleration of a submerged entity while
+	// it's swimming up (holding jump), in blocks per update tick squared.
+	swimAcceleration = 0.012
+
+	// SurfaceSubmersion is how submerged an entity must be for swimming up to
+	// push it any higher. Entities swimming up bob at the surface with their
+	// head out of the fluid, rather than leaping out of it.
+	surfaceSubmersion = 0.5
+
+	// SwimSpeedMultiplier is the factor by which an entity's horizontal
+	// movement is slowed while it's submerged.
+	swimSpeedMultiplier = 0.5
+)
+
+// Submersion returns how much of the entity is submerged in a fluid (like
+// water), from 0 (not at all) to 1 (completely). It's the fraction of the
+// height of the entity's AABB that overlaps fluid blocks in the column of
+// blocks at its centre.
+func (e *Entity) submersion(w *world.World) float32 {
+	x, y1, z := world.ToWorldSpace(e.AABB.Center.X(), e.AABB.MinY(),
+		e.AABB.Center.Z())
+	_, y2, _ := world.ToWorldSpace(e.AABB.Center.X(), e.AABB.MaxY(),
+		e.AABB.Center.Z())
+	y1, y2 = w.ClampHeight(y1, y2)
+
+	submerged := float32(0.0)
+	for y := y1; y <= y2; y++ {
+		block, ok := w.GetBlock(x, y, z)
+		if !ok || !w.GetBlockInfo(block).Fluid {
+			continue
+		}
+		bottom := math32.Max(float32(y), e.AABB.MinY())
+		top := math32.Min(float32(y+1), e.AABB.MaxY())
+		submerged += math32.Max(top-bottom, 0.0)
+	}
+	return math32.Min(submerged/e.AABB.Size.Y(), 1.0)
+}
+
+// ApplySwimming works out the entity's vertical velocity for this tick while
+// it's at least partly submerged in a fluid, instead of the usual gravity.
+// Buoyancy cancels out some of gravity, drag slows the entity down, and
+// swimming up (holding jump) pushes it upwards.
+//
+// Swimming up against a wall lets the entity climb out of the fluid onto the
+// block above, by giving it the same upward velocity as a jump.
+func (e *Entity) applySwimming() {
+	gravity := Gravity * (1.0 - buoyancy*e.submerged)
+	vy := e.velocity.Y() - gravity
+	if e.swimUp && e.submerged > surfaceSubmersion {
+		vy += swimAcceleration
+	}
+	vy = math.Clamp(vy*fluidDrag, -maxSwimSpeed, maxSwimSpeed)
+	if e.swimUp && e.againstWall {
+		vy = math32.Max(vy, JumpVelocity)
+	}
+	e.velocity = mgl32.Vec3{e.velocity.X(), vy, e.velocity.Z()}
+}
+
+// SlowSwimming slows down the entity's horizontal movement while it's at least
+// partly submerged in a fluid.
+func (e *Entity) slowSwimming() {
+	e.moveDelta = mgl32.Vec3{e.moveDelta.X() * swimSpeedMultiplier,
+		e.moveDelta.Y(), e.moveDelta.Z() * swimSpeedMultiplier}
+}
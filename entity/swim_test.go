@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestSubmersion(t *testing.T) {
+	w := newTestWorld()
+	setBlock(t, w, 0, testFloorY, 0, "Water")
+	e := newTestEntity(standingAt(0.5, 0.5))
+
+	// Only the bottom block of the entity's column is water
+	want := float32(1.0) / PlayerHeight
+	if got := e.submersion(w); mgl32.Abs(got-want) > 1e-5 {
+		t.Errorf("got submersion %v, want %v", got, want)
+	}
+
+	// Water next to the entity's column doesn't count
+	if got := newTestEntity(standingAt(1.5, 0.5)).submersion(w); got != 0.0 {
+		t.Errorf("got submersion %v beside the water, want 0", got)
+	}
+
+	setBlock(t, w, 0, testFloorY+1, 0, "Water")
+	if got := e.submersion(w); got != 1.0 {
+		t.Errorf("got submersion %v under water, want 1", got)
+	}
+}
+
+func TestSwimmingSinksSlowly(t *testing.T) {
+	e := newTestEntity(standingAt(0.5, 0.5))
+	e.submerged = 1.0
+	e.applySwimming()
+	want := float32(-Gravity * (1.0 - buoyancy) * fluidDrag)
+	if got := e.velocity.Y(); mgl32.Abs(got-want) > 1e-6 {
+		t.Errorf("got vertical velocity %v, want %v", got, want)
+	}
+
+	// Falling into water slows straight down to the maximum swim speed
+	e.velocity = mgl32.Vec3{0.0, -2.0, 0.0}
+	e.applySwimming()
+	if got := e.velocity.Y(); got != -maxSwimSpeed {
+		t.Errorf("got vertical velocity %v, want %v", got, -maxSwimSpeed)
+	}
+}
+
+func TestSwimmingUp(t *testing.T) {
+	e := newTestEntity(standingAt(0.5, 0.5))
+	e.submerged = 1.0
+	e.swimUp = true
+	e.moveDelta = mgl32.Vec3{0.2, 0.0, -0.4}
+	e.applySwimming()
+	e.slowSwimming()
+	if e.velocity.Y() <= 0.0 {
+		t.Errorf("got vertical velocity %v swimming up, want upwards",
+			e.velocity.Y())
+	}
+	if want := (mgl32.Vec3{0.1, 0.0, -0.2}); !e.moveDelta.ApproxEqual(want) {
+		t.Errorf("got movement %v in water, want %v", e.moveDelta, want)
+	}
+
+	// Swimming up at the surface doesn't lift the entity out of the water
+	e.velocity = mgl32.Vec3{}
+	e.submerged = surfaceSubmersion
+	e.applySwimming()
+	if e.velocity.Y() > 0.0 {
+		t.Errorf("got vertical velocity %v at the surface, want none up",
+			e.velocity.Y())
+	}
+
+	// Unless the entity is against a wall, so it can climb out
+	e.againstWall = true
+	e.applySwimming()
+	if e.velocity.Y() != JumpVelocity {
+		t.Errorf("got vertical velocity %v against a wall, want %v",
+			e.velocity.Y(), JumpVelocity)
+	}
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"math/rand"
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	// The minimum and maximum number of update ticks a wandering entity walks
+	// in one direction for before stopping.
+	minWanderWalkTicks = 20
+	maxWanderWalkTicks = 100
+
+	// The minimum and maximum number of update ticks a wandering entity stands
+	// still for between walks.
+	minWanderPauseTicks = 20
+	maxWanderPauseTicks = 80
+)
+
+// WanderController is a simple mob AI controller, which makes an entity
+// wander aimlessly around the world. The entity walks in a random direction
+// for a random amount of time, stops for a while, then sets off again in
+// another direction.
+//
+// Directions are relative to the way the entity is facing, and the entity's
+// move speed applies as usual.
+type WanderController struct {
+	// Random numbers come from the controller's own generator, so entities
+	// created with the same seed always wander in the same way
+	rand *rand.Rand
+
+	walking   bool       // True while walking, false while pausing
+	direction mgl32.Vec3 // The normalized direction being walked in
+	ticksLeft int        // Ticks until the entity stops or sets off again
+}
+
+// NewWanderController creates a new wander controller whose random choices are
+// derived from `seed`. The entity starts off standing still.
+func NewWanderController(seed int64) *WanderController {
+	return &WanderController{rand: rand.New(rand.NewSource(seed))}
+}
+
+// HandleEvent implements the `Controller` interface. Mobs ignore user input.
+func (c *WanderController) HandleEvent(evt sdl.Event) {}
+
+// Update implements the `Controller` interface.
+func (c *WanderController) Update(entity Controllable) {
+	// Switch between walking and pausing once the current one runs out
+	if c.ticksLeft <= 0 {
+		c.walking = !c.walking
+		if c.walking {
+			angle := c.rand.Float32() * 2.0 * math32.Pi
+			c.direction = mgl32.Vec3{math32.Sin(angle), 0.0, math32.Cos(angle)}
+			c.ticksLeft = randomTicks(c.rand, minWanderWalkTicks,
+				maxWanderWalkTicks)
+		} else {
+			c.ticksLeft = randomTicks(c.rand, minWanderPauseTicks,
+				maxWanderPauseTicks)
+		}
+	}
+	c.ticksLeft--
+
+	if c.walking {
+		entity.Move(c.direction)
+	}
+}
+
+// RandomTicks returns a random number of ticks between `min` and `max`
+// inclusive.
+func randomTicks(r *rand.Rand, min, max int) int {
+	return min + r.Intn(max-min+1)
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// WanderMoves runs a wander controller for the given number of update ticks,
+// and returns the move it issued on each tick (zero while pausing).
+func wanderMoves(c *WanderController, ticks int) []mgl32.Vec3 {
+	moves := make([]mgl32.Vec3, ticks)
+	for i := range moves {
+		var entity testControllable
+		c.Update(&entity)
+		moves[i] = entity.moved
+	}
+	return moves
+}
+
+func TestWanderControllerWalksAndPauses(t *testing.T) {
+	const ticks = 1000
+	moves := wanderMoves(NewWanderController(1), ticks)
+
+	var walking, directions int
+	var last mgl32.Vec3
+	for i, move := range moves {
+		if move == (mgl32.Vec3{}) {
+			continue
+		}
+		walking++
+		if move.Y() != 0.0 || mgl32.Abs(move.Len()-1.0) > 1e-5 {
+			t.Fatalf("tick %d moved %v, want a horizontal unit vector", i,
+				move)
+		}
+		if !move.ApproxEqual(last) {
+			directions++
+			last = move
+		}
+	}
+	if walking == 0 {
+		t.Fatal("controller never moved")
+	}
+	if walking == ticks {
+		t.Error("controller never paused")
+	}
+	if directions < 2 {
+		t.Error("controller never changed direction")
+	}
+}
+
+func TestWanderControllerIsDeterministic(t *testing.T) {
+	a := wanderMoves(NewWanderController(7), 500)
+	b := wanderMoves(NewWanderController(7), 500)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("tick %d moved %v and %v with the same seed", i, a[i],
+				b[i])
+		}
+	}
+}
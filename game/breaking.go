@@ -0,0 +1,64 @@
+package game
+
+import (
+	"github.com/chewxy/math32"
+)
+
+// BreakTicksPerHardness is the number of update ticks taken to break a block
+// in survival mode, for each unit of the block's hardness.
+const breakTicksPerHardness = 1.5 * TicksPerSecond
+
+// BreakTicksTolerance is how far over a whole number of ticks a break time
+// can be and still round down to it, to allow for floating point error.
+const breakTicksTolerance = 1e-3
+
+// BreakTicks returns the number of update ticks taken to break a block with
+// the given hardness in survival mode, which is proportional to the hardness.
+// Blocks with no hardness break in a single tick. The hardness must not be
+// negative, since those blocks can't be broken.
+func breakTicks(hardness float32) int {
+	// Hardnesses like 0.6 can't be represented exactly, so ignore tiny errors
+	// that would otherwise round up to an extra tick
+	ticks := hardness*breakTicksPerHardness - breakTicksTolerance
+	return int(math32.Max(math32.Ceil(ticks), 1.0))
+}
+
+// BreakProgress keeps track of how far through breaking a block the player is
+// in survival mode, while they hold down the break button.
+type breakProgress struct {
+	active bool
+	pos    [3]int // The block being broken
+	ticks  int    // Number of update ticks spent breaking it so far
+	total  int    // Number of update ticks needed to break it
+}
+
+// Reset stops breaking the current block, losing any progress.
+func (b *breakProgress) reset() {
+	*b = breakProgress{}
+}
+
+// Advance continues breaking the block at `pos` by one update tick, given the
+// total number of ticks it takes to break. Looking at a different block
+// starts from scratch. Returns true once the block is broken, after which the
+// next block starts from scratch too.
+func (b *breakProgress) advance(pos [3]int, total int) bool {
+	if !b.active || pos != b.pos {
+		*b = breakProgress{active: true, pos: pos}
+	}
+	b.total = total
+	b.ticks++
+	if b.ticks >= b.total {
+		b.reset()
+		return true
+	}
+	return false
+}
+
+// Fraction returns how far through breaking the current block the player is,
+// from 0 to 1.
+func (b *breakProgress) fraction() float32 {
+	if !b.active || b.total == 0 {
+		return 0.0
+	}
+	return float32(b.ticks) / float32(b.total)
+}
@@ -0,0 +1,70 @@
+package game
+
+import "testing"
+
+func TestBreakProgressAdvance(t *testing.T) {
+	var b breakProgress
+	pos := [3]int{1, 2, 3}
+	for i := 1; i < 4; i++ {
+		if b.advance(pos, 4) {
+			t.Fatalf("block broke after %d of 4 ticks", i)
+		}
+		if got, want := b.fraction(), float32(i)/4.0; got != want {
+			t.Errorf("got fraction %v after %d ticks, want %v", got, i, want)
+		}
+	}
+	if !b.advance(pos, 4) {
+		t.Fatal("block didn't break after 4 ticks")
+	}
+
+	// The next block starts from scratch
+	if b.fraction() != 0.0 {
+		t.Errorf("got fraction %v after breaking, want 0", b.fraction())
+	}
+}
+
+func TestBreakProgressRestartsOnNewBlock(t *testing.T) {
+	var b breakProgress
+	b.advance([3]int{1, 2, 3}, 3)
+	b.advance([3]int{1, 2, 3}, 3)
+
+	// Looking at another block loses the progress on the first
+	if b.advance([3]int{1, 2, 4}, 3) {
+		t.Fatal("new block broke straight away")
+	}
+	if got, want := b.fraction(), float32(1.0)/3.0; got != want {
+		t.Errorf("got fraction %v on the new block, want %v", got, want)
+	}
+
+	b.reset()
+	if b.fraction() != 0.0 {
+		t.Errorf("got fraction %v after resetting, want 0", b.fraction())
+	}
+}
+
+func TestBreakProgressInstantBlocks(t *testing.T) {
+	var b breakProgress
+	if !b.advance([3]int{0, 0, 0}, 1) {
+		t.Error("block taking 1 tick didn't break straight away")
+	}
+}
+
+func TestBreakTicks(t *testing.T) {
+	tests := []struct {
+		hardness float32
+		want     int
+	}{
+		{0.0, 1},
+		{0.001, 1},
+		{1.0, 90},
+		{0.5, 45},
+		{2.0, 180},
+		{0.6, 54},
+	}
+	for _, test := range tests {
+		if got := breakTicks(test.hardness); got != test.want {
+			t.Errorf("got %d ticks to break hardness %v, want %d", got,
+				test.hardness, test.want)
+		}
+	}
+}
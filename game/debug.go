@@ -0,0 +1,183 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/benanders/mineral/entity"
+	"github.com/benanders/mineral/hud"
+	"github.com/benanders/mineral/world"
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The colors of the borders drawn around chunks that are waiting to be
+// generated, that are loaded but have nothing to draw, and that have been
+// meshed.
+var (
+	loadingChunkColor = mgl32.Vec4{1.0, 0.2, 0.2, 1.0}
+	loadedChunkColor  = mgl32.Vec4{1.0, 0.9, 0.2, 1.0}
+	meshedChunkColor  = mgl32.Vec4{0.2, 1.0, 0.2, 1.0}
+)
+
+// The colors of the collision boxes drawn around entities and blocks.
+var (
+	entityBoxColor = mgl32.Vec4{0.2, 0.8, 1.0, 1.0}
+	blockBoxColor  = mgl32.Vec4{1.0, 1.0, 1.0, 0.6}
+)
+
+const (
+	// The number of recent frames averaged over to calculate the frame rate
+	// and timings shown on the debug screen.
+	numFrameSamples = 60
+
+	// The distance of the debug text from the top left corner of the window,
+	// in screen pixels.
+	debugMargin = 4.0
+
+	// The distance around the player's AABB, in blocks, within which the
+	// collision boxes of solid blocks are drawn.
+	collisionBoxRadius = 2.0
+
+	// How far collision boxes are drawn outside their true size, in blocks,
+	// so they aren't hidden by the faces of blocks due to z-fighting.
+	collisionBoxInflation = 0.004
+)
+
+// FrameTimes records the durations of the most recent frames in a ring
+// buffer, so that they can be averaged. Averaging over a number of frames
+// stops the numbers shown on the debug screen from flickering.
+type frameTimes struct {
+	samples [numFrameSamples]time.Duration
+	next    int // Index in `samples` at which the next sample is recorded
+	count   int // Number of samples recorded, up to the size of the buffer
+}
+
+// Record adds a new sample to the buffer, replacing the oldest one if the
+// buffer is full.
+func (f *frameTimes) record(duration time.Duration) {
+	f.samples[f.next] = duration
+	f.next = (f.next + 1) % numFrameSamples
+	if f.count < numFrameSamples {
+		f.count++
+	}
+}
+
+// Average returns the mean of the samples in the buffer, or 0 if nothing has
+// been recorded yet.
+func (f *frameTimes) average() time.Duration {
+	if f.count == 0 {
+		return 0
+	}
+	var total time.Duration
+	for i := 0; i < f.count; i++ {
+		total += f.samples[i]
+	}
+	return total / time.Duration(f.count)
+}
+
+// FramesPerSecond returns the frame rate corresponding to the average frame
+// time, or 0 if nothing has been recorded yet.
+func (f *frameTimes) framesPerSecond() float64 {
+	average := f.average()
+	if average == 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(average)
+}
+
+// Milliseconds converts a duration into a fractional number of milliseconds,
+// for display.
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+// FormatPosition formats a position in world space for the debug screen.
+func formatPosition(pos mgl32.Vec3) string {
+	return fmt.Sprintf("XYZ: %.3f / %.3f / %.3f", pos.X(), pos.Y(), pos.Z())
+}
+
+// FormatChunk formats the coordinates of a chunk for the debug screen.
+func formatChunk(p, q int) string {
+	return fmt.Sprintf("Chunk: %d %d", p, q)
+}
+
+// FormatWorldTime formats the world time for the debug screen, as the day
+// number and the progress through that day.
+func formatWorldTime(worldTime float32) string {
+	day := math32.Floor(worldTime)
+	return fmt.Sprintf("Day %.0f, time: %.3f", day, worldTime-day)
+}
+
+// DebugLines returns the lines of text shown on the debug screen.
+func (g *Game) debugLines() []string {
+	return []string{
+		fmt.Sprintf("%.0f fps", g.frameTimes.framesPerSecond()),
+		fmt.Sprintf("Frame: %.2f ms, update: %.2f ms, render: %.2f ms",
+			milliseconds(g.frameTimes.average()),
+			milliseconds(g.updateTimes.average()),
+			milliseconds(g.renderTimes.average())),
+		formatPosition(g.player.AABB.Center),
+		formatChunk(g.playerChunkP, g.playerChunkQ),
+		fmt.Sprintf("Chunks pending: %d", g.world.PendingChunkCount()),
+		formatWorldTime(g.worldTime),
+		"Mode: " + g.player.GameMode().String(),
+		"VSync: " + swapIntervalName(g.swapInterval),
+	}
+}
+
+// RenderDebug draws the debug screen in the top left corner of the window.
+func (g *Game) renderDebug() {
+	for i, line := range g.debugLines() {
+		y := debugMargin + float32(i)*hud.LineHeight
+		g.text.DrawText(debugMargin, y, line)
+	}
+}
+
+// ChunkBorderColor returns the color of the border drawn around a chunk in the
+// given state.
+func chunkBorderColor(state world.ChunkState) mgl32.Vec4 {
+	switch state {
+	case world.ChunkLoading:
+		return loadingChunkColor
+	case world.ChunkLoaded:
+		return loadedChunkColor
+	default:
+		return meshedChunkColor
+	}
+}
+
+// RenderChunkBorders draws the borders of every chunk that's loaded or waiting
+// to be generated, colored by its state. Chunks outside the camera's view
+// aren't drawn, so it's cheap enough to leave on.
+func (g *Game) renderChunkBorders() {
+	frustum := g.camera.Frustum()
+	for _, chunk := range g.world.ChunkSnapshots() {
+		if frustum.IntersectsAABB(chunk.AABB) {
+			g.outline.RenderBox(g.camera.View, chunk.AABB,
+				chunkBorderColor(chunk.State))
+		}
+	}
+}
+
+// RenderCollisionBoxes draws the AABBs of the player and every other entity,
+// at their interpolated positions, and of the solid blocks around the player.
+func (g *Game) renderCollisionBoxes(alpha float32) {
+	region := g.player.AABB.Grow(collisionBoxRadius)
+	for _, box := range g.world.CollisionAABBs(region) {
+		g.outline.RenderBox(g.camera.View, box.Grow(collisionBoxInflation),
+			blockBoxColor)
+	}
+	entities := append([]*entity.Entity{&g.player.Entity},
+		g.entities.Entities()...)
+	for _, d := range g.entities.Drops() {
+		entities = append(entities, &d.Entity)
+	}
+	for _, e := range entities {
+		box := e.AABB
+		box.Center = e.InterpolatedCenter(alpha)
+		g.outline.RenderBox(g.camera.View, box.Grow(collisionBoxInflation),
+			entityBoxColor)
+	}
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestFrameTimesAverage(t *testing.T) {
+	var times frameTimes
+	if got := times.average(); got != 0 {
+		t.Errorf("got average %v with no samples, want 0", got)
+	}
+	if got := times.framesPerSecond(); got != 0 {
+		t.Errorf("got %v fps with no samples, want 0", got)
+	}
+
+	times.record(10 * time.Millisecond)
+	times.record(30 * time.Millisecond)
+	if got := times.average(); got != 20*time.Millisecond {
+		t.Errorf("got average %v, want 20ms", got)
+	}
+	if got := times.framesPerSecond(); got != 50 {
+		t.Errorf("got %v fps, want 50", got)
+	}
+}
+
+func TestFrameTimesReplacesOldestSample(t *testing.T) {
+	var times frameTimes
+	times.record(time.Second)
+	for i := 0; i < numFrameSamples; i++ {
+		times.record(4 * time.Millisecond)
+	}
+
+	// The slow first frame has been pushed out of the buffer
+	if got := times.average(); got != 4*time.Millisecond {
+		t.Errorf("got average %v, want 4ms", got)
+	}
+	if got := times.framesPerSecond(); got != 250 {
+		t.Errorf("got %v fps, want 250", got)
+	}
+}
+
+func TestFormatPosition(t *testing.T) {
+	got := formatPosition(mgl32.Vec3{1.5, -2.25, 1000.0})
+	if want := "XYZ: 1.500 / -2.250 / 1000.000"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFormatChunk(t *testing.T) {
+	if got, want := formatChunk(-3, 12), "Chunk: -3 12"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestChunkBorderColor(t *testing.T) {
+	tests := []struct {
+		state world.ChunkState
+		want  mgl32.Vec4
+	}{
+		{world.ChunkLoading, loadingChunkColor},
+		{world.ChunkLoaded, loadedChunkColor},
+		{world.ChunkMeshed, meshedChunkColor},
+	}
+	for _, test := range tests {
+		if got := chunkBorderColor(test.state); got != test.want {
+			t.Errorf("got color %v for state %v, want %v", got, test.state,
+				test.want)
+		}
+	}
+
+	// Each state can be told apart
+	if loadingChunkColor == loadedChunkColor ||
+		loadedChunkColor == meshedChunkColor ||
+		meshedChunkColor == loadingChunkColor {
+		t.Error("chunk states share a border color")
+	}
+}
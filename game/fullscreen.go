@@ -0,0 +1,55 @@
+package game
+
+import (
+	"log"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Fullscreen tracks whether the window is fullscreen, and the size the window
+// was before it went fullscreen so that it can be put back afterwards.
+type fullscreen struct {
+	enabled bool
+
+	// The size of the window, in screen coordinates, when fullscreen was last
+	// entered. Zero if the window started out fullscreen, in which case the
+	// window system is left to pick the windowed size
+	windowedWidth, windowedHeight int32
+}
+
+// Toggle switches between fullscreen and windowed, given the current size of
+// the window. When entering fullscreen, the current size is saved. When
+// leaving it, the saved size is returned, with `restore` set to false if
+// there's no saved size.
+func (f *fullscreen) toggle(width, height int32) (restoreWidth,
+	restoreHeight int32, restore bool) {
+	f.enabled = !f.enabled
+	if f.enabled {
+		f.windowedWidth, f.windowedHeight = width, height
+		return 0, 0, false
+	}
+	restore = f.windowedWidth > 0 && f.windowedHeight > 0
+	return f.windowedWidth, f.windowedHeight, restore
+}
+
+// ToggleFullscreen switches the window between borderless fullscreen (covering
+// the whole desktop) and windowed, restoring its previous size when leaving
+// fullscreen. The OpenGL context belongs to the window rather than its mode,
+// so it survives the switch; only the viewport and projection need updating.
+func (g *Game) toggleFullscreen() {
+	width, height := g.window.GetSize()
+	restoreWidth, restoreHeight, restore := g.fullscreen.toggle(width, height)
+	flags := uint32(0)
+	if g.fullscreen.enabled {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	if err := g.window.SetFullscreen(flags); err != nil {
+		log.Println("failed to toggle fullscreen:", err)
+		g.fullscreen.enabled = !g.fullscreen.enabled
+		return
+	}
+	if restore {
+		g.window.SetSize(restoreWidth, restoreHeight)
+	}
+	g.resize()
+}
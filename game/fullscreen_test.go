@@ -0,0 +1,37 @@
+package game
+
+import "testing"
+
+func TestFullscreenRestoresWindowedSize(t *testing.T) {
+	var f fullscreen
+	if _, _, restore := f.toggle(800, 600); restore || !f.enabled {
+		t.Fatal("entering fullscreen didn't enable it without restoring")
+	}
+
+	// The fullscreen size isn't saved when leaving fullscreen
+	width, height, restore := f.toggle(1920, 1080)
+	if f.enabled {
+		t.Error("leaving fullscreen didn't disable it")
+	}
+	if !restore || width != 800 || height != 600 {
+		t.Errorf("got restored size %dx%d (restore %v), want 800x600", width,
+			height, restore)
+	}
+}
+
+func TestFullscreenStartingFullscreen(t *testing.T) {
+	// There's no windowed size to go back to if the window started out
+	// fullscreen, so the window system picks it
+	f := fullscreen{enabled: true}
+	if _, _, restore := f.toggle(1920, 1080); restore || f.enabled {
+		t.Error("restored a windowed size that was never saved")
+	}
+
+	// Going fullscreen again saves the size picked
+	f.toggle(1280, 720)
+	if width, height, restore := f.toggle(1920, 1080); !restore ||
+		width != 1280 || height != 720 {
+		t.Errorf("got restored size %dx%d (restore %v), want 1280x720",
+			width, height, restore)
+	}
+}
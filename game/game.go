@@ -1,92 +1,705 @@
 package game
 
 import (
+	"log"
+	"path/filepath"
 	"time"
 
+	"github.com/benanders/mineral/audio"
 	"github.com/benanders/mineral/camera"
+	"github.com/benanders/mineral/config"
 	"github.com/benanders/mineral/entity"
+	"github.com/benanders/mineral/hud"
+	"github.com/benanders/mineral/inventory"
+	"github.com/benanders/mineral/math"
+	"github.com/benanders/mineral/particle"
+	"github.com/benanders/mineral/render"
 	"github.com/benanders/mineral/sky"
 	"github.com/benanders/mineral/world"
 
+	"github.com/chewxy/math32"
+	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
+const (
+	// TicksPerSecond is the number of times the game state is updated per
+	// second of real time.
+	TicksPerSecond = 60
+
+	// DefaultDayLength is the default real time taken for a full day/night
+	// cycle, which is the same as Minecraft's.
+	DefaultDayLength = 20 * time.Minute
+)
+
+// SprintFovIncrease is the amount by which the camera's field of view widens
+// while the player is sprinting, in radians.
+const sprintFovIncrease = 10.0 * math32.Pi / 180.0
+
+// BlockSoundVolume is the volume at which sounds are played when the player
+// breaks or places a block, between 0 and 1.
+const blockSoundVolume = 0.8
+
+// FootstepVolume is the volume at which the player's footsteps are played,
+// between 0 and 1.
+const footstepVolume = 0.3
+
+// Reach is the maximum distance, in blocks, from the player's eye at which the
+// player can interact with blocks.
+const reach = 5.0
+
 // Game stores all the required state information while the game is running.
 type Game struct {
 	window *sdl.Window
 
+	// The player's graphics and control settings, as they were when the game
+	// started other than VSync and smooth lighting (see `Settings` for the
+	// current settings)
+	settings config.Settings
+
+	// The swap interval in use, which depends on whether VSync is on (see
+	// `setVSync`). It's toggled with F10
+	swapInterval int
+
 	sky   *sky.Sky
 	world *world.World
 
 	camera           *camera.Camera
 	player           *entity.Player
-	playerController entity.Controller
+	playerController *entity.InputController
+	hotbar           *inventory.Hotbar
+
+	entities *entity.Manager // All entities other than the player
+
+	// The chunk the player is currently in, around which chunks are loaded
+	playerChunkP, playerChunkQ int
+
+	// Shows the player what they're aiming at
+	crosshair *hud.Crosshair
+
+	// The size of the window's drawable area, in pixels
+	screenWidth, screenHeight int32
+
+	// Whether the window is fullscreen, toggled with F11
+	fullscreen fullscreen
+
+	// True while the game is paused, during which the game state isn't
+	// updated and the mouse is released. The overlay darkens the scene while
+	// paused. It's toggled with escape
+	paused  bool
+	overlay *hud.Overlay
+
+	// Outlines the block the player is looking at
+	outline *render.BlockOutline
+
+	// How far through breaking a block the player is in survival mode, shown
+	// by cracks drawn over the block
+	breaking breakProgress
+	cracks   *render.BlockCracks
+
+	// Pieces of broken blocks and rain drops. Rain drops show part of the
+	// water texture, if there's a water block
+	particles *particle.System
+	rainUV    world.FaceUV
+	canRain   bool
+
+	// The debug screen shows the frame rate and the player's position. It's
+	// toggled with F3
+	text        *hud.Text
+	showDebug   bool
+	frameTimes  frameTimes // Time between consecutive frames
+	updateTimes frameTimes // Time taken by each update tick
+	renderTimes frameTimes // Time taken to render each frame
+
+	// True to draw the borders of every loaded chunk, colored by how far
+	// through loading each one is. It's toggled with F4
+	showChunkBorders bool
+
+	// True to draw the AABBs of every entity and of the solid blocks around
+	// the player, for debugging collisions. It's toggled with F5
+	showCollisionBoxes bool
 
-	startTime time.Time
+	// Number of update ticks since the game started, used to animate
+	// textures. Unlike the world time, this can't be changed
+	ticks uint64
+
+	// World time is measured in days since the world was created; the
+	// fractional part is the progress through the current day. It's derived
+	// from a number of update ticks, rather than accumulated, so that
+	// floating point error doesn't build up over time
+	dayTicks   uint64 // Number of update ticks into the world's time
+	ticksInDay uint64 // Number of update ticks in a full day
+	worldTime  float32
+
+	startTime  time.Time
+	lastRender time.Time // The time at which the previous frame was rendered
+
+	// The folder that edited chunks and the player's state are saved to and
+	// loaded from, or empty if the game isn't saved (see `Save`)
+	saveDir string
 }
 
-// New creates a new game state.
-func New(window *sdl.Window) *Game {
-	g := Game{window: window, startTime: time.Now()}
+// New creates a new game state. `dayLength` is the real time taken for a full
+// day/night cycle, and the world is created with the given seed. The render
+// radius, field of view, lighting, mouse look, and auto-jump are taken from
+// `settings`. Edited chunks and the player are loaded from `saveDir` if they
+// were saved there, and are saved back to it by `Save`; nothing is saved if
+// it's empty. Returns an error if any of the game's assets (like its shaders)
+// fail to load, so the caller can report it to the user.
+func New(window *sdl.Window, dayLength time.Duration,
+	settings config.Settings, seed int64, saveDir string) (*Game, error) {
+	g := Game{window: window, settings: settings, startTime: time.Now(),
+		lastRender: time.Now(), saveDir: saveDir}
+	g.ticksInDay = ticksInDay(dayLength)
+	g.fullscreen.enabled = window.GetFlags()&sdl.WINDOW_FULLSCREEN != 0
+	g.setVSync(settings.VSync)
+
+	var err error
+	if g.sky, err = sky.New(); err != nil {
+		return nil, err
+	}
+	if g.text, err = hud.NewText(); err != nil {
+		g.Destroy()
+		return nil, err
+	}
+	if g.crosshair, err = hud.NewCrosshair(); err != nil {
+		g.Destroy()
+		return nil, err
+	}
+	if g.overlay, err = hud.NewOverlay(); err != nil {
+		g.Destroy()
+		return nil, err
+	}
+	if g.outline, err = render.NewBlockOutline(); err != nil {
+		g.Destroy()
+		return nil, err
+	}
+	if g.cracks, err = render.NewBlockCracks(); err != nil {
+		g.Destroy()
+		return nil, err
+	}
+	if g.particles, err = particle.New(); err != nil {
+		g.Destroy()
+		return nil, err
+	}
+	if g.world, err = world.New(settings.RenderRadius, world.DefaultHeight,
+		seed); err != nil {
+		g.Destroy()
+		return nil, err
+	}
+	g.world.SetSmoothLighting(settings.SmoothLighting)
+	if saveDir != "" {
+		g.world.SetSaveDir(filepath.Join(saveDir, chunksDir))
+	}
+
+	if water, ok := g.world.FindBlock("Water"); ok {
+		g.rainUV = g.world.GetBlockInfo(water).ParticleUV()
+		g.canRain = true
+	}
 
-	g.sky = sky.New()
-	g.world = world.New(8)
-	g.world.GenChunksAround(0, 0)
+	// Put the player back where they were when the game was last saved.
+	// Otherwise, spawn them standing on the ground at the centre of the world
+	g.hotbar = inventory.NewHotbar(inventory.HotbarSize)
+	loaded, err := g.loadPlayer()
+	if err != nil {
+		log.Println("failed to load player:", err)
+	}
+	if !loaded {
+		spawn := g.world.FindSpawn(0, 0)
+		spawn = spawn.Add(mgl32.Vec3{0.0, entity.PlayerHeight / 2.0, 0.0})
+		g.player = entity.NewPlayer(spawn, mgl32.Vec2{})
+	}
+	g.player.AutoJump = settings.AutoJump
+	g.playerChunkP, g.playerChunkQ = g.playerChunk()
+	g.world.GenChunksAround(g.playerChunkP, g.playerChunkQ)
 
-	g.player = entity.NewPlayer(mgl32.Vec3{0.0, 5.0, 0.0}, mgl32.Vec2{})
-	g.playerController = entity.NewInputController()
+	// Keep the chunk the player spawned in loaded, however far they wander
+	g.world.PinChunk(g.playerChunkP, g.playerChunkQ)
+	bindings, err := entity.LoadKeybindings()
+	if err != nil {
+		log.Println("failed to load key bindings:", err)
+	}
+	g.playerController = entity.NewInputController(bindings)
+	g.playerController.Hotbar = g.hotbar
+	g.playerController.SetMouseSensitivity(settings.MouseSensitivity)
+	g.playerController.SetInvertY(settings.InvertY)
+	g.playerController.SetLookSmoothing(settings.LookSmoothing)
+	g.entities = entity.NewManager(entity.DefaultDespawnRadius,
+		entity.NewPhysicsBatcher(entity.DefaultBatchThreshold,
+			entity.DefaultBatchSize))
 
-	w, h := sdl.GLGetDrawableSize(window)
-	aspect := float32(w) / float32(h)
 	g.camera = &camera.Camera{}
-	g.camera.Perspective(camera.Fov, aspect, camera.Near, camera.Far)
-	g.camera.Follow(g.player)
+	g.camera.Perspective(g.fov(), 1.0, camera.Near, camera.Far)
+	g.resize()
 
-	return &g
+	return &g, nil
 }
 
-// Destroy frees all resources allocated by the game state.
+// Destroy frees all resources allocated by the game state. Anything that
+// hasn't been created yet (if `New` failed part way through) is skipped.
 func (g *Game) Destroy() {
-	g.world.Destroy()
-	g.sky.Destroy()
+	if g.world != nil {
+		g.world.Destroy()
+	}
+	if g.sky != nil {
+		g.sky.Destroy()
+	}
+	if g.text != nil {
+		g.text.Destroy()
+	}
+	if g.crosshair != nil {
+		g.crosshair.Destroy()
+	}
+	if g.overlay != nil {
+		g.overlay.Destroy()
+	}
+	if g.outline != nil {
+		g.outline.Destroy()
+	}
+	if g.cracks != nil {
+		g.cracks.Destroy()
+	}
+	if g.particles != nil {
+		g.particles.Destroy()
+	}
 }
 
 // HandleEvent processes a user input event.
 func (g *Game) HandleEvent(evt sdl.Event) {
+	// Pause or resume the game with escape
+	if e, ok := evt.(*sdl.KeyboardEvent); ok && e.Type == sdl.KEYDOWN &&
+		e.Repeat == 0 && e.Keysym.Scancode == sdl.SCANCODE_ESCAPE {
+		g.togglePause()
+		return
+	}
+	// Rebuild the projection when the window changes size
+	if e, ok := evt.(*sdl.WindowEvent); ok &&
+		(e.Event == sdl.WINDOWEVENT_RESIZED ||
+			e.Event == sdl.WINDOWEVENT_SIZE_CHANGED) {
+		g.resize()
+	}
+	// Turn VSync on or off with F10, switch in and out of fullscreen with
+	// F11, and switch between smooth and flat lighting with F12
+	if e, ok := evt.(*sdl.KeyboardEvent); ok && e.Type == sdl.KEYDOWN &&
+		e.Repeat == 0 {
+		switch e.Keysym.Scancode {
+		case sdl.SCANCODE_F10:
+			g.setVSync(!g.settings.VSync)
+		case sdl.SCANCODE_F11:
+			g.toggleFullscreen()
+		case sdl.SCANCODE_F12:
+			g.settings.SmoothLighting = !g.settings.SmoothLighting
+			g.world.SetSmoothLighting(g.settings.SmoothLighting)
+		}
+	}
+	if g.paused {
+		g.handlePausedEvent(evt)
+		return
+	}
+
+	// Pick the block the player is looking at with the middle mouse button
+	if e, ok := evt.(*sdl.MouseButtonEvent); ok &&
+		e.Button == sdl.BUTTON_MIDDLE && e.State == sdl.PRESSED {
+		g.pickBlock()
+	}
+	// Toggle the debug screen with F3
+	if e, ok := evt.(*sdl.KeyboardEvent); ok && e.Type == sdl.KEYDOWN &&
+		e.Repeat == 0 && e.Keysym.Scancode == sdl.SCANCODE_F3 {
+		g.showDebug = !g.showDebug
+	}
+	// Toggle the chunk borders with F4 and the collision boxes with F5. Skip
+	// forward to the next noon or midnight with F6 and F7, and start or stop
+	// the rain with F8, for testing the sky in different conditions. Switch
+	// between survival and creative with F9
+	if e, ok := evt.(*sdl.KeyboardEvent); ok && e.Type == sdl.KEYDOWN &&
+		e.Repeat == 0 {
+		switch e.Keysym.Scancode {
+		case sdl.SCANCODE_F4:
+			g.showChunkBorders = !g.showChunkBorders
+		case sdl.SCANCODE_F5:
+			g.showCollisionBoxes = !g.showCollisionBoxes
+		case sdl.SCANCODE_F6:
+			g.skipToTimeOfDay(noon)
+		case sdl.SCANCODE_F7:
+			g.skipToTimeOfDay(midnight)
+		case sdl.SCANCODE_F8:
+			g.toggleRain()
+		case sdl.SCANCODE_F9:
+			g.toggleGameMode()
+		}
+	}
+	// Change the render radius with +/- or PageUp/PageDown, repeating while
+	// the key is held down
+	if e, ok := evt.(*sdl.KeyboardEvent); ok && e.Type == sdl.KEYDOWN {
+		switch e.Keysym.Scancode {
+		case sdl.SCANCODE_EQUALS, sdl.SCANCODE_KP_PLUS, sdl.SCANCODE_PAGEUP:
+			g.world.SetRenderRadius(g.world.RenderRadius + 1)
+		case sdl.SCANCODE_MINUS, sdl.SCANCODE_KP_MINUS,
+			sdl.SCANCODE_PAGEDOWN:
+			g.world.SetRenderRadius(g.world.RenderRadius - 1)
+		}
+	}
 	g.playerController.HandleEvent(evt)
 }
 
+// Resize updates the OpenGL viewport and the camera's aspect ratio to match
+// the current size of the window's drawable area. This can differ from the
+// window's size on high DPI displays.
+func (g *Game) resize() {
+	w, h := sdl.GLGetDrawableSize(g.window)
+	if w <= 0 || h <= 0 {
+		return // The window is minimised
+	}
+	gl.Viewport(0, 0, w, h)
+	g.screenWidth, g.screenHeight = w, h
+	g.camera.SetAspect(float32(w) / float32(h))
+	g.text.SetScreenSize(w, h)
+	g.crosshair.SetScreenSize(w, h)
+	g.camera.Follow(g.player)
+}
+
+// Settings returns the player's current graphics and control settings,
+// including any changed while playing (like the render radius), so they can
+// be saved for next time.
+func (g *Game) Settings() config.Settings {
+	settings := g.settings
+	settings.RenderRadius = g.world.RenderRadius
+	settings.Fullscreen = g.fullscreen.enabled
+	return settings
+}
+
+// Fov returns the camera's field of view from the settings, in radians.
+func (g *Game) fov() float32 {
+	return mgl32.DegToRad(g.settings.Fov)
+}
+
+// PickBlock puts the type of block the player is looking at in their hotbar,
+// if they're looking at a block within reach (see `Hotbar.Pick`).
+func (g *Game) pickBlock() {
+	pos, _, ok := g.world.Raycast(g.player.EyePosition(), g.player.Sight(),
+		reach)
+	if !ok {
+		return
+	}
+	if block, ok := g.world.GetBlock(pos[0], pos[1], pos[2]); ok {
+		g.hotbar.Pick(block.Type())
+	}
+}
+
+// UpdateBreaking breaks the block the player is looking at, if it's within
+// reach. Creative players break blocks as soon as they click. Survival
+// players have to hold down the break button for a time depending on the
+// block's hardness, starting again if they let go or look at another block.
+func (g *Game) updateBreaking(clicked bool) {
+	pos, _, ok := g.world.Raycast(g.player.EyePosition(), g.player.Sight(),
+		reach)
+	if !ok {
+		g.breaking.reset()
+		return
+	}
+	if g.player.GameMode() == entity.Creative {
+		g.breaking.reset()
+		if clicked {
+			g.breakBlock(pos)
+		}
+		return
+	}
+
+	block, ok := g.world.GetBlock(pos[0], pos[1], pos[2])
+	if !ok || !(clicked || g.playerController.IsBreaking()) {
+		g.breaking.reset()
+		return
+	}
+
+	// Unbreakable blocks don't even start to crack
+	info := g.world.GetBlockInfo(block)
+	if !info.Breakable() {
+		g.breaking.reset()
+		return
+	}
+	ticks := breakTicks(info.Hardness)
+	if g.breaking.advance(pos, ticks) {
+		g.breakBlock(pos)
+	}
+}
+
+// RenderCracks draws cracks over the block the player is breaking, showing
+// how close it is to breaking.
+func (g *Game) renderCracks() {
+	pos := g.breaking.pos
+	block, ok := g.world.GetBlock(pos[0], pos[1], pos[2])
+	if !ok {
+		return
+	}
+	box := g.world.GetBlockInfo(block).AABB(world.ToChunkSpace(pos[0], pos[1],
+		pos[2]))
+	g.cracks.Render(g.camera.View, box, g.breaking.fraction())
+}
+
+// BreakBlock breaks the block at the given world-space coordinates, playing
+// its break sound and scattering pieces of it. In survival mode, the block
+// drops something for the player to pick up.
+func (g *Game) breakBlock(pos [3]int) {
+	block, ok := g.world.BreakBlock(pos[0], pos[1], pos[2])
+	if !ok {
+		return
+	}
+	info := g.world.GetBlockInfo(block)
+	audio.Play(info.BreakSound, blockSoundVolume)
+	g.particles.EmitBlockBreak(pos, info.ParticleUV())
+	if g.player.GameMode() == entity.Survival && info.Drop() != world.Air {
+		center := mgl32.Vec3{float32(pos[0]) + 0.5, float32(pos[1]) + 0.5,
+			float32(pos[2]) + 0.5}
+		g.entities.SpawnDrop(entity.NewDrop(info.Drop(), center))
+	}
+}
+
+// PlaceBlock places the block selected in the hotbar against the face of the
+// block the player is looking at, if they're looking at a block within reach.
+// Blocks can't be placed inside the player or any other entity.
+func (g *Game) placeBlock() {
+	block := g.hotbar.Selected()
+	if block == world.Air {
+		return
+	}
+	hit, face, ok := g.world.Raycast(g.player.EyePosition(), g.player.Sight(),
+		reach)
+	if !ok {
+		return
+	}
+
+	// Only replace air
+	pos := world.AdjacentBlock(hit, face)
+	if existing, ok := g.world.GetBlock(pos[0], pos[1], pos[2]); !ok ||
+		existing != world.Air {
+		return
+	}
+
+	// Blocks like logs lie along the direction the player is facing
+	if g.world.GetBlockInfo(block).Orientable {
+		block = block.WithAxis(world.AxisFromDirection(g.player.Sight()))
+	}
+
+	// Check the block won't end up inside an entity
+	entities := g.entities.Entities()
+	occupied := make([]math.AABB, 0, len(entities)+1)
+	occupied = append(occupied, g.player.AABB)
+	for _, e := range entities {
+		occupied = append(occupied, e.AABB)
+	}
+	if g.world.CanPlaceBlock(pos[0], pos[1], pos[2], block, occupied) &&
+		g.world.SetBlock(pos[0], pos[1], pos[2], block) {
+		audio.Play(g.world.GetBlockInfo(block).PlaceSound, blockSoundVolume)
+	}
+}
+
+// PlayFootsteps plays the step sound of the block the player is walking on,
+// if they took a step during the most recent update tick.
+func (g *Game) playFootsteps() {
+	if !g.player.Stepped() {
+		return
+	}
+	if block, ok := g.player.GroundBlock(g.world); ok {
+		audio.Play(g.world.GetBlockInfo(block).StepSound, footstepVolume)
+	}
+}
+
+// ToggleRain starts the rain if the weather is clear, or stops it if it's
+// raining.
+func (g *Game) toggleRain() {
+	if g.world.Weather() == world.Rain {
+		g.world.SetWeather(world.Clear)
+	} else {
+		g.world.SetWeather(world.Rain)
+	}
+}
+
+// ToggleGameMode switches the player between survival and creative.
+func (g *Game) toggleGameMode() {
+	if g.player.GameMode() == entity.Creative {
+		g.player.SetGameMode(entity.Survival)
+	} else {
+		g.player.SetGameMode(entity.Creative)
+	}
+}
+
+// EmitRain spawns rain drops around the player while it's raining.
+func (g *Game) emitRain() {
+	strength := g.world.RainStrength()
+	if !g.canRain || strength <= 0.0 {
+		return
+	}
+	g.particles.EmitRain(g.world, g.player.EyePosition(), strength, g.rainUV)
+}
+
+// PlayerChunk returns the coordinates of the chunk the player is in.
+func (g *Game) playerChunk() (p, q int) {
+	center := g.player.AABB.Center
+	x, y, z := world.ToWorldSpace(center.X(), center.Y(), center.Z())
+	p, q, _, _, _ = world.ToChunkSpace(x, y, z)
+	return p, q
+}
+
+// UpdatePlayerChunk loads chunks around the player if they've moved into a
+// different chunk since the last update, unloading any chunks they've moved
+// away from. Returns true if the player changed chunk.
+//
+// Chunks are only loaded when the player crosses a chunk boundary, rather than
+// every tick, since checking every chunk within the render radius is costly.
+func (g *Game) updatePlayerChunk() bool {
+	p, q := g.playerChunk()
+	if p == g.playerChunkP && q == g.playerChunkQ {
+		return false
+	}
+	g.playerChunkP, g.playerChunkQ = p, q
+	g.world.GenChunksAround(p, q)
+	return true
+}
+
+// PrepareUpdates tells the game how many update ticks are about to be run in
+// the current frame, before `Update` is called that many times. Mouse
+// movement since the last frame is spread evenly across those ticks.
+func (g *Game) PrepareUpdates(ticks int) {
+	g.playerController.SpreadLook(ticks)
+}
+
 // Update advances the game state. It's called at a fixed time step, in order
 // to simplify some of the mechanics of the code (particularly the physics).
+// Nothing happens while the game is paused.
 func (g *Game) Update() {
+	if g.paused {
+		return
+	}
+	start := time.Now()
+	defer func() { g.updateTimes.record(time.Since(start)) }()
+
+	// Move the day/night cycle forward
+	g.advanceTime()
+
 	// Checks for completed chunk load requests
 	g.world.Update()
 
-	// Update the player's movement
-	g.player.ApplyMovementAndResolveCollisions(g.world)
+	// Update the movement of the player and all other entities, despawning
+	// any that are too far away, and pick up any drops the player touches
+	g.entities.Update(g.world, g.player)
+	g.entities.CollectDrops(g.player, g.hotbar)
+	g.playFootsteps()
+	g.particles.Update(g.world)
+	g.emitRain()
+
+	// Load chunks ahead of the player as they move around the world
+	g.updatePlayerChunk()
 
 	// Get the camera to follow the player
 	g.playerController.Update(g.player)
+
+	// Break blocks while the player holds down the break button, and place
+	// blocks if they clicked since the last update
+	breakBlock, placeBlock := g.playerController.BlockActions()
+	g.updateBreaking(breakBlock)
+	if placeBlock {
+		g.placeBlock()
+	}
 	g.camera.Follow(g.player)
 }
 
+// InterpolatedView is a camera view point at a fixed position, used to view
+// the scene from the player's interpolated eye position.
+type interpolatedView struct {
+	eye, sight mgl32.Vec3
+}
+
+// Sight implements the camera.ViewPoint interface.
+func (v interpolatedView) Sight() mgl32.Vec3 {
+	return v.sight
+}
+
+// EyePosition implements the camera.ViewPoint interface.
+func (v interpolatedView) EyePosition() mgl32.Vec3 {
+	return v.eye
+}
+
 // Render draws the game to the screen. It's called as fast as possible. Render
 // frames are dropped (slowing the visible FPS) if updating the game takes
 // longer than the alloted time.
-func (g *Game) Render() {
+//
+// `alpha` is how far we are between the previous update tick and the next one,
+// from 0 to 1. The player is viewed from partway between their positions
+// before and after the most recent tick, so movement looks smooth even when
+// rendering faster than the tick rate.
+func (g *Game) Render(alpha float32) {
+	// Nothing moves while the game is paused, so show everything as it was at
+	// the most recent tick, rather than interpolating towards a tick that
+	// isn't coming
+	if g.paused {
+		alpha = 1.0
+	}
+
+	// Widen the field of view while sprinting, easing between the two so the
+	// change isn't jarring
+	now := time.Now()
+	g.frameTimes.record(now.Sub(g.lastRender))
+	defer func() { g.renderTimes.record(time.Since(now)) }()
+	elapsed := float32(now.Sub(g.lastRender).Seconds())
+	g.lastRender = now
+	targetFov := g.fov()
+	if g.player.Sprinting {
+		targetFov += sprintFovIncrease
+	}
+	g.camera.EaseFov(targetFov, elapsed)
+	view := interpolatedView{g.player.InterpolatedEyePosition(alpha),
+		g.player.Sight()}
+	g.camera.Follow(view)
+
 	// Sky is rendered first, underneath everything else
-	g.sky.Render(sky.RenderInfo{
-		WorldTime:    0.0,
+	skyInfo := sky.RenderInfo{
+		WorldTime:    g.worldTime,
 		Camera:       g.camera,
+		CameraPos:    view.eye,
 		RenderRadius: g.world.RenderRadius,
 		LookDir:      g.player.Sight(),
-	})
+		Rain:         g.world.RainStrength(),
+	}
+	g.sky.Render(skyInfo)
 
 	// The world is rendered on top of the sky
 	g.world.Render(world.RenderInfo{
 		Camera:       g.camera,
-		PlayerChunkP: 0,
-		PlayerChunkQ: 0,
+		PlayerChunkP: g.playerChunkP,
+		PlayerChunkQ: g.playerChunkQ,
+		Time:         (float32(g.ticks) + alpha) / TicksPerSecond,
+		FogColor:     sky.FogColor(skyInfo),
 	})
+
+	// Crack the block the player is breaking, and outline the block they're
+	// looking at, if it's within reach
+	if g.breaking.active {
+		g.renderCracks()
+	}
+	if hit, _, ok := g.world.Raycast(view.eye, view.sight, reach); ok {
+		g.outline.Render(g.camera.View, hit)
+	}
+	if g.showChunkBorders {
+		g.renderChunkBorders()
+	}
+	if g.showCollisionBoxes {
+		g.renderCollisionBoxes(alpha)
+	}
+
+	// Particles are blended with the world behind them
+	g.particles.Render(g.camera.View, view.sight, alpha)
+
+	// Clouds are rendered after the world, so they're hidden behind nearby
+	// terrain
+	g.sky.RenderClouds(skyInfo)
+
+	// The HUD is drawn over the top of everything else
+	g.crosshair.Render()
+	if g.showDebug {
+		g.renderDebug()
+	}
+	if g.paused {
+		g.renderPause()
+	}
 }
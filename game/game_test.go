@@ -0,0 +1,112 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/entity"
+	"github.com/benanders/mineral/inventory"
+	"github.com/benanders/mineral/particle"
+	"github.com/benanders/mineral/world"
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestPickBlock(t *testing.T) {
+	w := world.NewHeadless(32, 1)
+	w.LoadChunkHeadless(0, 0)
+	glowstone, ok := w.FindBlock("Glowstone")
+	if !ok {
+		t.Fatal("no glowstone block")
+	}
+	w.SetBlock(0, 2, 0, glowstone)
+
+	// The player stands on the glowstone, looking straight down at it
+	center := mgl32.Vec3{0.5, 3.0 + entity.PlayerHeight/2.0, 0.5}
+	player := entity.NewPlayer(center, mgl32.Vec2{0.0, -math32.Pi / 2.0})
+	g := &Game{world: w, player: player,
+		hotbar: inventory.NewHotbar(inventory.HotbarSize)}
+	g.pickBlock()
+	if got := g.hotbar.Selected(); got != glowstone {
+		t.Errorf("got selected block %v after picking, want glowstone %v",
+			got, glowstone)
+	}
+
+	// Looking up at the sky doesn't pick anything
+	g.player = entity.NewPlayer(center, mgl32.Vec2{0.0, math32.Pi / 2.0})
+	g.hotbar = inventory.NewHotbar(inventory.HotbarSize)
+	g.pickBlock()
+	if got := g.hotbar.Selected(); got != world.Air {
+		t.Errorf("got selected block %v looking at the sky, want air", got)
+	}
+}
+
+func TestBreakBlockSpawnsDrop(t *testing.T) {
+	w := world.NewHeadless(32, 1)
+	w.LoadChunkHeadless(0, 0)
+	stone, ok := w.FindBlock("Stone")
+	if !ok {
+		t.Fatal("no stone block")
+	}
+	cobblestone, ok := w.FindBlock("Cobblestone")
+	if !ok {
+		t.Fatal("no cobblestone block")
+	}
+	w.SetBlock(2, 2, 3, stone)
+
+	center := mgl32.Vec3{0.5, 3.0 + entity.PlayerHeight/2.0, 0.5}
+	player := entity.NewPlayer(center, mgl32.Vec2{})
+	g := &Game{world: w, player: player, particles: particle.NewHeadless(),
+		entities: entity.NewManager(entity.DefaultDespawnRadius,
+			entity.NewPhysicsBatcher(entity.DefaultBatchThreshold,
+				entity.DefaultBatchSize))}
+
+	// Stone drops cobblestone, from the centre of where the stone was
+	g.breakBlock([3]int{2, 2, 3})
+	drops := g.entities.Drops()
+	if len(drops) != 1 {
+		t.Fatalf("got %d drops after breaking stone, want 1", len(drops))
+	}
+	if drops[0].Block != cobblestone {
+		t.Errorf("got drop %v after breaking stone, want cobblestone %v",
+			drops[0].Block, cobblestone)
+	}
+	want := mgl32.Vec3{2.5, 2.5, 3.5}
+	if got := drops[0].AABB.Center; got != want {
+		t.Errorf("drop centred at %v, want %v", got, want)
+	}
+
+	// Nothing's dropped in creative
+	player.SetGameMode(entity.Creative)
+	w.SetBlock(2, 2, 3, stone)
+	g.breakBlock([3]int{2, 2, 3})
+	if got := len(g.entities.Drops()); got != 1 {
+		t.Errorf("got %d drops after breaking stone in creative, want 1 "+
+			"from before", got)
+	}
+}
+
+func TestUpdatePlayerChunk(t *testing.T) {
+	w := world.NewHeadless(32, 1)
+	player := entity.NewPlayer(mgl32.Vec3{8.0, 10.0, 8.0}, mgl32.Vec2{})
+	g := &Game{world: w, player: player}
+
+	// Moving within the chunk doesn't reload the chunks around the player
+	player.AABB.Center = mgl32.Vec3{15.5, 10.0, 0.5}
+	if g.updatePlayerChunk() {
+		t.Error("chunks reloaded without leaving the chunk")
+	}
+
+	// Crossing into the next chunk does
+	player.AABB.Center = mgl32.Vec3{16.5, 10.0, 0.5}
+	if !g.updatePlayerChunk() {
+		t.Fatal("chunks not reloaded after crossing a chunk boundary")
+	}
+	if g.playerChunkP != 1 || g.playerChunkQ != 0 {
+		t.Errorf("player in chunk (%d, %d), want (1, 0)", g.playerChunkP,
+			g.playerChunkQ)
+	}
+	if w.PendingChunkCount() == 0 {
+		t.Error("no chunks queued around the new chunk")
+	}
+}
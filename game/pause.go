@@ -0,0 +1,61 @@
+package game
+
+import (
+	"github.com/benanders/mineral/hud"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// PauseOverlayColor is the color drawn over the top of the scene while the
+// game is paused, darkening it so it's clear the game isn't running.
+var pauseOverlayColor = mgl32.Vec4{0.0, 0.0, 0.0, 0.5}
+
+// PausedText is shown in the centre of the screen while the game is paused.
+const pausedText = "Paused - press Esc or click to resume"
+
+// SetRelativeMouseMode traps the mouse cursor in the window, or releases it.
+// Tests replace it, since there's no window to trap the cursor in.
+var setRelativeMouseMode = sdl.SetRelativeMouseMode
+
+// SetPaused pauses or resumes the game. While paused, the game state isn't
+// updated (although it's still rendered), and the mouse cursor is released
+// from the window so the player can use it elsewhere.
+func (g *Game) setPaused(paused bool) {
+	g.paused = paused
+	setRelativeMouseMode(!paused)
+}
+
+// TogglePause resumes the game if it's paused, or pauses it otherwise.
+func (g *Game) togglePause() {
+	g.setPaused(!g.paused)
+}
+
+// HandlePausedEvent processes a user input event while the game is paused.
+// Clicking anywhere in the window resumes the game, and the click is swallowed
+// so it doesn't also break or place a block.
+//
+// Keyboard events and mouse button releases are still passed on to the player
+// controller so that it knows what's held down when the game resumes (e.g. so
+// the player doesn't keep walking forward or breaking blocks after releasing
+// a key or button while paused), but mouse movement is ignored.
+func (g *Game) handlePausedEvent(evt sdl.Event) {
+	switch e := evt.(type) {
+	case *sdl.MouseButtonEvent:
+		if e.State == sdl.PRESSED {
+			g.setPaused(false)
+		} else {
+			g.playerController.HandleEvent(evt)
+		}
+	case *sdl.KeyboardEvent:
+		g.playerController.HandleEvent(evt)
+	}
+}
+
+// RenderPause darkens the scene and tells the player how to resume.
+func (g *Game) renderPause() {
+	g.overlay.Render(pauseOverlayColor)
+	x := (float32(g.screenWidth) - g.text.Width(pausedText)) / 2.0
+	y := (float32(g.screenHeight) - hud.LineHeight) / 2.0
+	g.text.DrawText(x, y, pausedText)
+}
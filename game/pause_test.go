@@ -0,0 +1,73 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/entity"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// MouseButton returns the event for a mouse button being pressed or released.
+func mouseButton(button uint8, pressed bool) *sdl.MouseButtonEvent {
+	evt := sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONUP, Button: button,
+		State: sdl.RELEASED}
+	if pressed {
+		evt.Type, evt.State = sdl.MOUSEBUTTONDOWN, sdl.PRESSED
+	}
+	return &evt
+}
+
+func TestReleasingBreakButtonWhilePaused(t *testing.T) {
+	controller := entity.NewInputController(entity.DefaultKeybindings())
+	g := &Game{playerController: controller}
+	controller.HandleEvent(mouseButton(sdl.BUTTON_LEFT, true))
+	if !controller.IsBreaking() {
+		t.Fatal("not breaking while the break button is held")
+	}
+
+	// The game is paused while the button is held, and it's released before
+	// the game resumes
+	g.paused = true
+	g.handlePausedEvent(mouseButton(sdl.BUTTON_LEFT, false))
+	if controller.IsBreaking() {
+		t.Error("still breaking after the break button was released")
+	}
+}
+
+func TestTogglePause(t *testing.T) {
+	var trapped []bool
+	set := setRelativeMouseMode
+	defer func() { setRelativeMouseMode = set }()
+	setRelativeMouseMode = func(enabled bool) int {
+		trapped = append(trapped, enabled)
+		return 0
+	}
+
+	// Pausing releases the mouse, and resuming traps it again
+	g := &Game{}
+	g.togglePause()
+	if !g.paused {
+		t.Fatal("game isn't paused after toggling pause")
+	}
+	g.togglePause()
+	if g.paused {
+		t.Fatal("game is still paused after toggling pause twice")
+	}
+	if len(trapped) != 2 || trapped[0] || !trapped[1] {
+		t.Errorf("got mouse trapped %v, want released then trapped", trapped)
+	}
+}
+
+func TestUpdateWhilePaused(t *testing.T) {
+	// The game has no world, player, or anything else to update, so this
+	// would panic if the update didn't return straight away
+	g := &Game{paused: true, ticksInDay: 1000}
+	g.Update()
+	if g.ticks != 0 || g.dayTicks != 0 {
+		t.Errorf("time moved on to tick %d while paused", g.ticks)
+	}
+	if g.updateTimes.count != 0 {
+		t.Error("recorded an update tick while paused")
+	}
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/benanders/mineral/entity"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The names of the folder that edited chunks are saved to, and of the file
+// that the player's state is saved to, within the save folder.
+const (
+	chunksDir  = "chunks"
+	playerFile = "player.toml"
+)
+
+// PlayerState is everything about the player that's saved between games.
+type playerState struct {
+	Position  [3]float32 // The centre of the player's AABB
+	Rotation  [2]float32 // The player's rotation along the x and y axes
+	Slot      int        // The selected hotbar slot
+	WorldTime float32    // Days since the world was created
+}
+
+// Save writes every chunk edited since it was loaded, and the player's state,
+// to the save folder. Nothing is saved if the game has no save folder.
+func (g *Game) Save() error {
+	if g.saveDir == "" {
+		return nil
+	}
+	if err := g.world.Save(); err != nil {
+		return err
+	}
+	state := playerState{
+		Position:  g.player.AABB.Center,
+		Rotation:  g.player.Rotation,
+		Slot:      g.hotbar.SelectedIndex(),
+		WorldTime: g.worldTime,
+	}
+	return savePlayerState(filepath.Join(g.saveDir, playerFile), state)
+}
+
+// LoadPlayer creates the player from the state saved in the save folder,
+// restoring the selected hotbar slot and the world time too. Returns false if
+// there's no saved state.
+func (g *Game) loadPlayer() (bool, error) {
+	if g.saveDir == "" {
+		return false, nil
+	}
+	state, ok, err := loadPlayerState(filepath.Join(g.saveDir, playerFile))
+	if !ok {
+		return false, err
+	}
+	g.player = entity.NewPlayer(state.Position, state.Rotation)
+	g.hotbar.Select(state.Slot)
+	g.SetWorldTime(state.WorldTime)
+	return true, nil
+}
+
+// SavePlayerState writes the player's state to the given TOML file, creating
+// the folder it's in if necessary.
+func savePlayerState(path string, state playerState) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(state); err != nil {
+		return fmt.Errorf("failed to encode player: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create save folder: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to save player `%v`: %v", path, err)
+	}
+	return nil
+}
+
+// LoadPlayerState reads the player's state from the given TOML file. Returns
+// false without an error if the file doesn't exist.
+func loadPlayerState(path string) (playerState, bool, error) {
+	var state playerState
+	source, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, false, nil
+	} else if err != nil {
+		return state, false, fmt.Errorf("failed to load player `%v`: %v",
+			path, err)
+	}
+	if _, err := toml.Decode(string(source), &state); err != nil {
+		return state, false, fmt.Errorf("failed to load player `%v`: %v",
+			path, err)
+	}
+	return state, true, nil
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/benanders/mineral/entity"
+	"github.com/benanders/mineral/inventory"
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// NewSaveTestGame creates a game with a headless world that's saved to `dir`.
+func newSaveTestGame(dir string) *Game {
+	w := world.NewHeadless(32, 1)
+	w.SetSaveDir(filepath.Join(dir, chunksDir))
+	w.LoadChunkHeadless(0, 0)
+	return &Game{world: w, saveDir: dir, ticksInDay: 1000,
+		hotbar: inventory.NewHotbar(inventory.HotbarSize)}
+}
+
+func TestSaveRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	g := newSaveTestGame(dir)
+	g.player = entity.NewPlayer(mgl32.Vec3{12.5, 20.9, -3.25},
+		mgl32.Vec2{1.25, -0.5})
+	g.hotbar.Select(4)
+	g.SetWorldTime(3.375)
+	stone, _ := g.world.FindBlock("Stone")
+	g.world.SetBlock(2, 10, 3, stone)
+	if err := g.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Loading the save puts the player back where they were
+	loaded := newSaveTestGame(dir)
+	ok, err := loaded.loadPlayer()
+	if !ok || err != nil {
+		t.Fatalf("failed to load player: %v", err)
+	}
+	if got := loaded.player.AABB.Center; got != g.player.AABB.Center {
+		t.Errorf("got player at %v, want %v", got, g.player.AABB.Center)
+	}
+	if got := loaded.player.Rotation; got != g.player.Rotation {
+		t.Errorf("got player rotation %v, want %v", got, g.player.Rotation)
+	}
+	if got := loaded.hotbar.SelectedIndex(); got != 4 {
+		t.Errorf("got hotbar slot %d selected, want 4", got)
+	}
+	if got := loaded.WorldTime(); got != 3.375 {
+		t.Errorf("got world time %v, want 3.375", got)
+	}
+
+	// The edited chunk was saved along with the player
+	if block, _ := loaded.world.GetBlock(2, 10, 3); block != stone {
+		t.Errorf("got block %v in the saved chunk, want stone %v", block,
+			stone)
+	}
+}
+
+func TestLoadWithoutSave(t *testing.T) {
+	g := newSaveTestGame(t.TempDir())
+	if ok, err := g.loadPlayer(); ok || err != nil {
+		t.Errorf("loaded player from an empty save folder: %v", err)
+	}
+}
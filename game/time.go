@@ -0,0 +1,85 @@
+package game
+
+import (
+	"time"
+
+	"github.com/chewxy/math32"
+)
+
+// The progress through the day (the fractional part of the world time) at
+// which it's noon and midnight. A day starts at sunrise.
+const (
+	noon     = 0.25
+	midnight = 0.75
+)
+
+// TicksInDay returns the number of update ticks in a day of the given length.
+// A day always lasts at least one tick, even if the length is negative.
+func ticksInDay(dayLength time.Duration) uint64 {
+	ticks := dayLength.Seconds() * TicksPerSecond
+	if ticks < 1.0 {
+		return 1
+	}
+	return uint64(ticks)
+}
+
+// AdvanceTime moves the world time forward by one update tick.
+func (g *Game) advanceTime() {
+	g.ticks++
+	g.dayTicks++
+	g.updateWorldTime()
+}
+
+// UpdateWorldTime recalculates the world time from the number of ticks into
+// the world's time.
+func (g *Game) updateWorldTime() {
+	days := g.dayTicks / g.ticksInDay
+	progress := float64(g.dayTicks%g.ticksInDay) / float64(g.ticksInDay)
+	g.worldTime = float32(float64(days) + progress)
+}
+
+// WorldTime returns the number of days since the world was created. The
+// fractional part is the progress through the current day, which starts at
+// sunrise.
+func (g *Game) WorldTime() float32 {
+	return g.worldTime
+}
+
+// SetWorldTime jumps straight to the given world time, in days since the world
+// was created. Negative times are treated as 0. The time is rounded to the
+// nearest update tick.
+func (g *Game) SetWorldTime(worldTime float32) {
+	if worldTime < 0.0 {
+		worldTime = 0.0
+	}
+	g.dayTicks = uint64(float64(worldTime)*float64(g.ticksInDay) + 0.5)
+	g.updateWorldTime()
+}
+
+// DayLengthTicks returns the number of update ticks in a full day/night cycle.
+func (g *Game) DayLengthTicks() int {
+	return int(g.ticksInDay)
+}
+
+// SetDayLength changes the number of update ticks in a full day/night cycle.
+// A day always lasts at least one tick. The world time is kept the same, so
+// the sky doesn't jump; only the speed at which time passes changes.
+func (g *Game) SetDayLength(ticks int) {
+	if ticks < 1 {
+		ticks = 1
+	}
+	days := float64(g.dayTicks) / float64(g.ticksInDay)
+	g.ticksInDay = uint64(ticks)
+	g.dayTicks = uint64(days*float64(g.ticksInDay) + 0.5)
+	g.updateWorldTime()
+}
+
+// SkipToTimeOfDay moves the world time forward to the next point at which the
+// progress through the day is `progress` (between 0 and 1).
+func (g *Game) skipToTimeOfDay(progress float32) {
+	day := math32.Floor(g.worldTime)
+	if g.worldTime-day >= progress {
+		day++ // Already past that time today, so skip to tomorrow
+	}
+	g.SetWorldTime(day + progress)
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicksInDay(t *testing.T) {
+	tests := []struct {
+		dayLength time.Duration
+		want      uint64
+	}{
+		{time.Second, TicksPerSecond},
+		{20 * time.Minute, 20 * 60 * TicksPerSecond},
+		{0, 1},
+		{time.Nanosecond, 1},
+		{-time.Second, 1},
+		{-20 * time.Minute, 1},
+	}
+	for _, test := range tests {
+		if got := ticksInDay(test.dayLength); got != test.want {
+			t.Errorf("got %d ticks in a day of %v, want %d", got,
+				test.dayLength, test.want)
+		}
+	}
+}
+
+func TestSetDayLengthKeepsWorldTime(t *testing.T) {
+	g := &Game{ticksInDay: 100}
+	g.SetWorldTime(2.5)
+
+	// Halfway through the third day stays halfway through it
+	g.SetDayLength(40)
+	if g.DayLengthTicks() != 40 {
+		t.Errorf("got %d ticks in a day, want 40", g.DayLengthTicks())
+	}
+	if g.WorldTime() != 2.5 {
+		t.Errorf("got world time %v, want 2.5", g.WorldTime())
+	}
+
+	// Time now passes faster
+	for i := 0; i < 10; i++ {
+		g.advanceTime()
+	}
+	if g.WorldTime() != 2.75 {
+		t.Errorf("got world time %v after 10 ticks, want 2.75", g.WorldTime())
+	}
+}
+
+func TestSetDayLengthIsAtLeastOneTick(t *testing.T) {
+	g := &Game{ticksInDay: 100}
+	for _, ticks := range []int{0, -5} {
+		g.SetDayLength(ticks)
+		if g.DayLengthTicks() != 1 {
+			t.Errorf("got %d ticks in a day of %d ticks, want 1",
+				g.DayLengthTicks(), ticks)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"log"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Swap intervals understood by `sdl.GLSetSwapInterval`.
+const (
+	// Show each frame as soon as it's rendered, without waiting for the
+	// display to refresh.
+	swapImmediate = 0
+
+	// Wait for the display to refresh before showing each frame (VSync).
+	swapVSync = 1
+
+	// Wait for the display to refresh, unless a frame is late, in which case
+	// show it straight away rather than waiting for the next refresh
+	// (adaptive VSync). Not every driver supports this.
+	swapAdaptive = -1
+)
+
+// ChooseSwapInterval applies the swap interval for the given VSync settings
+// using `set` (normally `sdl.GLSetSwapInterval`), falling back to regular
+// VSync if adaptive VSync isn't supported. Returns the swap interval that was
+// applied.
+func chooseSwapInterval(vsync, adaptive bool,
+	set func(interval int) error) (int, error) {
+	if !vsync {
+		return swapImmediate, set(swapImmediate)
+	}
+	if adaptive && set(swapAdaptive) == nil {
+		return swapAdaptive, nil
+	}
+	return swapVSync, set(swapVSync)
+}
+
+// SwapIntervalName returns a description of a swap interval for the debug
+// screen.
+func swapIntervalName(interval int) string {
+	switch interval {
+	case swapVSync:
+		return "on"
+	case swapAdaptive:
+		return "adaptive"
+	default:
+		return "off"
+	}
+}
+
+// SetVSync turns VSync on or off, using adaptive VSync if the settings ask for
+// it and the driver supports it. If the swap interval can't be changed, VSync
+// is left as it was.
+func (g *Game) setVSync(enabled bool) {
+	interval, err := chooseSwapInterval(enabled, g.settings.AdaptiveVSync,
+		sdl.GLSetSwapInterval)
+	if err != nil {
+		log.Println("failed to set VSync:", err)
+		return
+	}
+	g.settings.VSync = enabled
+	g.swapInterval = interval
+}
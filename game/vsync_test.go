@@ -0,0 +1,63 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSwapIntervals records the swap intervals it's asked to set, failing to
+// set any that aren't supported.
+type testSwapIntervals struct {
+	supported map[int]bool
+	set       []int
+}
+
+func (s *testSwapIntervals) setInterval(interval int) error {
+	s.set = append(s.set, interval)
+	if !s.supported[interval] {
+		return errors.New("unsupported swap interval")
+	}
+	return nil
+}
+
+func TestChooseSwapInterval(t *testing.T) {
+	all := map[int]bool{swapImmediate: true, swapVSync: true,
+		swapAdaptive: true}
+	tests := []struct {
+		vsync, adaptive bool
+		want            int
+	}{
+		{false, false, swapImmediate},
+		{false, true, swapImmediate},
+		{true, false, swapVSync},
+		{true, true, swapAdaptive},
+	}
+	for _, test := range tests {
+		s := testSwapIntervals{supported: all}
+		got, err := chooseSwapInterval(test.vsync, test.adaptive,
+			s.setInterval)
+		if err != nil || got != test.want {
+			t.Errorf("got swap interval %d (%v) for vsync %v and adaptive "+
+				"%v, want %d", got, err, test.vsync, test.adaptive, test.want)
+		}
+	}
+}
+
+func TestChooseSwapIntervalWithoutAdaptive(t *testing.T) {
+	s := testSwapIntervals{supported: map[int]bool{swapImmediate: true,
+		swapVSync: true}}
+	got, err := chooseSwapInterval(true, true, s.setInterval)
+	if err != nil || got != swapVSync {
+		t.Errorf("got swap interval %d (%v), want regular VSync", got, err)
+	}
+	if len(s.set) != 2 || s.set[1] != swapVSync {
+		t.Errorf("set swap intervals %v, want adaptive then VSync", s.set)
+	}
+}
+
+func TestChooseSwapIntervalFails(t *testing.T) {
+	s := testSwapIntervals{}
+	if _, err := chooseSwapInterval(true, false, s.setInterval); err == nil {
+		t.Error("no error when the swap interval can't be set")
+	}
+}
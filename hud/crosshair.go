@@ -0,0 +1,129 @@
+package hud
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/benanders/mineral/render"
+)
+
+const (
+	// The length of each bar of the crosshair, in screen pixels.
+	crosshairLength = 18
+
+	// The thickness of each bar of the crosshair, in screen pixels.
+	crosshairThickness = 2
+
+	// The number of vertices in the crosshair; two triangles for each of the
+	// three rectangles it's made of.
+	numCrosshairVertices = 3 * 6
+)
+
+// Crosshair draws a small cross in the centre of the window, showing the
+// player what they're aiming at. The crosshair inverts the colors of whatever
+// is behind it, so that it's visible against any background.
+type Crosshair struct {
+	vao, vbo uint32
+	program  *render.ReloadableProgram
+
+	projection mgl32.Mat4
+}
+
+// NewCrosshair allocates the required OpenGL resources for the crosshair. Its
+// vertex data isn't created until the screen size is set. Returns an error if
+// the crosshair's shaders fail to load.
+func NewCrosshair() (*Crosshair, error) {
+	// Create the program
+	program, err := render.LoadReloadableShaders(
+		"shaders/crosshairVert.glsl",
+		"shaders/crosshairFrag.glsl")
+	if err != nil {
+		return nil, err
+	}
+	program.Use()
+
+	// Create the VAO and VBO
+	var vao, vbo uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+
+	// Enable the position attribute
+	posAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("position\x00")))
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 2, gl.FLOAT, false, 0, gl.PtrOffset(0))
+
+	return &Crosshair{vao: vao, vbo: vbo, program: program}, nil
+}
+
+// Destroy releases all the resources allocated by the crosshair.
+func (c *Crosshair) Destroy() {
+	c.program.Destroy()
+	gl.DeleteVertexArrays(1, &c.vao)
+	gl.DeleteBuffers(1, &c.vbo)
+}
+
+// SetScreenSize rebuilds the crosshair's vertex data so that it sits in the
+// centre of the screen, given the size of the window's drawable area in
+// pixels. This should be called whenever the window is resized.
+func (c *Crosshair) SetScreenSize(width, height int32) {
+	c.projection = mgl32.Ortho2D(0.0, float32(width), float32(height), 0.0)
+	vertices := genCrosshairVertices(width, height)
+	gl.BindBuffer(gl.ARRAY_BUFFER, c.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(&vertices[0]),
+		gl.STATIC_DRAW)
+}
+
+// GenCrosshairVertices builds the vertex data for the crosshair, in screen
+// pixels, for a screen of the given size.
+//
+// The crosshair is made from a horizontal bar, and a vertical bar split in two
+// either side of it. The bars mustn't overlap, since the inverted blending
+// would cancel itself out where they do.
+func genCrosshairVertices(width, height int32) []float32 {
+	// Keep the crosshair aligned to whole pixels, so it stays crisp
+	cx, cy := float32(width/2), float32(height/2)
+	length := float32(crosshairLength)
+	thickness := float32(crosshairThickness)
+	arm := (length - thickness) / 2.0
+
+	vertices := make([]float32, 0, numCrosshairVertices*2)
+	vertices = appendRect(vertices, cx-length/2.0, cy-thickness/2.0, length,
+		thickness)
+	vertices = appendRect(vertices, cx-thickness/2.0, cy-length/2.0,
+		thickness, arm)
+	vertices = appendRect(vertices, cx-thickness/2.0, cy+thickness/2.0,
+		thickness, arm)
+	return vertices
+}
+
+// AppendRect appends the vertex data for a rectangle with its top left corner
+// at (x, y) to `vertices`, as two triangles.
+func appendRect(vertices []float32, x, y, w, h float32) []float32 {
+	return append(vertices,
+		x, y,
+		x, y+h,
+		x+w, y+h,
+		x, y,
+		x+w, y+h,
+		x+w, y,
+	)
+}
+
+// Render draws the crosshair over the top of the scene.
+func (c *Crosshair) Render() {
+	c.program.Use()
+	gl.UniformMatrix4fv(c.program.Uniform("mvp"), 1, false,
+		&c.projection[0])
+
+	// Invert the colors behind the crosshair
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.ONE_MINUS_DST_COLOR, gl.ZERO)
+
+	gl.BindVertexArray(c.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, numCrosshairVertices)
+
+	// Reset the OpenGL state
+	gl.Disable(gl.BLEND)
+}
@@ -0,0 +1,64 @@
+package hud
+
+import (
+	"testing"
+
+	"github.com/chewxy/math32"
+)
+
+func TestCrosshairIsCentred(t *testing.T) {
+	sizes := [][2]int32{{800, 600}, {851, 501}, {1920, 1080}}
+	for _, size := range sizes {
+		vertices := genCrosshairVertices(size[0], size[1])
+		if len(vertices) != numCrosshairVertices*2 {
+			t.Fatalf("got %d vertices, want %d", len(vertices)/2,
+				numCrosshairVertices)
+		}
+
+		// Find the crosshair's bounding box, and check every vertex lies on
+		// a whole pixel
+		minX, minY := vertices[0], vertices[1]
+		maxX, maxY := minX, minY
+		for i := 0; i < len(vertices); i += 2 {
+			x, y := vertices[i], vertices[i+1]
+			if x != float32(int(x)) || y != float32(int(y)) {
+				t.Errorf("vertex (%v, %v) isn't on a whole pixel", x, y)
+			}
+			minX, maxX = math32.Min(minX, x), math32.Max(maxX, x)
+			minY, maxY = math32.Min(minY, y), math32.Max(maxY, y)
+		}
+
+		cx, cy := float32(size[0]/2), float32(size[1]/2)
+		if (minX+maxX)/2.0 != cx || (minY+maxY)/2.0 != cy {
+			t.Errorf("crosshair centred at (%v, %v) on a %dx%d screen, "+
+				"want (%v, %v)", (minX+maxX)/2.0, (minY+maxY)/2.0, size[0],
+				size[1], cx, cy)
+		}
+		if maxX-minX != crosshairLength || maxY-minY != crosshairLength {
+			t.Errorf("crosshair is %vx%v, want %dx%d", maxX-minX, maxY-minY,
+				crosshairLength, crosshairLength)
+		}
+	}
+}
+
+func TestCrosshairBarsDontOverlap(t *testing.T) {
+	// The bars only overlap if their total area is more than the area of the
+	// cross they cover
+	vertices := genCrosshairVertices(800, 600)
+	area := float32(0.0)
+	for i := 0; i < len(vertices); i += 6 {
+		// Each triangle's area, from the cross product of two of its edges
+		ax, ay := vertices[i+2]-vertices[i], vertices[i+3]-vertices[i+1]
+		bx, by := vertices[i+4]-vertices[i], vertices[i+5]-vertices[i+1]
+		cross := ax*by - ay*bx
+		if cross < 0.0 {
+			cross = -cross
+		}
+		area += cross / 2.0
+	}
+	want := float32(2*crosshairLength*crosshairThickness -
+		crosshairThickness*crosshairThickness)
+	if area != want {
+		t.Errorf("bars cover %v pixels, want %v", area, want)
+	}
+}
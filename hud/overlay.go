@@ -0,0 +1,80 @@
+package hud
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/benanders/mineral/render"
+)
+
+// The corners of two triangles covering the whole screen, in normalised device
+// coordinates.
+var overlayVertices = []float32{
+	-1.0, -1.0,
+	1.0, -1.0,
+	1.0, 1.0,
+	-1.0, -1.0,
+	1.0, 1.0,
+	-1.0, 1.0,
+}
+
+// Overlay tints the whole screen with a translucent color, e.g. to darken the
+// scene behind a menu. Since it covers the whole screen in normalised device
+// coordinates, it doesn't need to know the size of the window.
+type Overlay struct {
+	vao, vbo uint32
+	program  *render.ReloadableProgram
+}
+
+// NewOverlay allocates the required OpenGL resources for the overlay. Returns
+// an error if the overlay's shaders fail to load.
+func NewOverlay() (*Overlay, error) {
+	// Create the program
+	program, err := render.LoadReloadableShaders(
+		"shaders/overlayVert.glsl",
+		"shaders/overlayFrag.glsl")
+	if err != nil {
+		return nil, err
+	}
+	program.Use()
+
+	// Create the VAO and VBO
+	var vao, vbo uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(overlayVertices)*4,
+		gl.Ptr(&overlayVertices[0]), gl.STATIC_DRAW)
+
+	// Enable the position attribute
+	posAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("position\x00")))
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 2, gl.FLOAT, false, 0, gl.PtrOffset(0))
+
+	return &Overlay{vao: vao, vbo: vbo, program: program}, nil
+}
+
+// Destroy releases all the resources allocated by the overlay.
+func (o *Overlay) Destroy() {
+	o.program.Destroy()
+	gl.DeleteVertexArrays(1, &o.vao)
+	gl.DeleteBuffers(1, &o.vbo)
+}
+
+// Render covers the whole screen with the given color, blended with the scene
+// behind it according to the color's alpha.
+func (o *Overlay) Render(color mgl32.Vec4) {
+	o.program.Use()
+	gl.Uniform4f(o.program.Uniform("color"), color[0], color[1], color[2],
+		color[3])
+
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	gl.BindVertexArray(o.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(overlayVertices)/2))
+
+	// Reset the OpenGL state
+	gl.Disable(gl.BLEND)
+}
@@ -0,0 +1,248 @@
+package hud
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/png" // The font atlas is provided as a .png image
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/benanders/mineral/asset"
+	"github.com/benanders/mineral/render"
+)
+
+const (
+	// The OpenGL texture slot into which the font atlas is loaded.
+	fontTextureSlot = 4
+
+	// The font atlas is a 16x16 grid of glyphs, one for each ASCII character
+	// (plus some extended characters), each 8x8 pixels in size.
+	glyphsPerRow = 16
+	glyphSize    = 8
+
+	// The horizontal space left after each glyph, in font pixels.
+	glyphSpacing = 1
+
+	// The width of a space character, in font pixels. Spaces have no pixels in
+	// the font atlas, so we can't work out their width from it.
+	spaceWidth = 4
+
+	// TextScale is the number of screen pixels covered by each font pixel.
+	TextScale = 2.0
+
+	// LineHeight is the vertical distance between consecutive lines of text, in
+	// screen pixels.
+	LineHeight = (glyphSize + 2) * TextScale
+
+	// The offset of the drop shadow drawn underneath text, in screen pixels.
+	shadowOffset = TextScale
+
+	// The number of values per vertex in the text's vertex data; 2 for the
+	// position, and 2 for the texture coordinates.
+	valuesPerTextVertex = 4
+)
+
+// Text draws strings to the screen using Minecraft's bitmap font. Text is
+// positioned in screen pixels, with the origin in the top left corner of the
+// window.
+type Text struct {
+	vao, vbo   uint32
+	program    *render.ReloadableProgram
+	texture    uint32
+	projection mgl32.Mat4
+
+	// The width of each glyph in the font atlas, in font pixels, including
+	// the spacing after it
+	advances [glyphsPerRow * glyphsPerRow]int
+}
+
+// NewText loads the font atlas and allocates the required OpenGL resources for
+// drawing text. Returns an error if the font atlas or the text shaders fail to
+// load.
+func NewText() (*Text, error) {
+	// Load the font atlas first, so nothing needs cleaning up if it's missing
+	font, err := loadFontAtlas("textures/font/ascii.png")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the program
+	program, err := render.LoadReloadableShaders(
+		"shaders/textVert.glsl",
+		"shaders/textFrag.glsl")
+	if err != nil {
+		return nil, err
+	}
+	program.Use()
+
+	// Create the VAO
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+
+	// Create the VBO, which is filled with new vertex data each time a string
+	// is drawn
+	var vbo uint32
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+
+	// Position attribute
+	posAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("position\x00")))
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 2, gl.FLOAT, false,
+		valuesPerTextVertex*4, gl.PtrOffset(0))
+
+	// Texture coordinate attribute
+	uvAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("uv\x00")))
+	gl.EnableVertexAttribArray(uvAttr)
+	gl.VertexAttribPointer(uvAttr, 2, gl.FLOAT, false,
+		valuesPerTextVertex*4, gl.PtrOffset(2*4))
+
+	// Upload the font atlas, and work out the width of each glyph from it
+	texture := render.LoadTexture(font, fontTextureSlot)
+
+	t := &Text{vao: vao, vbo: vbo, program: program, texture: texture}
+	t.advances = getGlyphAdvances(font)
+	return t, nil
+}
+
+// LoadFontAtlas reads the font atlas from the asset with the given path.
+func loadFontAtlas(path string) (*image.RGBA, error) {
+	// Get the .png file
+	pngData, err := asset.Asset(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image `%v`", path)
+	}
+
+	// Decode the .png file
+	img, _, err := image.Decode(bytes.NewReader(pngData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode png image `%v`", path)
+	}
+
+	// Convert the image to RGBA, which is the format OpenGL expects
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+// GetGlyphAdvances works out how far to move along after drawing each glyph
+// in the font atlas, in font pixels. The font is proportional, so each glyph
+// is only as wide as its rightmost non-transparent column of pixels.
+func getGlyphAdvances(font *image.RGBA) [glyphsPerRow * glyphsPerRow]int {
+	var advances [glyphsPerRow * glyphsPerRow]int
+	for glyph := range advances {
+		// Find the rightmost column containing any visible pixels
+		x0 := (glyph % glyphsPerRow) * glyphSize
+		y0 := (glyph / glyphsPerRow) * glyphSize
+		width := 0
+		for x := glyphSize - 1; x >= 0 && width == 0; x-- {
+			for y := 0; y < glyphSize; y++ {
+				if font.RGBAAt(x0+x, y0+y).A > 0 {
+					width = x + 1
+					break
+				}
+			}
+		}
+		advances[glyph] = width + glyphSpacing
+	}
+	advances[' '] = spaceWidth
+	return advances
+}
+
+// Destroy releases all the resources allocated by the text renderer.
+func (t *Text) Destroy() {
+	t.program.Destroy()
+	gl.DeleteVertexArrays(1, &t.vao)
+	gl.DeleteBuffers(1, &t.vbo)
+	gl.DeleteTextures(1, &t.texture)
+}
+
+// SetScreenSize updates the orthographic projection used to position text,
+// given the size of the window's drawable area in pixels. This should be
+// called whenever the window is resized.
+func (t *Text) SetScreenSize(width, height int32) {
+	t.projection = mgl32.Ortho2D(0.0, float32(width), float32(height), 0.0)
+}
+
+// Width returns the width of a string when drawn, in screen pixels.
+func (t *Text) Width(s string) float32 {
+	width := 0
+	for _, glyph := range []byte(s) {
+		width += t.advances[glyph]
+	}
+	return float32(width) * TextScale
+}
+
+// DrawText draws a string in white with a drop shadow. (x, y) is the top left
+// corner of the text, in screen pixels from the top left corner of the window.
+// Only single byte characters are supported.
+func (t *Text) DrawText(x, y float32, s string) {
+	vertices := t.genTextVertices(x, y, s)
+	if len(vertices) == 0 {
+		return
+	}
+
+	// Upload the vertex data
+	gl.BindVertexArray(t.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, t.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(&vertices[0]),
+		gl.STREAM_DRAW)
+
+	// Set uniforms
+	t.program.Use()
+	gl.UniformMatrix4fv(t.program.Uniform("mvp"), 1, false, &t.projection[0])
+	gl.Uniform1i(t.program.Uniform("font"), fontTextureSlot)
+
+	// Text is drawn over the top of everything else, with linear alpha
+	// blending
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	// Draw the shadow first, then the text on top of it
+	numVertices := int32(len(vertices) / valuesPerTextVertex)
+	gl.Uniform2f(t.program.Uniform("offset"), shadowOffset, shadowOffset)
+	gl.Uniform3f(t.program.Uniform("textColor"), 0.25, 0.25, 0.25)
+	gl.DrawArrays(gl.TRIANGLES, 0, numVertices)
+	gl.Uniform2f(t.program.Uniform("offset"), 0.0, 0.0)
+	gl.Uniform3f(t.program.Uniform("textColor"), 1.0, 1.0, 1.0)
+	gl.DrawArrays(gl.TRIANGLES, 0, numVertices)
+
+	// Reset the OpenGL state
+	gl.Disable(gl.BLEND)
+}
+
+// GenTextVertices builds the vertex data for a string, with two triangles for
+// each visible glyph.
+func (t *Text) genTextVertices(x, y float32, s string) []float32 {
+	vertices := make([]float32, 0, len(s)*6*valuesPerTextVertex)
+	size := float32(glyphSize) * TextScale
+	for _, glyph := range []byte(s) {
+		// Skip characters with no pixels
+		advance := float32(t.advances[glyph]) * TextScale
+		if glyph == ' ' {
+			x += advance
+			continue
+		}
+
+		// Texture coordinates of the glyph in the font atlas
+		u1 := float32(glyph%glyphsPerRow) / glyphsPerRow
+		v1 := float32(glyph/glyphsPerRow) / glyphsPerRow
+		u2 := u1 + 1.0/glyphsPerRow
+		v2 := v1 + 1.0/glyphsPerRow
+
+		vertices = append(vertices,
+			x, y, u1, v1,
+			x, y+size, u1, v2,
+			x+size, y+size, u2, v2,
+			x, y, u1, v1,
+			x+size, y+size, u2, v2,
+			x+size, y, u2, v1,
+		)
+		x += advance
+	}
+	return vertices
+}
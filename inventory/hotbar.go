@@ -0,0 +1,115 @@
+package inventory
+
+import (
+	"github.com/benanders/mineral/world"
+)
+
+const (
+	// HotbarSize is the number of slots in the player's hotbar.
+	HotbarSize = 9
+
+	// MaxStackSize is the maximum number of blocks that can be held in a
+	// single slot.
+	MaxStackSize = 64
+)
+
+// Slot holds a stack of blocks of the same type. An empty slot holds no air
+// blocks.
+type Slot struct {
+	Block world.Block
+	Count int
+}
+
+// Empty returns true if the slot doesn't hold anything.
+func (s Slot) Empty() bool {
+	return s.Block == world.Air || s.Count <= 0
+}
+
+// Hotbar is the row of slots the player can quickly switch between, one of
+// which is selected at any time. The selected slot holds the block the player
+// builds with.
+type Hotbar struct {
+	Slots    []Slot
+	selected int // Index of the currently selected slot
+}
+
+// NewHotbar creates a hotbar with the given number of empty slots, with the
+// first slot selected.
+func NewHotbar(size int) *Hotbar {
+	return &Hotbar{Slots: make([]Slot, size)}
+}
+
+// Select selects the slot at the given index. Indices outside the hotbar are
+// ignored, leaving the selection unchanged.
+func (h *Hotbar) Select(i int) {
+	if i >= 0 && i < len(h.Slots) {
+		h.selected = i
+	}
+}
+
+// Scroll moves the selection along the hotbar by `delta` slots, wrapping
+// around from one end to the other.
+func (h *Hotbar) Scroll(delta int) {
+	if len(h.Slots) == 0 {
+		return
+	}
+
+	// Go's modulus operator returns negative numbers for negative operands, so
+	// add on the number of slots if necessary
+	h.selected = (h.selected + delta) % len(h.Slots)
+	if h.selected < 0 {
+		h.selected += len(h.Slots)
+	}
+}
+
+// SelectedIndex returns the index of the currently selected slot.
+func (h *Hotbar) SelectedIndex() int {
+	return h.selected
+}
+
+// Selected returns the type of block in the currently selected slot, or air if
+// the slot is empty.
+func (h *Hotbar) Selected() world.Block {
+	if len(h.Slots) == 0 || h.Slots[h.selected].Empty() {
+		return world.Air
+	}
+	return h.Slots[h.selected].Block
+}
+
+// Pick selects the slot holding the given type of block, if there is one.
+// Otherwise the selected slot is replaced with a full stack of the block.
+func (h *Hotbar) Pick(block world.Block) {
+	if len(h.Slots) == 0 || block == world.Air {
+		return
+	}
+	for i, slot := range h.Slots {
+		if !slot.Empty() && slot.Block == block {
+			h.selected = i
+			return
+		}
+	}
+	h.Slots[h.selected] = Slot{block, MaxStackSize}
+}
+
+// Add puts a single block into the hotbar, stacking it onto the first slot
+// holding the same type of block that isn't full, or otherwise into the first
+// empty slot. Returns false if there's no room for the block.
+func (h *Hotbar) Add(block world.Block) bool {
+	block = block.Type()
+	if block == world.Air {
+		return false
+	}
+	for i, slot := range h.Slots {
+		if !slot.Empty() && slot.Block == block && slot.Count < MaxStackSize {
+			h.Slots[i].Count++
+			return true
+		}
+	}
+	for i, slot := range h.Slots {
+		if slot.Empty() {
+			h.Slots[i] = Slot{block, 1}
+			return true
+		}
+	}
+	return false
+}
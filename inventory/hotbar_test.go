@@ -0,0 +1,114 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/world"
+)
+
+func TestHotbarScrollWraps(t *testing.T) {
+	h := NewHotbar(HotbarSize)
+	tests := []struct {
+		delta, want int
+	}{
+		{1, 1},
+		{-2, HotbarSize - 1},
+		{1, 0},
+		{HotbarSize * 2, 0},
+		{-HotbarSize*3 - 1, HotbarSize - 1},
+	}
+	for _, test := range tests {
+		h.Scroll(test.delta)
+		if got := h.SelectedIndex(); got != test.want {
+			t.Errorf("scrolled by %d to slot %d, want %d", test.delta, got,
+				test.want)
+		}
+	}
+}
+
+func TestHotbarSelectIgnoresInvalidSlots(t *testing.T) {
+	h := NewHotbar(HotbarSize)
+	h.Select(4)
+	h.Select(-1)
+	h.Select(HotbarSize)
+	if got := h.SelectedIndex(); got != 4 {
+		t.Errorf("selected slot %d, want 4", got)
+	}
+	h.Select(HotbarSize - 1)
+	if got := h.SelectedIndex(); got != HotbarSize-1 {
+		t.Errorf("selected slot %d, want %d", got, HotbarSize-1)
+	}
+}
+
+func TestEmptyHotbar(t *testing.T) {
+	h := NewHotbar(0)
+	h.Scroll(1)
+	h.Select(0)
+	if got := h.SelectedIndex(); got != 0 {
+		t.Errorf("selected slot %d in an empty hotbar", got)
+	}
+}
+
+func TestHotbarAddStacks(t *testing.T) {
+	const stone, dirt = world.Block(1), world.Block(3)
+	h := NewHotbar(3)
+	h.Slots[1] = Slot{dirt, 5}
+
+	// Blocks stack onto a slot holding the same type, ignoring their state
+	if !h.Add(dirt.WithState(2)) {
+		t.Fatal("no room for a block")
+	}
+	if h.Slots[1] != (Slot{dirt, 6}) {
+		t.Errorf("got slot %v, want 6 dirt", h.Slots[1])
+	}
+
+	// Other blocks go in the first empty slot
+	if !h.Add(stone) || h.Slots[0] != (Slot{stone, 1}) {
+		t.Errorf("got slot %v, want 1 stone", h.Slots[0])
+	}
+	if h.Add(world.Air) {
+		t.Error("added air to the hotbar")
+	}
+}
+
+func TestHotbarAddWhenFull(t *testing.T) {
+	const stone, dirt = world.Block(1), world.Block(3)
+	h := NewHotbar(2)
+	h.Slots[0] = Slot{stone, MaxStackSize}
+
+	// A full stack overflows into an empty slot
+	if !h.Add(stone) || h.Slots[1] != (Slot{stone, 1}) {
+		t.Errorf("got slot %v, want 1 stone", h.Slots[1])
+	}
+	for h.Slots[1].Count < MaxStackSize {
+		if !h.Add(stone) {
+			t.Fatalf("no room with %d stone in the last slot",
+				h.Slots[1].Count)
+		}
+	}
+	if h.Add(stone) || h.Add(dirt) {
+		t.Error("added a block to a full hotbar")
+	}
+}
+
+func TestHotbarPick(t *testing.T) {
+	const stone, dirt = world.Block(1), world.Block(3)
+	h := NewHotbar(3)
+	h.Slots[2] = Slot{dirt, 5}
+
+	// Picking a block that's already in the hotbar selects its slot
+	h.Pick(dirt)
+	if got := h.SelectedIndex(); got != 2 {
+		t.Errorf("selected slot %d, want 2", got)
+	}
+
+	// Otherwise the selected slot is replaced
+	h.Pick(stone)
+	if h.Slots[2] != (Slot{stone, MaxStackSize}) {
+		t.Errorf("got slot %v, want a full stack of stone", h.Slots[2])
+	}
+	h.Pick(world.Air)
+	if got := h.Selected(); got != stone {
+		t.Errorf("picking air changed the selected block to %v", got)
+	}
+}
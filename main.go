@@ -3,18 +3,76 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime"
 	"time"
 
+	"github.com/benanders/mineral/audio"
+	"github.com/benanders/mineral/config"
 	"github.com/benanders/mineral/game"
+	"github.com/benanders/mineral/profile"
+	"github.com/benanders/mineral/world"
 
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
 // The minimum number of nanoseconds that must elapse between update ticks.
-const nsPerTick = 1000 * 1000 * 1000 / 60
+const nsPerTick = 1000 * 1000 * 1000 / game.TicksPerSecond
+
+// The window size used if none is given on the command line.
+const (
+	defaultWidth  = 850
+	defaultHeight = 500
+)
+
+// MeshDigest prints a summary of the vertex data for the chunks around the
+// centre of the world and exits, without opening a window. Used to check that
+// changes to world generation or meshing don't change the output unexpectedly
+// (see `TestMeshDigest`).
+var meshDigest = flag.Bool("mesh-digest", false,
+	"print a digest of the spawn area's vertex data and exit")
+
+// DayLength is the length of a full day/night cycle in real time.
+var dayLength = flag.Duration("day-length", game.DefaultDayLength,
+	"real time taken for a full day/night cycle")
+
+// Seed is the seed the world is created with. A seed of 0 picks a random seed,
+// which is printed so the same world can be created again. The terrain is
+// always the same for now; the seed only controls things like random block
+// ticks and the weather.
+var seed = flag.Int64("seed", 0,
+	"seed for random events like grass spreading and the weather; the "+
+		"terrain doesn't depend on it yet (0 picks a random seed)")
+
+// RenderRadius is the number of chunks around the player that are rendered.
+// Like fullscreen, it overrides the settings file only if it's given.
+var renderRadius = flag.Int("render-radius", config.DefaultRenderRadius,
+	"number of chunks around the player to render")
+
+// Width, height, and fullscreen control the window the game is played in.
+var width = flag.Int("width", defaultWidth, "initial window width")
+var height = flag.Int("height", defaultHeight, "initial window height")
+var fullscreen = flag.Bool("fullscreen", false, "start in fullscreen")
+
+// PprofAddr is the address to serve the `net/http/pprof` profiling endpoints
+// on, while the game runs.
+var pprofAddr = flag.String("pprof", "",
+	"serve pprof endpoints on the given address (e.g. localhost:6060)")
+
+// CPUProfile is the file to write a CPU profile to, and CPUProfileDuration
+// how long to record it for after starting the game.
+var cpuProfile = flag.String("cpu-profile", "",
+	"write a CPU profile to the given file")
+var cpuProfileDuration = flag.Duration("cpu-profile-duration", 0,
+	"stop the CPU profile after this long (0 records until the game exits)")
 
 func init() {
 	// The OpenGL context MUST be created on the main OS thread. To ensure this,
@@ -22,17 +80,143 @@ func init() {
 	runtime.LockOSThread()
 }
 
+// ClampFlag returns the value of an integer flag limited to the range [min,
+// max], warning the user if it's out of range.
+func clampFlag(name string, value, min, max int) int {
+	clamped := value
+	if clamped < min {
+		clamped = min
+	} else if clamped > max {
+		clamped = max
+	}
+	if clamped != value {
+		log.Printf("warning: -%v must be between %v and %v; using %v\n", name,
+			min, max, clamped)
+	}
+	return clamped
+}
+
+// LoadSettings reads the player's settings from their settings file, then
+// overrides them with any settings given on the command line. The default
+// settings are used if the file can't be loaded.
+//
+// Returns the settings from the file, and the settings to play with. Only
+// settings changed while playing are saved back to the file, so the command
+// line only overrides the file for this game.
+func loadSettings() (saved, settings config.Settings) {
+	saved, err := config.Load()
+	if err != nil {
+		log.Println("failed to load settings:", err)
+	}
+	settings = saved
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "render-radius":
+			settings.RenderRadius = *renderRadius
+		case "fullscreen":
+			settings.Fullscreen = *fullscreen
+		}
+	})
+	settings.RenderRadius = clampFlag("render-radius", settings.RenderRadius,
+		1, world.MaxRenderRadius)
+	return saved, settings
+}
+
+// SaveDir returns the folder the world is saved in, next to the settings file.
+// The world isn't saved if the folder can't be found.
+func saveDir() string {
+	dir, err := config.Dir()
+	if err != nil {
+		log.Println("failed to find save folder:", err)
+		return ""
+	}
+	return filepath.Join(dir, "world")
+}
+
+// ProfileConfig returns the profilers requested on the command line.
+func profileConfig() profile.Config {
+	return profile.Config{
+		HTTPAddr:    *pprofAddr,
+		CPUFile:     *cpuProfile,
+		CPUDuration: *cpuProfileDuration,
+	}
+}
+
+// FrameSleep returns how long to sleep after rendering a frame that took
+// `frameTime`, so that no more than `maxFPS` frames are rendered per second.
+// There's no need to sleep if there's no limit, or if the frame has already
+// used up its share of the second.
+func frameSleep(maxFPS int, frameTime time.Duration) time.Duration {
+	if maxFPS <= 0 {
+		return 0
+	}
+	budget := time.Second / time.Duration(maxFPS)
+	if frameTime >= budget {
+		return 0
+	}
+	return budget - frameTime
+}
+
+// RandomSeed picks a random world seed using `rng`. The seed is never 0, since
+// that asks for a random seed.
+func randomSeed(rng *rand.Rand) int64 {
+	seed := int64(0)
+	for seed == 0 {
+		seed = rng.Int63()
+	}
+	return seed
+}
+
 func main() {
+	flag.Parse()
+	if *meshDigest {
+		fmt.Print(world.MeshDigest(world.MeshDigestRadius, world.DefaultHeight))
+		return
+	}
+
+	// Start any profilers requested on the command line. Profiling is off by
+	// default
+	profiling := profileConfig()
+	if profiling.Enabled() {
+		stop, err := profile.Start(profiling)
+		if err != nil {
+			log.Fatalln("failed to start profiling:", err)
+		}
+		defer stop()
+	}
+
+	// Make sure the settings given on the command line are sensible
+	saved, settings := loadSettings()
+	*width = clampFlag("width", *width, 1, math.MaxInt32)
+	*height = clampFlag("height", *height, 1, math.MaxInt32)
+	if *seed == 0 {
+		*seed = randomSeed(rand.New(rand.NewSource(time.Now().UnixNano())))
+		log.Println("world seed:", *seed)
+	}
+
 	// Initialise SDL
 	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		log.Fatalln("failed to initialise SDL:", err)
 	}
 	defer sdl.Quit()
 
-	// Create a new window with a fixed title and initial size
-	window, err := sdl.CreateWindow("Mineral",
-		sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, 850, 500,
-		sdl.WINDOW_ALLOW_HIGHDPI|sdl.WINDOW_OPENGL|sdl.WINDOW_RESIZABLE)
+	// Open the audio device. The game can be played without sound, so don't
+	// stop if it fails
+	if err := audio.Init(); err != nil {
+		log.Println("failed to initialise audio:", err)
+	} else {
+		defer audio.Quit()
+	}
+
+	// Create a new window with a fixed title and initial size. Fullscreen
+	// windows take on the size of the desktop instead
+	flags := uint32(sdl.WINDOW_ALLOW_HIGHDPI | sdl.WINDOW_OPENGL |
+		sdl.WINDOW_RESIZABLE)
+	if settings.Fullscreen {
+		flags |= sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	window, err := sdl.CreateWindow("Mineral", sdl.WINDOWPOS_CENTERED,
+		sdl.WINDOWPOS_CENTERED, int32(*width), int32(*height), flags)
 	if err != nil {
 		log.Fatalln("failed to create a new window:", err)
 	}
@@ -64,10 +248,35 @@ func main() {
 	log.Println("OpenGL version:", glVersion)
 	log.Println("GLSL version:", glslVersion)
 
-	// Create the main game state
-	game := game.New(window)
+	// Create the main game state. If it fails (e.g. because a shader doesn't
+	// compile), tell the user why before quitting, rather than crashing
+	game, err := game.New(window, *dayLength, settings, *seed, saveDir())
+	if err != nil {
+		log.Println("failed to start game:", err)
+		sdl.ShowSimpleMessageBox(sdl.MESSAGEBOX_ERROR, "Mineral",
+			"Failed to start game:\n"+err.Error(), window)
+		return
+	}
 	defer game.Destroy()
 
+	// Save the world before it's destroyed. Quitting with Ctrl-C stops the
+	// main loop like closing the window does, so the world is saved then too
+	defer func() {
+		if err := game.Save(); err != nil {
+			log.Println("failed to save world:", err)
+		}
+	}()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	// Remember any settings changed while playing for next time
+	defer func() {
+		changed := saved.WithChanges(settings, game.Settings())
+		if err := changed.Save(); err != nil {
+			log.Println("failed to save settings:", err)
+		}
+	}()
+
 	// `lag` accumulates how much time each frame takes, so we can run the
 	// update function at a constant time step
 	previousTime := time.Now()
@@ -83,6 +292,11 @@ func main() {
 		previousTime = currentTime
 
 		// Handle user input
+		select {
+		case <-interrupt:
+			running = false
+		default:
+		}
 		for evt := sdl.PollEvent(); evt != nil; evt = sdl.PollEvent() {
 			if _, ok := evt.(*sdl.QuitEvent); ok {
 				running = false
@@ -94,14 +308,21 @@ func main() {
 		// Update the game at a fixed time step, triggering multiple updates if
 		// we've fallen behind (e.g. if rendering or the previous update takes
 		// too long)
+		game.PrepareUpdates(int(lag / nsPerTick))
 		for lag >= nsPerTick {
 			game.Update()
 			lag -= nsPerTick
 		}
 
 		// Render the game as fast as possible, dropping render frames to update
-		// the game if necessary
-		game.Render()
+		// the game if necessary. The leftover lag tells us how far we are
+		// towards the next update tick
+		game.Render(float32(lag) / nsPerTick)
 		sdl.GLSwapWindow(window)
+
+		// Wait out the rest of the frame if the frame rate is limited. The
+		// time spent sleeping is added to `lag` at the start of the next
+		// frame, so the game still updates at the same rate
+		time.Sleep(frameSleep(settings.MaxFPS, time.Since(currentTime)))
 	}
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestProfileConfig(t *testing.T) {
+	defer func(addr, file string, duration time.Duration) {
+		*pprofAddr, *cpuProfile, *cpuProfileDuration = addr, file, duration
+	}(*pprofAddr, *cpuProfile, *cpuProfileDuration)
+
+	// Profiling is off unless it's asked for
+	if profileConfig().Enabled() {
+		t.Error("profiling enabled by default")
+	}
+
+	*pprofAddr = "localhost:6060"
+	*cpuProfile = "cpu.prof"
+	*cpuProfileDuration = 30 * time.Second
+	config := profileConfig()
+	if !config.Enabled() {
+		t.Error("profiling not enabled")
+	}
+	if config.HTTPAddr != "localhost:6060" || config.CPUFile != "cpu.prof" ||
+		config.CPUDuration != 30*time.Second {
+		t.Errorf("got profile config %+v", config)
+	}
+}
+
+func TestClampFlag(t *testing.T) {
+	tests := []struct {
+		value, want int
+	}{
+		{5, 5},
+		{1, 1},
+		{32, 32},
+		{0, 1},
+		{-7, 1},
+		{33, 32},
+	}
+	for _, test := range tests {
+		if got := clampFlag("test", test.value, 1, 32); got != test.want {
+			t.Errorf("clamped %d to %d, want %d", test.value, got, test.want)
+		}
+	}
+}
+
+// ZeroSource is a random number source that returns 0 a few times before
+// returning a fixed non-zero number.
+type zeroSource struct {
+	zeros int
+}
+
+func (s *zeroSource) Int63() int64 {
+	if s.zeros > 0 {
+		s.zeros--
+		return 0
+	}
+	return 42
+}
+
+func (s *zeroSource) Seed(seed int64) {}
+
+func TestRandomSeedIsNeverZero(t *testing.T) {
+	if got := randomSeed(rand.New(&zeroSource{zeros: 3})); got != 42 {
+		t.Errorf("got seed %d, want 42", got)
+	}
+
+	// The same random numbers give the same seed
+	a := randomSeed(rand.New(rand.NewSource(7)))
+	b := randomSeed(rand.New(rand.NewSource(7)))
+	if a != b || a == 0 {
+		t.Errorf("got seeds %d and %d from the same source", a, b)
+	}
+}
+
+func TestFrameSleep(t *testing.T) {
+	tests := []struct {
+		maxFPS    int
+		frameTime time.Duration
+		want      time.Duration
+	}{
+		{0, time.Millisecond, 0},
+		{-30, time.Millisecond, 0},
+		{100, 4 * time.Millisecond, 6 * time.Millisecond},
+		{100, 0, 10 * time.Millisecond},
+		{100, 10 * time.Millisecond, 0},
+		{100, 25 * time.Millisecond, 0},
+		{50, 5 * time.Millisecond, 15 * time.Millisecond},
+	}
+	for _, test := range tests {
+		got := frameSleep(test.maxFPS, test.frameTime)
+		if got != test.want {
+			t.Errorf("got sleep %v after a %v frame at %d fps, want %v", got,
+				test.frameTime, test.maxFPS, test.want)
+		}
+	}
+}
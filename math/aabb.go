@@ -67,3 +67,146 @@ func (a AABB) IntersectionZ(b AABB) float32 {
 	}
 	return math.Nextafter32(a.MinZ()-b.MaxZ(), float32(math.Inf(-1)))
 }
+
+// Min returns the corner of the AABB with the smallest coordinates.
+func (a AABB) Min() mgl32.Vec3 { return a.Center.Sub(a.Size.Mul(0.5)) }
+
+// Max returns the corner of the AABB with the largest coordinates.
+func (a AABB) Max() mgl32.Vec3 { return a.Center.Add(a.Size.Mul(0.5)) }
+
+// Grow returns a copy of the AABB expanded by `amount` on every side, about
+// the same centre.
+func (a AABB) Grow(amount float32) AABB {
+	growth := mgl32.Vec3{amount, amount, amount}.Mul(2.0)
+	return AABB{Center: a.Center, Size: a.Size.Add(growth)}
+}
+
+// SweepEpsilon is the distance by which two AABBs can overlap and still be
+// considered to be just touching when sweeping. This absorbs the floating
+// point error left over after moving an AABB into contact with another.
+const SweepEpsilon = 1e-4
+
+// SweepAABB finds the earliest time at which the AABB `moving` collides with
+// the AABB `static`, if `moving` is moved by `delta`. The time is a fraction
+// of the movement between 0 and 1, and the normal is the surface normal of the
+// face of `static` that was hit.
+//
+// Returns a time of 1 and a zero normal if the AABBs don't collide during the
+// movement (or if they're only sliding along each other). AABBs that are
+// already touching, and moving towards each other, collide at a time of 0.
+func SweepAABB(moving AABB, delta mgl32.Vec3, static AABB) (t float32,
+	normal mgl32.Vec3) {
+	movingMin, movingMax := moving.Min(), moving.Max()
+	staticMin, staticMax := static.Min(), static.Max()
+
+	// Find the times at which the AABBs start and stop overlapping along each
+	// axis
+	entry := float32(math.Inf(-1))
+	exit := float32(math.Inf(1))
+	entryAxis := -1
+	for axis := 0; axis < 3; axis++ {
+		// The distance between the AABBs along this axis (negative if they
+		// overlap), and the distance until they no longer overlap
+		var gap, depth float32
+		if delta[axis] > 0.0 {
+			gap = staticMin[axis] - movingMax[axis]
+			depth = staticMax[axis] - movingMin[axis]
+		} else {
+			gap = movingMin[axis] - staticMax[axis]
+			depth = movingMax[axis] - staticMin[axis]
+		}
+
+		// If we're not moving along this axis, then the AABBs must already
+		// overlap along it, otherwise they never collide
+		if delta[axis] == 0.0 {
+			if movingMax[axis] <= staticMin[axis]+SweepEpsilon ||
+				movingMin[axis] >= staticMax[axis]-SweepEpsilon {
+				return 1.0, mgl32.Vec3{}
+			}
+			continue
+		}
+
+		// Treat slightly overlapping AABBs as touching
+		if gap < 0.0 && gap > -SweepEpsilon {
+			gap = 0.0
+		}
+
+		// Convert the distances into times
+		speed := float32(math.Abs(float64(delta[axis])))
+		axisEntry := gap / speed
+		axisExit := depth / speed
+		if axisEntry > entry {
+			entry = axisEntry
+			entryAxis = axis
+		}
+		if axisExit < exit {
+			exit = axisExit
+		}
+	}
+
+	// The AABBs collide if they overlap along every axis at once, sometime
+	// during the movement. AABBs that already overlap (by more than the
+	// epsilon) are ignored, so that they can move apart
+	if entryAxis < 0 || entry > exit || entry < 0.0 || entry >= 1.0 {
+		return 1.0, mgl32.Vec3{}
+	}
+
+	// The normal points back against the direction of movement
+	if delta[entryAxis] > 0.0 {
+		normal[entryAxis] = -1.0
+	} else {
+		normal[entryAxis] = 1.0
+	}
+	return entry, normal
+}
+
+// RayAABB finds where the ray starting at `origin` and travelling in the
+// direction `dir` first enters the AABB `box`, using the slab method. The
+// direction doesn't need to be normalised; the distance is measured in
+// multiples of `dir`, so it's only a true distance if `dir` has length 1.
+//
+// Returns the distance along the ray at which it enters the box, and true if
+// the ray hits the box at all. A ray starting inside the box returns a
+// distance of 0 or less (the point behind the origin where the ray would have
+// entered the box). Boxes entirely behind the ray are missed.
+func RayAABB(origin, dir mgl32.Vec3, box AABB) (tMin float32, hit bool) {
+	boxMin, boxMax := box.Min(), box.Max()
+	tMin = float32(math.Inf(-1))
+	tMax := float32(math.Inf(1))
+	for axis := 0; axis < 3; axis++ {
+		// A ray parallel to this axis never enters or leaves the slab between
+		// the box's faces along it, so it must start within the slab. This is
+		// checked separately since dividing by a zero direction gives NaN for
+		// rays starting exactly on one of the faces
+		if dir[axis] == 0.0 {
+			if origin[axis] < boxMin[axis] || origin[axis] > boxMax[axis] {
+				return 0.0, false
+			}
+			continue
+		}
+
+		// Find the distances at which the ray crosses each face along this
+		// axis, nearest first
+		inv := 1.0 / dir[axis]
+		t1 := (boxMin[axis] - origin[axis]) * inv
+		t2 := (boxMax[axis] - origin[axis]) * inv
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+
+		// The ray is inside the box where it's inside every slab at once
+		if t1 > tMin {
+			tMin = t1
+		}
+		if t2 < tMax {
+			tMax = t2
+		}
+	}
+
+	// The ray misses if it leaves one slab before entering another, or if the
+	// box is behind the ray
+	if tMin > tMax || tMax < 0.0 {
+		return 0.0, false
+	}
+	return tMin, true
+}
@@ -0,0 +1,185 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// UnitBox is the AABB of the block at the origin.
+var unitBox = AABB{Center: mgl32.Vec3{0.5, 0.5, 0.5},
+	Size: mgl32.Vec3{1.0, 1.0, 1.0}}
+
+func TestRayAABBHit(t *testing.T) {
+	tests := []struct {
+		origin, dir mgl32.Vec3
+		want        float32
+	}{
+		// Straight along each axis
+		{mgl32.Vec3{-2.0, 0.5, 0.5}, mgl32.Vec3{1.0, 0.0, 0.0}, 2.0},
+		{mgl32.Vec3{0.5, 5.0, 0.5}, mgl32.Vec3{0.0, -1.0, 0.0}, 4.0},
+		{mgl32.Vec3{0.5, 0.5, 3.0}, mgl32.Vec3{0.0, 0.0, -1.0}, 2.0},
+
+		// Diagonally through a corner, with an unnormalised direction
+		{mgl32.Vec3{-1.0, -1.0, -1.0}, mgl32.Vec3{2.0, 2.0, 2.0}, 0.5},
+
+		// Along one of the box's faces
+		{mgl32.Vec3{-1.0, 1.0, 0.5}, mgl32.Vec3{1.0, 0.0, 0.0}, 1.0},
+	}
+	for _, test := range tests {
+		got, hit := RayAABB(test.origin, test.dir, unitBox)
+		if !hit {
+			t.Errorf("ray from %v along %v missed", test.origin, test.dir)
+		} else if mgl32.Abs(got-test.want) > 1e-5 {
+			t.Errorf("ray from %v along %v hit at %v, want %v", test.origin,
+				test.dir, got, test.want)
+		}
+	}
+}
+
+func TestRayAABBMiss(t *testing.T) {
+	tests := []struct {
+		origin, dir mgl32.Vec3
+	}{
+		// Pointing away from the box
+		{mgl32.Vec3{-2.0, 0.5, 0.5}, mgl32.Vec3{-1.0, 0.0, 0.0}},
+
+		// Passing beside the box
+		{mgl32.Vec3{-2.0, 1.5, 0.5}, mgl32.Vec3{1.0, 0.0, 0.0}},
+		{mgl32.Vec3{-2.0, 0.5, -2.0}, mgl32.Vec3{1.0, 0.0, 0.2}},
+	}
+	for _, test := range tests {
+		if _, hit := RayAABB(test.origin, test.dir, unitBox); hit {
+			t.Errorf("ray from %v along %v hit the box", test.origin,
+				test.dir)
+		}
+	}
+}
+
+func TestRayAABBStartingInside(t *testing.T) {
+	got, hit := RayAABB(mgl32.Vec3{0.5, 0.5, 0.5}, mgl32.Vec3{1.0, 0.0, 0.0},
+		unitBox)
+	if !hit {
+		t.Fatal("ray starting inside the box missed")
+	}
+	if got > 0.0 {
+		t.Errorf("ray starting inside the box hit at %v, want 0 or less", got)
+	}
+}
+
+// Penetrates returns true if two AABBs overlap by more than `SweepEpsilon`
+// along every axis.
+func penetrates(a, b AABB) bool {
+	aMin, aMax := a.Min(), a.Max()
+	bMin, bMax := b.Min(), b.Max()
+	for axis := 0; axis < 3; axis++ {
+		if aMax[axis]-bMin[axis] <= SweepEpsilon ||
+			bMax[axis]-aMin[axis] <= SweepEpsilon {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSweepAABBHitsBlockInPath(t *testing.T) {
+	// A box a little smaller than the unit box, starting 1 block away from
+	// it along each direction
+	size := mgl32.Vec3{0.6, 0.6, 0.6}
+	left := AABB{Center: mgl32.Vec3{-1.3, 0.5, 0.5}, Size: size}
+	above := AABB{Center: mgl32.Vec3{0.5, 2.3, 0.5}, Size: size}
+	behind := AABB{Center: mgl32.Vec3{0.5, 0.5, 2.3}, Size: size}
+	tests := []struct {
+		moving AABB
+		delta  mgl32.Vec3
+		want   float32
+		normal mgl32.Vec3
+	}{
+		// Slower than a block per tick
+		{left, mgl32.Vec3{1.5, 0.0, 0.0}, 1.0 / 1.5, mgl32.Vec3{-1, 0, 0}},
+		{above, mgl32.Vec3{0.0, -1.25, 0.0}, 0.8, mgl32.Vec3{0, 1, 0}},
+
+		// Faster than a block per tick, which would tunnel straight through
+		// the block if the box was just moved by the whole delta
+		{left, mgl32.Vec3{3.0, 0.0, 0.0}, 1.0 / 3.0, mgl32.Vec3{-1, 0, 0}},
+		{above, mgl32.Vec3{0.0, -10.0, 0.0}, 0.1, mgl32.Vec3{0, 1, 0}},
+		{behind, mgl32.Vec3{0.0, 0.0, -100.0}, 0.01, mgl32.Vec3{0, 0, 1}},
+
+		// Diagonally, hitting the top of the block
+		{above, mgl32.Vec3{0.2, -4.0, 0.2}, 0.25, mgl32.Vec3{0, 1, 0}},
+	}
+	for _, test := range tests {
+		got, normal := SweepAABB(test.moving, test.delta, unitBox)
+		if got >= 1.0 || mgl32.Abs(got-test.want) > 1e-5 {
+			t.Errorf("box moving by %v hit at %v, want %v", test.delta, got,
+				test.want)
+		}
+		if normal != test.normal {
+			t.Errorf("box moving by %v hit with normal %v, want %v",
+				test.delta, normal, test.normal)
+		}
+
+		// Moving the box up to the time of impact leaves it touching the
+		// block, not inside it
+		moved := test.moving
+		moved.Offset(test.delta.Mul(got))
+		if penetrates(moved, unitBox) {
+			t.Errorf("box moving by %v ended up inside the block at %v",
+				test.delta, moved.Center)
+		}
+	}
+}
+
+func TestSweepAABBMisses(t *testing.T) {
+	size := mgl32.Vec3{0.6, 0.6, 0.6}
+	left := AABB{Center: mgl32.Vec3{-1.3, 0.5, 0.5}, Size: size}
+	tests := []mgl32.Vec3{
+		// Stopping short of the block
+		{0.5, 0.0, 0.0},
+
+		// Moving away from the block
+		{-3.0, 0.0, 0.0},
+
+		// Passing beside the block
+		{0.0, 0.0, 5.0},
+		{3.0, 3.0, 0.0},
+	}
+	for _, delta := range tests {
+		got, normal := SweepAABB(left, delta, unitBox)
+		if got != 1.0 || normal != (mgl32.Vec3{}) {
+			t.Errorf("box moving by %v hit at %v with normal %v, want a "+
+				"miss", delta, got, normal)
+		}
+	}
+}
+
+func TestIntersection(t *testing.T) {
+	axes := []struct {
+		name         string
+		dir          mgl32.Vec3
+		intersection func(a, b AABB) float32
+	}{
+		{"x", mgl32.Vec3{1.0, 0.0, 0.0}, AABB.IntersectionX},
+		{"y", mgl32.Vec3{0.0, 1.0, 0.0}, AABB.IntersectionY},
+		{"z", mgl32.Vec3{0.0, 0.0, 1.0}, AABB.IntersectionZ},
+	}
+	for _, axis := range axes {
+		// Boxes overlapping the unit box by 0.25 from either side along the
+		// axis
+		for _, side := range []float32{1.0, -1.0} {
+			box := unitBox
+			box.Offset(axis.dir.Mul(side * 0.75))
+			got := axis.intersection(box, unitBox)
+			if mgl32.Abs(got-side*-0.25) > 1e-5 {
+				t.Errorf("got %s intersection %v from side %v, want %v",
+					axis.name, got, side, side*-0.25)
+			}
+
+			// Moving back by the intersection separates the boxes
+			box.Offset(axis.dir.Mul(-got))
+			if box.Intersects(unitBox) {
+				t.Errorf("boxes still overlap after moving back by the %s "+
+					"intersection from side %v", axis.name, side)
+			}
+		}
+	}
+}
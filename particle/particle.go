@@ -0,0 +1,229 @@
+// Package particle simulates and renders small, short lived particles, like
+// the pieces a block breaks into.
+package particle
+
+import (
+	"math/rand"
+
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// MaxParticles is the most particles that can exist at once. Any emitted
+	// beyond this are dropped, so lots of blocks breaking at once can't slow
+	// the game down.
+	MaxParticles = 1024
+
+	// The number of particles along each axis of the grid a broken block is
+	// split into.
+	breakGridSize = 4
+
+	// The downward acceleration applied to each particle, in blocks per update
+	// tick squared.
+	gravity = 0.004
+
+	// The fraction of a particle's velocity kept after each update tick, so
+	// particles slow down as they fly through the air.
+	drag = 0.98
+
+	// The fraction of a particle's horizontal velocity kept after each update
+	// tick spent resting on the ground.
+	groundFriction = 0.7
+
+	// The range of lifetimes of particles from a broken block, in update
+	// ticks. Particles fade out over their lifetime.
+	minBreakLifetime = 40
+	maxBreakLifetime = 80
+
+	// The fastest a particle from a broken block flies away from the block's
+	// centre, and the upwards speed added to every particle, in blocks per
+	// update tick.
+	maxBreakSpeed = 0.06
+	breakLift     = 0.05
+
+	// The size of the piece of the block's texture that each particle from a
+	// broken block shows, as a fraction of the whole texture.
+	breakUVScale = 0.25
+)
+
+// Particle is a single particle.
+type Particle struct {
+	Position mgl32.Vec3 // The particle's centre, in world space
+	Velocity mgl32.Vec3 // In blocks per update tick
+
+	// The particle's position before the most recent update tick, used to
+	// interpolate between update ticks when rendering
+	prevPosition mgl32.Vec3
+
+	// The number of update ticks the particle has existed for, and the number
+	// of ticks it lives for in total
+	Age, Lifetime int
+
+	// The part of the block texture atlas shown on the particle: the minimum
+	// UV coordinate and the size of the area
+	uv, uvSize mgl32.Vec2
+
+	// True if the particle disappears as soon as it lands on a block, rather
+	// than resting there until the end of its lifetime (e.g. rain drops)
+	expireOnLanding bool
+}
+
+// System updates and renders every particle in the world.
+type System struct {
+	// The particles that are alive. The slice's capacity is set when the
+	// system's created, and is never exceeded, so emitting particles never
+	// allocates
+	particles []Particle
+
+	// Random numbers for scattering particles
+	rand *rand.Rand
+
+	renderer // OpenGL resources for drawing the particles
+}
+
+// New creates a particle system with no particles, allocating the OpenGL
+// resources needed to render them. Returns an error if the particle shaders
+// fail to load.
+func New() (*System, error) {
+	renderer, err := newRenderer()
+	if err != nil {
+		return nil, err
+	}
+	return &System{
+		particles: make([]Particle, 0, MaxParticles),
+		rand:      rand.New(rand.NewSource(1)),
+		renderer:  renderer,
+	}, nil
+}
+
+// NewHeadless creates a particle system without any OpenGL resources, for
+// tests that only need to emit and update particles. The system must never be
+// rendered or destroyed.
+func NewHeadless() *System {
+	return &System{
+		particles: make([]Particle, 0, MaxParticles),
+		rand:      rand.New(rand.NewSource(1)),
+	}
+}
+
+// Destroy releases all the resources allocated by the particle system.
+func (s *System) Destroy() {
+	s.renderer.destroy()
+}
+
+// EmitBlockBreak scatters particles from the block at the given world-space
+// coordinates, which has just been broken. The particles show small pieces of
+// the block's texture, whose UV coordinate in the block texture atlas is
+// given.
+func (s *System) EmitBlockBreak(block [3]int, uv world.FaceUV) {
+	w, h := uv.Size()
+	corner := mgl32.Vec3{float32(block[0]), float32(block[1]),
+		float32(block[2])}
+	for x := 0; x < breakGridSize; x++ {
+		for y := 0; y < breakGridSize; y++ {
+			for z := 0; z < breakGridSize; z++ {
+				// Spread the particles evenly through the block, moving away
+				// from its centre
+				offset := mgl32.Vec3{
+					(float32(x)+0.5)/breakGridSize - 0.5,
+					(float32(y)+0.5)/breakGridSize - 0.5,
+					(float32(z)+0.5)/breakGridSize - 0.5,
+				}
+				velocity := offset.Mul(2.0 * maxBreakSpeed * s.rand.Float32())
+				velocity[1] += breakLift
+
+				// Show a random piece of the block's texture
+				u := uv.X + w*s.rand.Float32()*(1.0-breakUVScale)
+				v := uv.Y + h*s.rand.Float32()*(1.0-breakUVScale)
+				lifetime := minBreakLifetime +
+					s.rand.Intn(maxBreakLifetime-minBreakLifetime+1)
+				s.emit(Particle{
+					Position: corner.Add(offset).Add(mgl32.Vec3{0.5, 0.5, 0.5}),
+					Velocity: velocity,
+					Lifetime: lifetime,
+					uv:       mgl32.Vec2{u, v},
+					uvSize:   mgl32.Vec2{w * breakUVScale, h * breakUVScale},
+				})
+			}
+		}
+	}
+}
+
+// Emit adds a particle to the system, unless there are already
+// `MaxParticles` particles.
+func (s *System) emit(p Particle) {
+	if len(s.particles) >= MaxParticles {
+		return
+	}
+	p.prevPosition = p.Position
+	s.particles = append(s.particles, p)
+}
+
+// Count returns the number of particles that are alive.
+func (s *System) Count() int {
+	return len(s.particles)
+}
+
+// Update moves every particle under gravity, stopping them at solid blocks,
+// and removes particles that have reached the end of their lifetime (or that
+// have landed, for particles that expire on landing).
+func (s *System) Update(w *world.World) {
+	for i := 0; i < len(s.particles); {
+		p := &s.particles[i]
+		p.Age++
+		alive := p.Age < p.Lifetime
+		if alive {
+			landed := updateParticle(w, p)
+			alive = !landed || !p.expireOnLanding
+		}
+		if !alive {
+			// Swap the last particle into this one's place; the order of
+			// particles doesn't matter
+			last := len(s.particles) - 1
+			s.particles[i] = s.particles[last]
+			s.particles = s.particles[:last]
+			continue
+		}
+		i++
+	}
+}
+
+// UpdateParticle moves a particle along by its velocity for a single update
+// tick, and accelerates it downwards due to gravity. Returns true if the
+// particle is resting on top of a solid block.
+//
+// Particles are treated as points, and are moved along each axis separately,
+// so they can slide along blocks. A particle that would move into a solid
+// block stops moving along that axis instead. The vertical axis goes first,
+// so a particle that lands is slowed down by the ground straight away.
+func updateParticle(w *world.World, p *Particle) (landed bool) {
+	p.prevPosition = p.Position
+	p.Velocity[1] -= gravity
+	p.Velocity = p.Velocity.Mul(drag)
+	for _, axis := range [...]int{1, 0, 2} {
+		next := p.Position
+		next[axis] += p.Velocity[axis]
+		if isSolid(w, next) {
+			if axis == 1 && p.Velocity[1] <= 0.0 {
+				// Resting on the ground slows the particle down
+				p.Velocity[0] *= groundFriction
+				p.Velocity[2] *= groundFriction
+				landed = true
+			}
+			p.Velocity[axis] = 0.0
+			continue
+		}
+		p.Position = next
+	}
+	return landed
+}
+
+// IsSolid returns true if the given world-space position is inside a block
+// that particles can't move through.
+func isSolid(w *world.World, position mgl32.Vec3) bool {
+	x, y, z := world.ToWorldSpace(position.X(), position.Y(), position.Z())
+	block, ok := w.GetBlock(x, y, z)
+	return ok && w.GetBlockInfo(block).Collidable
+}
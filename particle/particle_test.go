@@ -0,0 +1,152 @@
+package particle
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The top of the stone floor in the chunk loaded for testing.
+const testFloorY = 3.0
+
+// NewTestWorld creates a headless world with the chunk at the origin loaded.
+func newTestWorld() *world.World {
+	w := world.NewHeadless(32, 1)
+	w.LoadChunkHeadless(0, 0)
+	return w
+}
+
+func TestUpdateParticleIntegratesVelocity(t *testing.T) {
+	w := newTestWorld()
+	start := mgl32.Vec3{8.0, 20.0, 8.0}
+	p := Particle{Position: start, Velocity: mgl32.Vec3{0.05, 0.1, 0.0}}
+
+	// Each tick, gravity and drag change the velocity before the particle
+	// moves by it
+	wantPos, wantVel := start, p.Velocity
+	for i := 0; i < 40; i++ {
+		if updateParticle(w, &p) {
+			t.Fatalf("particle landed in mid air at %v", p.Position)
+		}
+		wantVel[1] -= gravity
+		wantVel = wantVel.Mul(drag)
+		wantPos = wantPos.Add(wantVel)
+		if p.Velocity.Sub(wantVel).Len() > 1e-5 ||
+			p.Position.Sub(wantPos).Len() > 1e-4 {
+			t.Fatalf("tick %d: got particle at %v moving at %v, want %v "+
+				"moving at %v", i, p.Position, p.Velocity, wantPos, wantVel)
+		}
+	}
+	if p.Velocity.Y() >= 0.0 {
+		t.Error("gravity didn't turn the particle around")
+	}
+}
+
+func TestParticleLandsOnGround(t *testing.T) {
+	w := newTestWorld()
+	p := Particle{Position: mgl32.Vec3{8.0, testFloorY + 1.0, 8.0},
+		Velocity: mgl32.Vec3{0.02, 0.0, 0.0}}
+	landed := false
+	for i := 0; i < 100 && !landed; i++ {
+		landed = updateParticle(w, &p)
+	}
+	if !landed {
+		t.Fatal("particle never landed")
+	}
+	if p.Position.Y() < testFloorY || p.Velocity.Y() != 0.0 {
+		t.Errorf("landed particle at %v moving at %v, want resting on the "+
+			"floor", p.Position, p.Velocity)
+	}
+}
+
+func TestParticlesExpire(t *testing.T) {
+	w := newTestWorld()
+	s := NewHeadless()
+	s.emit(Particle{Position: mgl32.Vec3{8.0, 20.0, 8.0}, Lifetime: 5})
+	s.emit(Particle{Position: mgl32.Vec3{8.0, 20.0, 8.0}, Lifetime: 10})
+	for i := 0; i < 4; i++ {
+		s.Update(w)
+	}
+	if s.Count() != 2 {
+		t.Fatalf("got %d particles before either expired, want 2",
+			s.Count())
+	}
+	s.Update(w)
+	if s.Count() != 1 || s.particles[0].Lifetime != 10 {
+		t.Fatal("particle didn't expire at the end of its lifetime")
+	}
+	for i := 0; i < 5; i++ {
+		s.Update(w)
+	}
+	if s.Count() != 0 {
+		t.Errorf("got %d particles after both expired", s.Count())
+	}
+}
+
+func TestParticleExpiresOnLanding(t *testing.T) {
+	w := newTestWorld()
+	s := NewHeadless()
+	s.emit(Particle{Position: mgl32.Vec3{8.0, testFloorY + 0.1, 8.0},
+		Velocity: mgl32.Vec3{0.0, -0.3, 0.0}, Lifetime: 100,
+		expireOnLanding: true})
+	s.Update(w)
+	if s.Count() != 0 {
+		t.Error("rain drop didn't disappear when it landed")
+	}
+}
+
+func TestEmitBlockBreakIsBounded(t *testing.T) {
+	s := NewHeadless()
+	uv := world.FaceUV{X: 0.25, Y: 0.5, Frames: 1}
+	s.EmitBlockBreak([3]int{1, 2, 3}, uv)
+	perBlock := breakGridSize * breakGridSize * breakGridSize
+	if s.Count() != perBlock {
+		t.Fatalf("got %d particles from a broken block, want %d", s.Count(),
+			perBlock)
+	}
+
+	// Every particle starts inside the block and lives for a bounded time
+	for _, p := range s.particles {
+		for axis, corner := range [...]float32{1.0, 2.0, 3.0} {
+			if p.Position[axis] < corner || p.Position[axis] > corner+1.0 {
+				t.Fatalf("particle at %v outside the broken block",
+					p.Position)
+			}
+		}
+		if p.Lifetime < minBreakLifetime || p.Lifetime > maxBreakLifetime {
+			t.Errorf("got particle lifetime %d", p.Lifetime)
+		}
+	}
+
+	// Breaking lots of blocks at once can't exceed the limit
+	for i := 0; i < MaxParticles/perBlock+5; i++ {
+		s.EmitBlockBreak([3]int{i, 2, 3}, uv)
+	}
+	if s.Count() != MaxParticles {
+		t.Errorf("got %d particles, want the limit of %d", s.Count(),
+			MaxParticles)
+	}
+}
+
+func TestParticleFadesOut(t *testing.T) {
+	p := Particle{Position: mgl32.Vec3{1.0, 2.0, 3.0}, Lifetime: 40}
+	p.prevPosition = p.Position
+	right, up := mgl32.Vec3{0.1, 0.0, 0.0}, mgl32.Vec3{0.0, 0.1, 0.0}
+	fade := func(age int) float32 {
+		p.Age = age
+		vertices := appendParticle(nil, &p, right, up, 0.0)
+		return vertices[5] // The first vertex's fade
+	}
+	if got := fade(0); got != 1.0 {
+		t.Errorf("got fade %v for a new particle, want 1", got)
+	}
+	if got := fade(20); mgl32.Abs(got-0.5) > 1e-5 {
+		t.Errorf("got fade %v halfway through a particle's life, want 0.5",
+			got)
+	}
+	if got := fade(40); got != 0.0 {
+		t.Errorf("got fade %v at the end of a particle's life, want 0", got)
+	}
+}
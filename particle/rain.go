@@ -0,0 +1,70 @@
+package particle
+
+import (
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// The number of rain drops spawned each update tick while it's raining
+	// fully. Fewer are spawned while rain is fading in or out.
+	rainDropsPerTick = 12
+
+	// The horizontal distance from the player within which rain drops are
+	// spawned, and the range of heights above the player, in blocks.
+	rainRadius    = 10
+	minRainHeight = 4
+	maxRainHeight = 14
+
+	// The speed at which rain drops start falling, in blocks per update tick.
+	// Drag slowly brings them down to a slightly slower steady speed.
+	rainSpeed = 0.3
+
+	// The longest a rain drop can fall for before disappearing, in update
+	// ticks. Most land well before this.
+	rainLifetime = 80
+
+	// The size of the piece of the water texture that each rain drop shows,
+	// as a fraction of the whole texture.
+	rainUVScale = 0.125
+)
+
+// EmitRain spawns rain drops falling around the given world-space position
+// (the player's), showing small pieces of the texture at the given UV
+// coordinate in the block texture atlas (the water texture). `strength` is
+// how heavily it's raining, between 0 and 1.
+//
+// Drops are only spawned where there's nothing above them between the drop
+// and the sky, so it doesn't rain inside caves or buildings. Drops disappear
+// as soon as they land.
+func (s *System) EmitRain(w *world.World, around mgl32.Vec3, strength float32,
+	uv world.FaceUV) {
+	uvWidth, uvHeight := uv.Size()
+	count := int(strength*rainDropsPerTick + 0.5)
+	for i := 0; i < count; i++ {
+		position := mgl32.Vec3{
+			around.X() + (s.rand.Float32()*2.0-1.0)*rainRadius,
+			around.Y() + minRainHeight +
+				s.rand.Float32()*(maxRainHeight-minRainHeight),
+			around.Z() + (s.rand.Float32()*2.0-1.0)*rainRadius,
+		}
+		x, y, z := world.ToWorldSpace(position.X(), position.Y(),
+			position.Z())
+		if isSolid(w, position) || !w.HasSkyAccess(x, y, z) {
+			continue
+		}
+
+		// Show a random piece of the water texture
+		u := uv.X + uvWidth*s.rand.Float32()*(1.0-rainUVScale)
+		v := uv.Y + uvHeight*s.rand.Float32()*(1.0-rainUVScale)
+		s.emit(Particle{
+			Position:        position,
+			Velocity:        mgl32.Vec3{0.0, -rainSpeed, 0.0},
+			Lifetime:        rainLifetime,
+			uv:              mgl32.Vec2{u, v},
+			uvSize:          mgl32.Vec2{uvWidth, uvHeight}.Mul(rainUVScale),
+			expireOnLanding: true,
+		})
+	}
+}
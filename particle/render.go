@@ -0,0 +1,165 @@
+package particle
+
+import (
+	"github.com/benanders/mineral/render"
+	"github.com/benanders/mineral/world"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// The length of each side of a particle, in blocks.
+	particleSize = 0.1
+
+	// The number of floats in the vertex data for each vertex of a particle:
+	// its position, UV coordinate, and opacity.
+	valuesPerVertex = 3 + 2 + 1
+
+	// The number of vertices making up each particle; two triangles.
+	verticesPerParticle = 6
+)
+
+// Renderer draws particles as squares that always face the camera (known as
+// billboards), textured with part of the block texture atlas. The vertex data
+// is rebuilt every frame, since every particle moves.
+type renderer struct {
+	vao, vbo uint32
+	program  *render.ReloadableProgram
+
+	// Vertex data for every particle, kept between frames so it doesn't have
+	// to be reallocated every frame
+	vertices []float32
+}
+
+// NewRenderer allocates the required OpenGL resources for drawing particles.
+func newRenderer() (renderer, error) {
+	// Create the program
+	program, err := render.LoadReloadableShaders(
+		"shaders/particleVert.glsl",
+		"shaders/particleFrag.glsl")
+	if err != nil {
+		return renderer{}, err
+	}
+	program.Use()
+
+	// Particles are textured using the block texture atlas
+	gl.Uniform1i(program.Uniform("blockAtlas"), world.BlockAtlasSlot)
+
+	// Create the VAO and VBO
+	var vao, vbo uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+
+	// Set up the vertex attributes
+	stride := int32(valuesPerVertex * 4)
+	posAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("position\x00")))
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 3, gl.FLOAT, false, stride,
+		gl.PtrOffset(0))
+	uvAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("uv\x00")))
+	gl.EnableVertexAttribArray(uvAttr)
+	gl.VertexAttribPointer(uvAttr, 2, gl.FLOAT, false, stride,
+		gl.PtrOffset(3*4))
+	fadeAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("fade\x00")))
+	gl.EnableVertexAttribArray(fadeAttr)
+	gl.VertexAttribPointer(fadeAttr, 1, gl.FLOAT, false, stride,
+		gl.PtrOffset(5*4))
+
+	vertices := make([]float32, 0,
+		MaxParticles*verticesPerParticle*valuesPerVertex)
+	return renderer{vao: vao, vbo: vbo, program: program,
+		vertices: vertices}, nil
+}
+
+// Destroy releases all the resources allocated by the renderer.
+func (r *renderer) destroy() {
+	r.program.Destroy()
+	gl.DeleteVertexArrays(1, &r.vao)
+	gl.DeleteBuffers(1, &r.vbo)
+}
+
+// Render draws every particle. `viewProjection` is the camera's combined
+// projection and view matrix, and `sight` is the direction the camera is
+// looking in, which the particles face. `alpha` is how far through the current
+// update tick we are, used to interpolate each particle's position.
+//
+// Particles are depth tested against the world, so must be drawn after it.
+func (s *System) Render(viewProjection mgl32.Mat4, sight mgl32.Vec3,
+	alpha float32) {
+	if len(s.particles) == 0 {
+		return
+	}
+
+	// Find the directions across and up the screen, along which the sides of
+	// each particle lie
+	right := sight.Cross(mgl32.Vec3{0.0, 1.0, 0.0}).Normalize()
+	up := right.Cross(sight).Normalize()
+	right = right.Mul(particleSize / 2.0)
+	up = up.Mul(particleSize / 2.0)
+
+	// Build the vertex data for every particle
+	s.vertices = s.vertices[:0]
+	for i := range s.particles {
+		s.vertices = appendParticle(s.vertices, &s.particles[i], right, up,
+			alpha)
+	}
+
+	// Upload the vertex data, orphaning the old buffer so we don't have to
+	// wait for the previous frame to finish drawing from it
+	gl.BindVertexArray(s.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, cap(s.vertices)*4, nil, gl.STREAM_DRAW)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(s.vertices)*4,
+		gl.Ptr(s.vertices))
+
+	s.program.Use()
+	gl.UniformMatrix4fv(s.program.Uniform("mvp"), 1, false,
+		&viewProjection[0])
+
+	// Particles fade out, so are blended with whatever's behind them. They're
+	// small and short lived, so don't bother sorting them
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.DepthMask(false)
+	gl.DrawArrays(gl.TRIANGLES, 0,
+		int32(len(s.particles)*verticesPerParticle))
+
+	// Reset the OpenGL state
+	gl.DepthMask(true)
+	gl.Disable(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+}
+
+// AppendParticle appends the vertex data for a particle to `vertices`, as two
+// triangles. `right` and `up` are half the particle's size, pointing across
+// and up the screen.
+func appendParticle(vertices []float32, p *Particle, right, up mgl32.Vec3,
+	alpha float32) []float32 {
+	center := p.prevPosition.Add(p.Position.Sub(p.prevPosition).Mul(alpha))
+	fade := 1.0 - (float32(p.Age)+alpha)/float32(p.Lifetime)
+
+	// The corners of the particle, going anticlockwise from the bottom left,
+	// and the UV coordinate of each
+	corners := [4]mgl32.Vec3{
+		center.Sub(right).Sub(up),
+		center.Add(right).Sub(up),
+		center.Add(right).Add(up),
+		center.Sub(right).Add(up),
+	}
+	uvs := [4]mgl32.Vec2{
+		{p.uv.X(), p.uv.Y() + p.uvSize.Y()},
+		p.uv.Add(p.uvSize),
+		{p.uv.X() + p.uvSize.X(), p.uv.Y()},
+		p.uv,
+	}
+	for _, i := range [...]int{0, 1, 2, 2, 3, 0} {
+		c, uv := corners[i], uvs[i]
+		vertices = append(vertices, c.X(), c.Y(), c.Z(), uv.X(), uv.Y(),
+			fade)
+	}
+	return vertices
+}
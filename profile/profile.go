@@ -0,0 +1,77 @@
+// Package profile runs Go's built in profilers while the game is running, for
+// finding out where time is spent generating chunks and rendering.
+package profile
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	_ "net/http/pprof" // Registers the profiling endpoints
+	"os"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// Config describes which profilers to run. The zero value runs none of them,
+// in which case profiling has no overhead at all.
+type Config struct {
+	// The address to serve the `net/http/pprof` endpoints on (e.g.
+	// "localhost:6060"), from which CPU and heap profiles can be taken at any
+	// time with `go tool pprof`. No server is started if empty.
+	HTTPAddr string
+
+	// The file to write a CPU profile to, covering the first `CPUDuration` of
+	// the game (or the whole game if `CPUDuration` is 0). No profile is
+	// written if empty.
+	CPUFile     string
+	CPUDuration time.Duration
+}
+
+// Enabled returns true if the config runs any profilers.
+func (c Config) Enabled() bool {
+	return c.HTTPAddr != "" || c.CPUFile != ""
+}
+
+// Start starts the profilers in the config. The returned function stops them
+// again, finishing off the CPU profile if it's still being recorded, and must
+// be called before the program exits.
+func Start(c Config) (stop func(), err error) {
+	if c.HTTPAddr != "" {
+		// Serve the endpoints in the background for as long as the game runs.
+		// Failing to serve them shouldn't stop anyone playing, so just log it
+		go func() {
+			log.Println("serving pprof on", "http://"+c.HTTPAddr+"/debug/pprof")
+			err := http.ListenAndServe(c.HTTPAddr, nil)
+			log.Println("failed to serve pprof:", err)
+		}()
+	}
+	if c.CPUFile == "" {
+		return func() {}, nil
+	}
+
+	f, err := os.Create(c.CPUFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile `%v`: %v",
+			c.CPUFile, err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %v", err)
+	}
+
+	// The profile is stopped either after the requested duration or when the
+	// game exits, whichever comes first
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			pprof.StopCPUProfile()
+			f.Close()
+			log.Println("wrote CPU profile to", c.CPUFile)
+		})
+	}
+	if c.CPUDuration > 0 {
+		time.AfterFunc(c.CPUDuration, stop)
+	}
+	return stop, nil
+}
@@ -0,0 +1,190 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/png" // The crack textures are provided as .png images
+
+	"github.com/benanders/mineral/asset"
+	"github.com/benanders/mineral/math"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// The number of crack textures, from barely cracked to about to break.
+	numCrackStages = 10
+
+	// The OpenGL texture slot into which the crack textures are loaded.
+	crackTextureSlot = 5
+
+	// CrackInflation is how far the cracks sit outside the surface of the
+	// block, in blocks, so they aren't hidden by the block's faces due to
+	// z-fighting.
+	crackInflation = 0.001
+
+	// The number of values per vertex in the cube's vertex data; 3 for the
+	// position, and 2 for the texture coordinates.
+	valuesPerCrackVertex = 5
+
+	// The number of vertices in the cube; two triangles on each face.
+	numCrackVertices = 6 * 6
+)
+
+// The corners of each face of a unit cube, going anticlockwise when looking
+// at the face from outside the cube.
+var cubeFaces = [6][4]mgl32.Vec3{
+	{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1}}, // Front
+	{{1, 0, 0}, {0, 0, 0}, {0, 1, 0}, {1, 1, 0}}, // Back
+	{{0, 0, 0}, {0, 0, 1}, {0, 1, 1}, {0, 1, 0}}, // Left
+	{{1, 0, 1}, {1, 0, 0}, {1, 1, 0}, {1, 1, 1}}, // Right
+	{{0, 1, 1}, {1, 1, 1}, {1, 1, 0}, {0, 1, 0}}, // Top
+	{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}}, // Bottom
+}
+
+// BlockCracks draws cracks over the block the player is breaking, getting
+// more cracked the closer the block is to breaking (like the destroy stages
+// in Minecraft).
+type BlockCracks struct {
+	vao, vbo uint32
+	program  *ReloadableProgram
+	texture  uint32
+}
+
+// NewBlockCracks loads the crack textures and allocates the required OpenGL
+// resources for drawing cracks. Returns an error if the textures or the
+// shaders fail to load.
+func NewBlockCracks() (*BlockCracks, error) {
+	strip, err := loadCrackStrip()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the program
+	program, err := LoadReloadableShaders(
+		"shaders/crackVert.glsl",
+		"shaders/crackFrag.glsl")
+	if err != nil {
+		return nil, err
+	}
+	program.Use()
+	gl.Uniform1i(program.Uniform("cracks"), crackTextureSlot)
+	gl.Uniform1f(program.Uniform("numStages"), numCrackStages)
+
+	// Create the VAO and VBO, holding a unit cube that's moved onto the block
+	// being broken when rendering
+	var vao, vbo uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	vertices := genCrackVertices()
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(&vertices[0]),
+		gl.STATIC_DRAW)
+
+	// Set up the vertex attributes
+	stride := int32(valuesPerCrackVertex * 4)
+	posAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("position\x00")))
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 3, gl.FLOAT, false, stride,
+		gl.PtrOffset(0))
+	uvAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("uv\x00")))
+	gl.EnableVertexAttribArray(uvAttr)
+	gl.VertexAttribPointer(uvAttr, 2, gl.FLOAT, false, stride,
+		gl.PtrOffset(3*4))
+
+	texture := LoadTexture(strip, crackTextureSlot)
+	return &BlockCracks{vao: vao, vbo: vbo, program: program,
+		texture: texture}, nil
+}
+
+// LoadCrackStrip loads each of the crack textures, and stacks them vertically
+// into a single image, with the least cracked at the top.
+func loadCrackStrip() (*image.RGBA, error) {
+	var strip *image.RGBA
+	for stage := 0; stage < numCrackStages; stage++ {
+		path := fmt.Sprintf("textures/blocks/destroy_stage_%v.png", stage)
+		data, err := asset.Asset(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load image `%v`", path)
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode png image `%v`", path)
+		}
+
+		// Every stage is the same size as the first
+		size := img.Bounds().Size()
+		if strip == nil {
+			strip = image.NewRGBA(image.Rect(0, 0, size.X,
+				size.Y*numCrackStages))
+		}
+		dest := image.Rect(0, stage*size.Y, size.X, (stage+1)*size.Y)
+		draw.Draw(strip, dest, img, img.Bounds().Min, draw.Src)
+	}
+	return strip, nil
+}
+
+// GenCrackVertices builds the vertex data for a unit cube, with each face
+// covered by a whole crack texture.
+func genCrackVertices() []float32 {
+	uvs := [4]mgl32.Vec2{{0, 1}, {1, 1}, {1, 0}, {0, 0}}
+	vertices := make([]float32, 0, numCrackVertices*valuesPerCrackVertex)
+	for _, face := range cubeFaces {
+		for _, i := range [...]int{0, 1, 2, 2, 3, 0} {
+			c, uv := face[i], uvs[i]
+			vertices = append(vertices, c.X(), c.Y(), c.Z(), uv.X(), uv.Y())
+		}
+	}
+	return vertices
+}
+
+// Destroy releases all the resources allocated by the cracks.
+func (c *BlockCracks) Destroy() {
+	c.program.Destroy()
+	gl.DeleteVertexArrays(1, &c.vao)
+	gl.DeleteBuffers(1, &c.vbo)
+	gl.DeleteTextures(1, &c.texture)
+}
+
+// CrackStage returns which of the crack textures to show for a block that's
+// `progress` of the way through breaking, from 0 to 1.
+func crackStage(progress float32) int {
+	stage := int(progress * numCrackStages)
+	if stage < 0 {
+		return 0
+	} else if stage >= numCrackStages {
+		return numCrackStages - 1
+	}
+	return stage
+}
+
+// Render draws cracks over the faces of the given world-space box (the AABB of
+// the block being broken), which is `progress` of the way through breaking,
+// from 0 to 1. `viewProjection` is the camera's combined projection and view
+// matrix. The cracks are depth tested against the world, so they must be
+// drawn after the world.
+func (c *BlockCracks) Render(viewProjection mgl32.Mat4, box math.AABB,
+	progress float32) {
+	c.program.Use()
+	mvp := viewProjection.Mul4(boxModel(box.Grow(crackInflation)))
+	gl.UniformMatrix4fv(c.program.Uniform("mvp"), 1, false, &mvp[0])
+	gl.Uniform1f(c.program.Uniform("stage"), float32(crackStage(progress)))
+
+	// The cracks are blended over the block, and mustn't hide anything
+	// translucent drawn after them
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.DepthMask(false)
+	gl.BindVertexArray(c.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, numCrackVertices)
+
+	// Reset the OpenGL state
+	gl.DepthMask(true)
+	gl.Disable(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+}
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"github.com/benanders/mineral/math"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// OutlineInflation is how far the outline sits outside the surface of the
+	// block, in blocks, so the lines aren't hidden by the block's faces due to
+	// z-fighting.
+	outlineInflation = 0.002
+
+	// The number of vertices in the outline; two for each of the 12 edges of
+	// a cube.
+	numOutlineVertices = 12 * 2
+)
+
+// OutlineColor is the color of the outline around the block the player is
+// looking at: translucent black, like in Minecraft.
+var outlineColor = mgl32.Vec4{0.0, 0.0, 0.0, 0.4}
+
+// BlockOutline draws a translucent black wireframe cube around a block,
+// showing the player which block they're looking at (like the selection box
+// in Minecraft). It can also draw the edges of any other box, in any color
+// (e.g. to show the borders of chunks on the debug screen).
+type BlockOutline struct {
+	vao, vbo uint32
+	program  *ReloadableProgram
+}
+
+// NewBlockOutline allocates the required OpenGL resources for the outline.
+// Returns an error if the outline's shaders fail to load.
+func NewBlockOutline() (*BlockOutline, error) {
+	// Create the program
+	program, err := LoadReloadableShaders(
+		"shaders/outlineVert.glsl",
+		"shaders/outlineFrag.glsl")
+	if err != nil {
+		return nil, err
+	}
+	program.Use()
+
+	// Create the VAO and VBO
+	var vao, vbo uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	unitCube := math.AABB{
+		Center: mgl32.Vec3{0.5, 0.5, 0.5},
+		Size:   mgl32.Vec3{1.0, 1.0, 1.0},
+	}
+	vertices := boxEdges(unitCube)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(&vertices[0]),
+		gl.STATIC_DRAW)
+
+	// Enable the position attribute
+	posAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("position\x00")))
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 3, gl.FLOAT, false, 0, gl.PtrOffset(0))
+
+	return &BlockOutline{vao: vao, vbo: vbo, program: program}, nil
+}
+
+// Destroy releases all the resources allocated by the outline.
+func (o *BlockOutline) Destroy() {
+	o.program.Destroy()
+	gl.DeleteVertexArrays(1, &o.vao)
+	gl.DeleteBuffers(1, &o.vbo)
+}
+
+// BoxEdges builds the vertex data for the 12 edges of a box, as pairs of
+// vertices for `GL_LINES`. Each edge joins two of the box's 8 corners that
+// differ along a single axis.
+func boxEdges(box math.AABB) []float32 {
+	corners := [2]mgl32.Vec3{box.Min(), box.Max()}
+	vertices := make([]float32, 0, numOutlineVertices*3)
+	for axis := 0; axis < 3; axis++ {
+		// Each axis has 4 edges parallel to it, one from each corner of the
+		// face at the start of the axis
+		for corner := 0; corner < 4; corner++ {
+			var start, end mgl32.Vec3
+			start[axis] = corners[0][axis]
+			end[axis] = corners[1][axis]
+			for i, other := range [...]int{(axis + 1) % 3, (axis + 2) % 3} {
+				side := corners[(corner>>i)&1][other]
+				start[other], end[other] = side, side
+			}
+			vertices = append(vertices, start[:]...)
+			vertices = append(vertices, end[:]...)
+		}
+	}
+	return vertices
+}
+
+// OutlineBox returns the world-space box the outline around the block at the
+// given world-space coordinates covers: the block, inflated slightly about its
+// centre.
+func outlineBox(x, y, z int) math.AABB {
+	center := mgl32.Vec3{float32(x), float32(y), float32(z)}
+	center = center.Add(mgl32.Vec3{0.5, 0.5, 0.5})
+	block := math.AABB{Center: center, Size: mgl32.Vec3{1.0, 1.0, 1.0}}
+	return block.Grow(outlineInflation)
+}
+
+// BoxModel returns the model matrix that moves the unit cube outline onto the
+// edges of the given box. Every box is drawn by transforming the same unit
+// cube, so no vertex data has to be uploaded for each box.
+func boxModel(box math.AABB) mgl32.Mat4 {
+	corner, size := box.Min(), box.Size
+	translate := mgl32.Translate3D(corner.X(), corner.Y(), corner.Z())
+	return translate.Mul4(mgl32.Scale3D(size.X(), size.Y(), size.Z()))
+}
+
+// Render draws the outline around the block at the given world-space
+// coordinates. `viewProjection` is the camera's combined projection and view
+// matrix. The outline is depth tested against the world, so it must be drawn
+// after the world.
+func (o *BlockOutline) Render(viewProjection mgl32.Mat4, block [3]int) {
+	box := outlineBox(block[0], block[1], block[2])
+	o.RenderBox(viewProjection, box, outlineColor)
+}
+
+// RenderBox draws the edges of the given world-space box in the given color,
+// which may be translucent. Like `Render`, the lines are depth tested against
+// the world.
+func (o *BlockOutline) RenderBox(viewProjection mgl32.Mat4, box math.AABB,
+	color mgl32.Vec4) {
+	o.program.Use()
+	mvp := viewProjection.Mul4(boxModel(box))
+	gl.UniformMatrix4fv(o.program.Uniform("mvp"), 1, false, &mvp[0])
+	gl.Uniform4f(o.program.Uniform("color"), color.X(), color.Y(), color.Z(),
+		color.W())
+
+	// The outline may be translucent, so it's blended with what's behind it
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.BindVertexArray(o.vao)
+	gl.DrawArrays(gl.LINES, 0, numOutlineVertices)
+
+	// Reset the OpenGL state
+	gl.Disable(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/benanders/mineral/math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestBoxEdges(t *testing.T) {
+	box := math.AABB{Center: mgl32.Vec3{1.0, 2.0, 3.0},
+		Size: mgl32.Vec3{2.0, 4.0, 6.0}}
+	corners := [2]mgl32.Vec3{box.Min(), box.Max()}
+	vertices := boxEdges(box)
+	if len(vertices) != numOutlineVertices*3 {
+		t.Fatalf("got %d values, want %d", len(vertices),
+			numOutlineVertices*3)
+	}
+
+	edges := make(map[[2]mgl32.Vec3]bool)
+	for i := 0; i < len(vertices); i += 6 {
+		start := mgl32.Vec3{vertices[i], vertices[i+1], vertices[i+2]}
+		end := mgl32.Vec3{vertices[i+3], vertices[i+4], vertices[i+5]}
+
+		// Both ends are corners of the box, differing along a single axis
+		differ := 0
+		for axis := 0; axis < 3; axis++ {
+			for _, v := range [...]float32{start[axis], end[axis]} {
+				if v != corners[0][axis] && v != corners[1][axis] {
+					t.Fatalf("edge %v to %v isn't on the box's corners",
+						start, end)
+				}
+			}
+			if start[axis] != end[axis] {
+				differ++
+			}
+		}
+		if differ != 1 {
+			t.Errorf("edge %v to %v doesn't run along one axis", start, end)
+		}
+		edges[[2]mgl32.Vec3{start, end}] = true
+	}
+	if len(edges) != 12 {
+		t.Errorf("got %d distinct edges, want 12", len(edges))
+	}
+}
+
+func TestOutlineTransform(t *testing.T) {
+	blocks := [][3]int{{0, 0, 0}, {5, 70, -3}, {-17, 2, 1000}}
+	for _, block := range blocks {
+		model := boxModel(outlineBox(block[0], block[1], block[2]))
+		transform := func(p mgl32.Vec3) mgl32.Vec3 {
+			return model.Mul4x1(p.Vec4(1.0)).Vec3()
+		}
+
+		// The unit cube's corners land just outside the block's corners, so
+		// the outline doesn't z-fight with the block's faces, and its centre
+		// lands on the block's centre
+		pos := mgl32.Vec3{float32(block[0]), float32(block[1]),
+			float32(block[2])}
+		inflation := mgl32.Vec3{outlineInflation, outlineInflation,
+			outlineInflation}
+		tests := []struct {
+			corner, want mgl32.Vec3
+		}{
+			{mgl32.Vec3{0.0, 0.0, 0.0}, pos.Sub(inflation)},
+			{mgl32.Vec3{1.0, 1.0, 1.0},
+				pos.Add(mgl32.Vec3{1.0, 1.0, 1.0}).Add(inflation)},
+			{mgl32.Vec3{0.5, 0.5, 0.5}, pos.Add(mgl32.Vec3{0.5, 0.5, 0.5})},
+		}
+		for _, test := range tests {
+			got := transform(test.corner)
+			if got.Sub(test.want).Len() > 1e-4 {
+				t.Errorf("block %v: unit cube point %v moved to %v, want %v",
+					block, test.corner, got, test.want)
+			}
+		}
+	}
+}
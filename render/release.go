@@ -0,0 +1,7 @@
+//go:build !debug
+
+package render
+
+// Debug is true in debug builds (built with `-tags debug`), which reload
+// shaders from disk whenever they change.
+const Debug = false
@@ -0,0 +1,297 @@
+package render
+
+import (
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/benanders/mineral/asset"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// The folder, relative to the working directory, from which shaders are
+	// reloaded in debug builds. This is the folder the assets are embedded
+	// from, so edits to the shaders are picked up straight away.
+	shaderSourceDir = "asset/data"
+
+	// The minimum time between checks for changes to a program's shaders.
+	reloadCheckInterval = 500 * time.Millisecond
+)
+
+// ReloadableProgram is a shader program that can be recompiled from its source
+// code while the game is running. In debug builds (built with `-tags debug`),
+// the program is automatically reloaded from disk whenever its shaders change.
+//
+// The program's ID changes when it's reloaded, so the program should always be
+// bound with `Use`, and uniform locations looked up with `Uniform`, rather
+// than holding on to either. Attribute locations and the values of uniforms
+// are carried over to the new program, so VAOs don't need to be recreated.
+type ReloadableProgram struct {
+	ID                       uint32 // The current OpenGL program
+	vertexPath, fragmentPath string
+
+	// Uniform locations in the current program, indexed by name
+	uniforms map[string]int32
+
+	// The time the program's shaders were last modified on disk, and when we
+	// last checked them for changes
+	modified, lastCheck time.Time
+
+	// Reads the source code for a shader, builds a new program from the
+	// source code for its shaders, and looks up the location of a uniform in
+	// a program
+	read          func(path string) ([]byte, error)
+	build         func(vertexSource, fragmentSource string) (uint32, error)
+	lookupUniform func(program uint32, name string) int32
+}
+
+// LoadReloadableShaders creates a new shader program from a vertex and fragment
+// shader, which can be reloaded later on.
+func LoadReloadableShaders(vertexPath,
+	fragmentPath string) (*ReloadableProgram, error) {
+	p := &ReloadableProgram{
+		vertexPath:    vertexPath,
+		fragmentPath:  fragmentPath,
+		uniforms:      make(map[string]int32),
+		read:          asset.Asset,
+		lookupUniform: uniformLocation,
+	}
+	p.build = func(vertexSource, fragmentSource string) (uint32, error) {
+		return buildProgram(p.vertexPath, p.fragmentPath, vertexSource,
+			fragmentSource, p.ID)
+	}
+	if Debug {
+		p.read = readShaderFile
+		p.modified = p.modTime()
+	}
+
+	program, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	p.ID = program
+	return p, nil
+}
+
+// ReadShaderFile reads the source code for a shader from disk, falling back to
+// the embedded asset if the shader can't be found (e.g. if the game isn't run
+// from the root of the repository).
+func readShaderFile(name string) ([]byte, error) {
+	source, err := os.ReadFile(path.Join(shaderSourceDir, name))
+	if err != nil {
+		return asset.Asset(name)
+	}
+	return source, nil
+}
+
+// Load reads the program's shaders and builds a new program from them, without
+// replacing the current program.
+func (p *ReloadableProgram) load() (uint32, error) {
+	vertexSource, fragmentSource, err := readShaderSources(p.read,
+		p.vertexPath, p.fragmentPath)
+	if err != nil {
+		return 0, err
+	}
+	return p.build(vertexSource, fragmentSource)
+}
+
+// Destroy releases the program. Does nothing if the program is nil, so that
+// anything holding a program that failed to load can still be destroyed.
+func (p *ReloadableProgram) Destroy() {
+	if p == nil {
+		return
+	}
+	gl.DeleteProgram(p.ID)
+}
+
+// Use binds the program, first reloading it if it's changed on disk (in debug
+// builds only).
+func (p *ReloadableProgram) Use() {
+	if Debug {
+		p.reloadIfChanged()
+	}
+	gl.UseProgram(p.ID)
+}
+
+// Uniform returns the location of a uniform in the current program, caching
+// the location so it's only looked up once.
+func (p *ReloadableProgram) Uniform(name string) int32 {
+	if location, ok := p.uniforms[name]; ok {
+		return location
+	}
+	location := p.lookupUniform(p.ID, name)
+	p.uniforms[name] = location
+	return location
+}
+
+// UniformLocation looks up the location of a uniform in a program.
+func uniformLocation(program uint32, name string) int32 {
+	return gl.GetUniformLocation(program, gl.Str(name+"\x00"))
+}
+
+// Attrib returns the location of a vertex attribute in the current program.
+// Attribute locations stay the same when the program is reloaded, so they
+// can be held on to (e.g. when setting up a VAO).
+func (p *ReloadableProgram) Attrib(name string) uint32 {
+	return uint32(gl.GetAttribLocation(p.ID, gl.Str(name+"\x00")))
+}
+
+// SetInt sets an integer (or sampler) uniform in the program. Like the other
+// setters, the program must already be bound with `Use`.
+func (p *ReloadableProgram) SetInt(name string, value int32) {
+	gl.Uniform1i(p.Uniform(name), value)
+}
+
+// SetFloat sets a float uniform in the program.
+func (p *ReloadableProgram) SetFloat(name string, value float32) {
+	gl.Uniform1f(p.Uniform(name), value)
+}
+
+// SetVec2 sets a vec2 uniform in the program.
+func (p *ReloadableProgram) SetVec2(name string, value mgl32.Vec2) {
+	gl.Uniform2fv(p.Uniform(name), 1, &value[0])
+}
+
+// SetVec3 sets a vec3 uniform in the program.
+func (p *ReloadableProgram) SetVec3(name string, value mgl32.Vec3) {
+	gl.Uniform3fv(p.Uniform(name), 1, &value[0])
+}
+
+// SetVec4 sets a vec4 uniform in the program.
+func (p *ReloadableProgram) SetVec4(name string, value mgl32.Vec4) {
+	gl.Uniform4fv(p.Uniform(name), 1, &value[0])
+}
+
+// SetMat4 sets a mat4 uniform in the program.
+func (p *ReloadableProgram) SetMat4(name string, value mgl32.Mat4) {
+	gl.UniformMatrix4fv(p.Uniform(name), 1, false, &value[0])
+}
+
+// Reload recompiles and relinks the program from the source code for its
+// shaders, and swaps it in for the current program. The values of all the
+// uniforms in the current program are copied across to the new one.
+//
+// If the new program fails to compile or link, the current program is left
+// in place and the error is returned.
+func (p *ReloadableProgram) Reload() error {
+	program, err := p.load()
+	if err != nil {
+		return err
+	}
+	copyUniforms(p.ID, program)
+	gl.DeleteProgram(p.ID)
+	p.ID = program
+
+	// Uniforms may be at different locations in the new program
+	for name := range p.uniforms {
+		p.uniforms[name] = p.lookupUniform(p.ID, name)
+	}
+	return nil
+}
+
+// ReloadIfChanged reloads the program if any of its shaders have been modified
+// on disk since it was last loaded. Errors are logged, rather than being fatal,
+// so that a mistake in a shader doesn't crash the game.
+func (p *ReloadableProgram) reloadIfChanged() {
+	now := time.Now()
+	if now.Sub(p.lastCheck) < reloadCheckInterval {
+		return
+	}
+	p.lastCheck = now
+
+	modified := p.modTime()
+	if !modified.After(p.modified) {
+		return
+	}
+	p.modified = modified
+	if err := p.Reload(); err != nil {
+		log.Println("failed to reload shaders:", err)
+	} else {
+		log.Printf("reloaded shaders `%v` and `%v`\n", p.vertexPath,
+			p.fragmentPath)
+	}
+}
+
+// ModTime returns the latest time at which either of the program's shaders was
+// modified on disk, or the zero time if neither can be found.
+func (p *ReloadableProgram) modTime() time.Time {
+	var latest time.Time
+	for _, name := range []string{p.vertexPath, p.fragmentPath} {
+		info, err := os.Stat(path.Join(shaderSourceDir, name))
+		if err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// BindAttribLocations gives each attribute in the unlinked program `to` the
+// same location as the attribute with the same name in the program `from`.
+func bindAttribLocations(from, to uint32) {
+	var count int32
+	gl.GetProgramiv(from, gl.ACTIVE_ATTRIBUTES, &count)
+	for i := uint32(0); i < uint32(count); i++ {
+		name, _ := activeVariable(from, i, gl.GetActiveAttrib)
+		location := gl.GetAttribLocation(from, gl.Str(name+"\x00"))
+		if location >= 0 {
+			gl.BindAttribLocation(to, uint32(location), gl.Str(name+"\x00"))
+		}
+	}
+}
+
+// CopyUniforms sets each uniform in the program `to` to the value of the
+// uniform with the same name in the program `from`. Leaves `to` bound.
+func copyUniforms(from, to uint32) {
+	gl.UseProgram(to)
+	var count int32
+	gl.GetProgramiv(from, gl.ACTIVE_UNIFORMS, &count)
+	for i := uint32(0); i < uint32(count); i++ {
+		name, kind := activeVariable(from, i, gl.GetActiveUniform)
+		src := gl.GetUniformLocation(from, gl.Str(name+"\x00"))
+		dst := gl.GetUniformLocation(to, gl.Str(name+"\x00"))
+		if src < 0 || dst < 0 {
+			continue
+		}
+
+		var f [16]float32
+		var n [1]int32
+		switch kind {
+		case gl.FLOAT:
+			gl.GetUniformfv(from, src, &f[0])
+			gl.Uniform1fv(dst, 1, &f[0])
+		case gl.FLOAT_VEC2:
+			gl.GetUniformfv(from, src, &f[0])
+			gl.Uniform2fv(dst, 1, &f[0])
+		case gl.FLOAT_VEC3:
+			gl.GetUniformfv(from, src, &f[0])
+			gl.Uniform3fv(dst, 1, &f[0])
+		case gl.FLOAT_VEC4:
+			gl.GetUniformfv(from, src, &f[0])
+			gl.Uniform4fv(dst, 1, &f[0])
+		case gl.FLOAT_MAT4:
+			gl.GetUniformfv(from, src, &f[0])
+			gl.UniformMatrix4fv(dst, 1, false, &f[0])
+		case gl.INT, gl.BOOL, gl.SAMPLER_2D:
+			gl.GetUniformiv(from, src, &n[0])
+			gl.Uniform1iv(dst, 1, &n[0])
+		}
+	}
+}
+
+// ActiveVariable returns the name and type of the active attribute or uniform
+// at the given index in a program, using `gl.GetActiveAttrib` or
+// `gl.GetActiveUniform`.
+func activeVariable(program, index uint32, get func(program, index uint32,
+	bufSize int32, length *int32, size *int32, xtype *uint32,
+	name *uint8)) (string, uint32) {
+	var length, size int32
+	var xtype uint32
+	buf := make([]uint8, 256)
+	get(program, index, int32(len(buf)), &length, &size, &xtype, &buf[0])
+	return strings.TrimSuffix(string(buf[:length]), "[0]"), xtype
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// TestUniformLookup is a fake uniform lookup, which gives each uniform name a
+// location and counts how many times each name is looked up.
+type testUniformLookup struct {
+	locations map[string]int32
+	lookups   map[string]int
+}
+
+func (l *testUniformLookup) lookup(program uint32, name string) int32 {
+	l.lookups[name]++
+	if location, ok := l.locations[name]; ok {
+		return location
+	}
+	return -1
+}
+
+func TestUniformLocationsAreCached(t *testing.T) {
+	l := testUniformLookup{
+		locations: map[string]int32{"mvp": 0, "tex": 3},
+		lookups:   make(map[string]int),
+	}
+	p := &ReloadableProgram{ID: 1, uniforms: make(map[string]int32),
+		lookupUniform: l.lookup}
+
+	for i := 0; i < 3; i++ {
+		if got := p.Uniform("tex"); got != 3 {
+			t.Errorf("got location %d for tex, want 3", got)
+		}
+		if got := p.Uniform("mvp"); got != 0 {
+			t.Errorf("got location %d for mvp, want 0", got)
+		}
+
+		// Uniforms that don't exist are cached too
+		if got := p.Uniform("missing"); got != -1 {
+			t.Errorf("got location %d for a missing uniform, want -1", got)
+		}
+	}
+	for _, name := range []string{"tex", "mvp", "missing"} {
+		if l.lookups[name] != 1 {
+			t.Errorf("looked up %s %d times, want once", name,
+				l.lookups[name])
+		}
+	}
+}
+
+// TestShaderSources is a fake shader file reader, which returns the source code
+// for each path from a map.
+func testShaderSources(sources map[string]string) func(string) ([]byte,
+	error) {
+	return func(path string) ([]byte, error) {
+		source, ok := sources[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(source), nil
+	}
+}
+
+func TestFailedReloadKeepsProgram(t *testing.T) {
+	errBroken := errors.New("syntax error")
+	var built []string
+	p := &ReloadableProgram{
+		ID:           7,
+		vertexPath:   "vert.glsl",
+		fragmentPath: "frag.glsl",
+		uniforms:     map[string]int32{"mvp": 2},
+		read: testShaderSources(map[string]string{
+			"vert.glsl": "void main() {}",
+			"frag.glsl": "void main() { broken }",
+		}),
+		build: func(vertexSource, fragmentSource string) (uint32, error) {
+			built = append(built, fragmentSource)
+			return 0, errBroken
+		},
+		lookupUniform: func(program uint32, name string) int32 {
+			t.Errorf("looked up %s after a failed reload", name)
+			return -1
+		},
+	}
+
+	// The broken shader is built, and its error returned, but the previous
+	// program and its uniforms are kept
+	if err := p.Reload(); err != errBroken {
+		t.Errorf("got error %v reloading a broken shader, want %v", err,
+			errBroken)
+	}
+	if len(built) != 1 || built[0] != "void main() { broken }" {
+		t.Errorf("built %q, want the broken fragment shader", built)
+	}
+	if p.ID != 7 || p.Uniform("mvp") != 2 {
+		t.Errorf("got program %d with mvp at %d, want program 7 with mvp "+
+			"at 2", p.ID, p.Uniform("mvp"))
+	}
+
+	// Likewise if a shader can't be read at all
+	p.fragmentPath = "missing.glsl"
+	if err := p.Reload(); err == nil {
+		t.Error("got no error reloading a missing shader")
+	}
+	if p.ID != 7 || len(built) != 1 {
+		t.Errorf("got program %d after building %d times, want program 7 "+
+			"built once", p.ID, len(built))
+	}
+}
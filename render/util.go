@@ -13,24 +13,50 @@ import (
 // LoadShaders compiles a vertex and fragment shader from an asset, creates a
 // new OpenGL shader program, attaches the two shaders, and links the program.
 func LoadShaders(vertexPath, fragmentPath string) (uint32, error) {
-	// Get the source code for the shaders
-	vertexSource, err := asset.Asset(vertexPath)
+	vertexSource, fragmentSource, err := readShaderSources(asset.Asset,
+		vertexPath, fragmentPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to load asset `%v`: %v", vertexPath, err)
+		return 0, err
 	}
-	fragmentSource, err := asset.Asset(fragmentPath)
+	return buildProgram(vertexPath, fragmentPath, vertexSource,
+		fragmentSource, 0)
+}
+
+// ReadShaderSources gets the source code for a vertex and fragment shader,
+// using the given function to read each file.
+func readShaderSources(read func(string) ([]byte, error), vertexPath,
+	fragmentPath string) (string, string, error) {
+	vertexSource, err := read(vertexPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load asset `%v`: %v",
+			vertexPath, err)
+	}
+	fragmentSource, err := read(fragmentPath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to load asset `%v`: %v", vertexPath, err)
+		return "", "", fmt.Errorf("failed to load asset `%v`: %v",
+			fragmentPath, err)
 	}
+	return string(vertexSource), string(fragmentSource), nil
+}
 
+// BuildProgram compiles a vertex and fragment shader from their source code,
+// and links them together into a new shader program. The paths are only used
+// in error messages.
+//
+// If `previous` isn't 0, the new program's attributes are given the same
+// locations as in the previous program, so that any VAOs set up for the
+// previous program still work with the new one.
+func buildProgram(vertexPath, fragmentPath, vertexSource,
+	fragmentSource string, previous uint32) (uint32, error) {
 	// Compile the vertex and fragment shaders
-	vertex, err := compileShader(gl.VERTEX_SHADER, string(vertexSource))
+	vertex, err := compileShader(gl.VERTEX_SHADER, vertexSource)
 	if err != nil {
 		return 0, fmt.Errorf("failed to compile vertex shader `%v`: %v",
 			vertexPath, err)
 	}
-	fragment, err := compileShader(gl.FRAGMENT_SHADER, string(fragmentSource))
+	fragment, err := compileShader(gl.FRAGMENT_SHADER, fragmentSource)
 	if err != nil {
+		gl.DeleteShader(vertex)
 		return 0, fmt.Errorf("failed to compile fragment shader `%v`: %v",
 			fragmentPath, err)
 	}
@@ -39,10 +65,14 @@ func LoadShaders(vertexPath, fragmentPath string) (uint32, error) {
 	program := gl.CreateProgram()
 	gl.AttachShader(program, vertex)
 	gl.AttachShader(program, fragment)
+	if previous != 0 {
+		bindAttribLocations(previous, program)
+	}
 
 	// Link the program
 	err = linkProgram(program)
 	if err != nil {
+		gl.DeleteProgram(program)
 		return 0, fmt.Errorf("failed to link program (`%v` and `%v`): %v",
 			vertexPath, fragmentPath, err)
 	}
@@ -128,3 +158,20 @@ func LoadTexture(img *image.RGBA, slot uint32) uint32 {
 
 	return texture
 }
+
+// LoadTextureMipmapped reads texture data from memory and uploads it to a GPU
+// texture for use with OpenGL, generating mipmaps for it.
+//
+// The texture is sampled from its mipmaps when viewed from a distance, which
+// stops it from shimmering, but stays crisp and pixelated up close.
+func LoadTextureMipmapped(img *image.RGBA, slot uint32) uint32 {
+	// Upload the texture as normal, which leaves it bound to the slot
+	texture := LoadTexture(img, slot)
+
+	// Generate the mipmaps, and blend between them when minifying
+	gl.GenerateMipmap(gl.TEXTURE_2D)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER,
+		gl.NEAREST_MIPMAP_LINEAR)
+
+	return texture
+}
@@ -0,0 +1,223 @@
+package sky
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/png" // Sun and moon textures are provided as .png images
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/benanders/mineral/asset"
+	"github.com/benanders/mineral/render"
+)
+
+const (
+	// The OpenGL texture slots into which the sun and moon textures are
+	// loaded. Slot 0 is used by the block atlas.
+	sunTextureSlot  = 1
+	moonTextureSlot = 2
+
+	// The distance of the sun and moon from the camera, and half the width of
+	// each of their quads. The sun and moon are always drawn at the same
+	// distance, so that they look infinitely far away.
+	celestialDistance = 100.0
+	sunHalfSize       = 30.0
+	moonHalfSize      = 20.0
+
+	// The moon texture contains each of the 8 phases of the moon, laid out in
+	// a 4 x 2 grid.
+	moonPhaseColumns = 4
+	moonPhaseRows    = 2
+)
+
+// CelestialBodies stores information about the sun and moon, which are
+// textured quads that rotate across the sky over the course of a day. The moon
+// is always on the opposite side of the sky to the sun.
+type celestialBodies struct {
+	vao, vbo    uint32
+	program     *render.ReloadableProgram
+	sunTexture  uint32
+	moonTexture uint32
+}
+
+// NewCelestialBodies builds the vertex data and allocates the required OpenGL
+// resources for the sun and moon.
+func newCelestialBodies() (celestialBodies, error) {
+	// Load the textures first, so nothing needs cleaning up if they're missing
+	sunImg, err := loadImage("textures/environment/sun.png")
+	if err != nil {
+		return celestialBodies{}, err
+	}
+	moonImg, err := loadImage("textures/environment/moon.png")
+	if err != nil {
+		return celestialBodies{}, err
+	}
+
+	// Create the program
+	program, err := loadShaders(
+		"shaders/celestialVert.glsl",
+		"shaders/celestialFrag.glsl")
+	if err != nil {
+		return celestialBodies{}, err
+	}
+	program.Use()
+
+	// Create the VAO
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+
+	// Create the VBO, holding the sun's quad followed by the moon's quad. Both
+	// sit directly above the camera, facing down towards it, and are rotated
+	// into place when rendering
+	vertices := append(genCelestialQuad(sunHalfSize),
+		genCelestialQuad(moonHalfSize)...)
+	var vbo uint32
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(&vertices[0]),
+		gl.STATIC_DRAW)
+
+	// Enable the position attribute
+	posAttr := program.Attrib("position")
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 3, gl.FLOAT, false, 5*4,
+		gl.PtrOffset(0))
+	// stride = 5*4 = 5 float32s (position, uv) * 4 bytes each
+
+	// Enable the UV attribute
+	uvAttr := program.Attrib("uv")
+	gl.EnableVertexAttribArray(uvAttr)
+	gl.VertexAttribPointer(uvAttr, 2, gl.FLOAT, false, 5*4,
+		gl.PtrOffset(3*4))
+	// stride = 5*4 = 5 float32s (position, uv) * 4 bytes each
+	// offset = 3*4 = 3 float32s (position) * 4 bytes each
+
+	// Upload the textures
+	sunTexture := render.LoadTexture(sunImg, sunTextureSlot)
+	moonTexture := render.LoadTexture(moonImg, moonTextureSlot)
+
+	return celestialBodies{vao, vbo, program, sunTexture, moonTexture}, nil
+}
+
+// GenCelestialQuad builds the vertex data for a square quad of the given half
+// width, sitting directly above the origin at the distance of the sun and
+// moon, facing down towards the origin.
+func genCelestialQuad(halfSize float32) []float32 {
+	// Use the same winding order as the sky plane, so GL_CULL_FACE still
+	// works
+	return []float32{
+		// position.x, position.y, position.z, uv.x, uv.y
+		-halfSize, celestialDistance, -halfSize, 0.0, 0.0,
+		halfSize, celestialDistance, -halfSize, 1.0, 0.0,
+		-halfSize, celestialDistance, halfSize, 0.0, 1.0,
+		halfSize, celestialDistance, halfSize, 1.0, 1.0,
+	}
+}
+
+// LoadImage reads and decodes a .png image from the asset files, ready to be
+// uploaded to the GPU.
+func loadImage(path string) (*image.RGBA, error) {
+	// Get the .png file
+	pngData, err := asset.Asset(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image `%v`", path)
+	}
+
+	// Decode the .png file
+	img, _, err := image.Decode(bytes.NewReader(pngData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode png image `%v`", path)
+	}
+
+	// Convert the image to RGBA, which is the format OpenGL expects
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+// Destroy releases all the resources allocated by the sun and moon.
+func (c *celestialBodies) destroy() {
+	c.program.Destroy()
+	gl.DeleteVertexArrays(1, &c.vao)
+	gl.DeleteBuffers(1, &c.vbo)
+	gl.DeleteTextures(1, &c.sunTexture)
+	gl.DeleteTextures(1, &c.moonTexture)
+}
+
+// GetCelestialRotation returns the rotation that takes the sun from directly
+// overhead to its position in the sky at the given celestial angle. The sun
+// rises along the negative x axis and sets along the positive x axis, in line
+// with the sunrise plane.
+func getCelestialRotation(celestialAngle float32) mgl32.Mat4 {
+	angle := -celestialAngle * 2.0 * math32.Pi
+	return mgl32.HomogRotate3D(angle, mgl32.Vec3{0.0, 0.0, 1.0})
+}
+
+// GetSunDirection returns the unit vector pointing from the camera towards the
+// sun at the given celestial angle.
+func getSunDirection(celestialAngle float32) mgl32.Vec3 {
+	rotation := getCelestialRotation(celestialAngle)
+	return rotation.Mul4x1(mgl32.Vec4{0.0, 1.0, 0.0, 0.0}).Vec3()
+}
+
+// GetMoonDirection returns the unit vector pointing from the camera towards
+// the moon at the given celestial angle, which is always opposite the sun.
+func getMoonDirection(celestialAngle float32) mgl32.Vec3 {
+	return getSunDirection(celestialAngle).Mul(-1.0)
+}
+
+// GetMoonPhase returns the phase of the moon on the given day, between 0 (a
+// full moon) and 7. The moon cycles through each phase over 8 days.
+func getMoonPhase(worldTime float32) int {
+	return int(uint64(worldTime) % (moonPhaseColumns * moonPhaseRows))
+}
+
+// Render draws the sun and moon at their current positions in the sky.
+func (c *celestialBodies) render(info RenderInfo) {
+	// Set the current shader program
+	c.program.Use()
+
+	// The sun and moon are drawn additively, so the black background of their
+	// textures doesn't cover up the sky
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.ONE, gl.ONE)
+
+	// Rotate the sun into place, and use the camera's orientation matrix so
+	// that it always appears at the same distance
+	celestialAngle := getCelestialAngle(info.WorldTime)
+	rotation := getCelestialRotation(celestialAngle)
+	mvp := info.Camera.Orientation.Mul4(rotation)
+	c.program.SetMat4("mvp", mvp)
+
+	// The sun and moon fade away behind the clouds while it's raining
+	c.program.SetFloat("brightness", getRainBrightness(info.Rain))
+
+	// Render the sun using the whole of its texture
+	c.program.SetInt("tex", sunTextureSlot)
+	c.program.SetVec4("uvRect", mgl32.Vec4{0.0, 0.0, 1.0, 1.0})
+	gl.BindVertexArray(c.vao)
+	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
+
+	// The moon is rotated half a turn further, to the opposite side of the sky
+	halfTurn := mgl32.HomogRotate3D(math32.Pi, mgl32.Vec3{0.0, 0.0, 1.0})
+	mvp = mvp.Mul4(halfTurn)
+	c.program.SetMat4("mvp", mvp)
+
+	// Render the moon using the part of its texture for the current phase
+	phase := getMoonPhase(info.WorldTime)
+	w := float32(1.0) / moonPhaseColumns
+	h := float32(1.0) / moonPhaseRows
+	u := float32(phase%moonPhaseColumns) * w
+	v := float32(phase/moonPhaseColumns) * h
+	c.program.SetInt("tex", moonTextureSlot)
+	c.program.SetVec4("uvRect", mgl32.Vec4{u, v, w, h})
+	gl.DrawArrays(gl.TRIANGLE_STRIP, 4, 4)
+
+	// Reset the OpenGL state
+	gl.Disable(gl.BLEND)
+}
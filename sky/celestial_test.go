@@ -0,0 +1,56 @@
+package sky
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestMoonPhaseCyclesEveryEightDays(t *testing.T) {
+	tests := []struct {
+		worldTime float32
+		want      int
+	}{
+		{0.0, 0},
+		{0.9, 0},
+		{1.0, 1},
+		{7.5, 7},
+		{8.0, 0},
+		{8.25, 0},
+		{13.0, 5},
+	}
+	for _, test := range tests {
+		if got := getMoonPhase(test.worldTime); got != test.want {
+			t.Errorf("got moon phase %d at world time %v, want %d", got,
+				test.worldTime, test.want)
+		}
+	}
+}
+
+func TestMoonIsOppositeSun(t *testing.T) {
+	for i := 0; i <= 40; i++ {
+		worldTime := float32(i) / 10.0
+		angle := getCelestialAngle(worldTime)
+		sun := getSunDirection(angle)
+		moon := getMoonDirection(angle)
+		if mgl32.Abs(sun.Len()-1.0) > 1e-5 {
+			t.Errorf("sun direction %v at world time %v isn't a unit vector",
+				sun, worldTime)
+		}
+		if moon.Add(sun).Len() > 1e-5 {
+			t.Errorf("moon direction %v at world time %v isn't opposite the "+
+				"sun direction %v", moon, worldTime, sun)
+		}
+	}
+
+	// The sun is overhead at noon, and the moon at midnight
+	up := mgl32.Vec3{0.0, 1.0, 0.0}
+	sun := getSunDirection(getCelestialAngle(0.25))
+	if sun.Sub(up).Len() > 1e-5 {
+		t.Errorf("sun direction %v at noon, want straight up", sun)
+	}
+	moon := getMoonDirection(getCelestialAngle(0.75))
+	if moon.Sub(up).Len() > 1e-5 {
+		t.Errorf("moon direction %v at midnight, want straight up", moon)
+	}
+}
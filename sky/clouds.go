@@ -0,0 +1,171 @@
+package sky
+
+import (
+	"github.com/chewxy/math32"
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/benanders/mineral/camera"
+	"github.com/benanders/mineral/render"
+)
+
+const (
+	// The OpenGL texture slot into which the cloud texture is loaded.
+	cloudTextureSlot = 3
+
+	// CloudHeight is the y coordinate of the cloud layer, in blocks.
+	cloudHeight = 128.0
+
+	// CloudScrollSpeed is the distance the clouds move along the x axis each
+	// day, in blocks.
+	cloudScrollSpeed = 2400.0
+
+	// The width of the cloud texture, in pixels, and the number of blocks
+	// covered by each pixel. The texture tiles infinitely in every direction.
+	cloudTextureWidth = 256
+	cloudPixelSize    = 12.0
+
+	// The number of blocks covered by a single tile of the cloud texture.
+	cloudTileSize = cloudTextureWidth * cloudPixelSize
+
+	// The horizontal distance from the camera at which the clouds have
+	// completely faded into the fog color, in blocks. This is well inside the
+	// camera's far plane, so the clouds fade out before they're clipped even
+	// when they're far above the camera.
+	cloudRadius = camera.Far * 0.75
+
+	// The fraction of the clouds' brightness that's lost while it's raining
+	// fully, turning them grey.
+	rainCloudDarkening = 0.4
+)
+
+// CloudLayer stores information about the clouds, which are a single flat
+// plane high above the player that scrolls slowly across the sky over time.
+// The plane follows the camera horizontally, but its texture stays fixed in
+// world space (apart from the scrolling), so the clouds appear infinite.
+type cloudLayer struct {
+	vao, vbo uint32
+	program  *render.ReloadableProgram
+	texture  uint32
+}
+
+// NewCloudLayer builds the vertex data and allocates the required OpenGL
+// resources for the cloud layer.
+func newCloudLayer() (cloudLayer, error) {
+	// Load the cloud texture first, so nothing needs cleaning up if it's
+	// missing
+	img, err := loadImage("textures/environment/clouds.png")
+	if err != nil {
+		return cloudLayer{}, err
+	}
+
+	// Create the program
+	program, err := loadShaders(
+		"shaders/cloudVert.glsl",
+		"shaders/cloudFrag.glsl")
+	if err != nil {
+		return cloudLayer{}, err
+	}
+	program.Use()
+
+	// The radius of the cloud layer never changes
+	program.SetFloat("radius", cloudRadius)
+
+	// Create the cloud plane, centred on the origin. It's translated to sit
+	// above the camera when rendering
+	vertices := [...]float32{
+		-cloudRadius, 0.0, -cloudRadius,
+		cloudRadius, 0.0, -cloudRadius,
+		-cloudRadius, 0.0, cloudRadius,
+		cloudRadius, 0.0, cloudRadius,
+	}
+	vao, vbo := genPlane(program, vertices[:])
+
+	// Upload the cloud texture
+	texture := render.LoadTexture(img, cloudTextureSlot)
+
+	return cloudLayer{vao, vbo, program, texture}, nil
+}
+
+// Destroy releases all the resources allocated by the cloud layer.
+func (c *cloudLayer) destroy() {
+	c.program.Destroy()
+	gl.DeleteVertexArrays(1, &c.vao)
+	gl.DeleteBuffers(1, &c.vbo)
+	gl.DeleteTextures(1, &c.texture)
+}
+
+// GetCloudScroll returns the distance the clouds have moved along the x axis
+// at the given world time, in blocks.
+func getCloudScroll(worldTime float32) float32 {
+	return worldTime * cloudScrollSpeed
+}
+
+// GetCloudUVOffset returns the texture coordinates of the cloud texture
+// directly above the camera, which is at `cameraPos`, at the given world time.
+// The coordinates are wrapped to a single tile of the texture, so they don't
+// lose precision far from the origin or as the world time grows.
+func getCloudUVOffset(cameraPos mgl32.Vec3, worldTime float32) mgl32.Vec2 {
+	scroll := wrapUV(getCloudScroll(worldTime) / cloudTileSize)
+	u := wrapUV(cameraPos.X()/cloudTileSize + scroll)
+	v := wrapUV(cameraPos.Z() / cloudTileSize)
+	return mgl32.Vec2{u, v}
+}
+
+// WrapUV wraps a texture coordinate into the range [0, 1).
+func wrapUV(coord float32) float32 {
+	coord -= math32.Floor(coord)
+	if coord >= 1.0 {
+		coord = 0.0 // Tiny negative coordinates round up to 1
+	}
+	return coord
+}
+
+// GetCloudColor returns the color of the clouds, which are white during the
+// day and darken at night, and when it's raining.
+func getCloudColor(celestialAngle, rain float32) color {
+	brightness := getSkyBrightness(celestialAngle)*0.9 + 0.1
+	brightness *= 1.0 - rain*rainCloudDarkening
+	return color{brightness, brightness, brightness}
+}
+
+// Render draws the cloud layer above the camera.
+func (c *cloudLayer) render(info RenderInfo) {
+	// Set the current shader program
+	c.program.Use()
+
+	// The clouds are positioned in world space, using the camera's view matrix
+	c.program.SetMat4("mvp", info.Camera.View)
+
+	// Follow the camera horizontally, but keep the clouds at a fixed height
+	c.program.SetVec3("offset", mgl32.Vec3{info.CameraPos.X(), cloudHeight,
+		info.CameraPos.Z()})
+
+	// Fix the texture in world space, scrolling it across the sky
+	c.program.SetVec2("uvOffset", getCloudUVOffset(info.CameraPos,
+		info.WorldTime))
+	c.program.SetFloat("uvScale", 1.0/cloudTileSize)
+
+	// Set the cloud and fog colors
+	celestialAngle := getCelestialAngle(info.WorldTime)
+	cloudColor := getCloudColor(celestialAngle, info.Rain)
+	c.program.SetVec3("cloudColor", cloudColor.vec3())
+	fogColor := getFogColor(celestialAngle, info.RenderRadius, info.LookDir,
+		info.Rain)
+	c.program.SetVec3("fogColor", fogColor.vec3())
+	c.program.SetInt("tex", cloudTextureSlot)
+
+	// Render the clouds with linear alpha blending, and depth testing so that
+	// they're hidden behind any nearer terrain
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.Enable(gl.DEPTH_TEST)
+
+	// Render the cloud plane
+	gl.BindVertexArray(c.vao)
+	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
+
+	// Reset the OpenGL state
+	gl.Disable(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+}
@@ -0,0 +1,37 @@
+package sky
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCloudUVOffsetIsWrapped(t *testing.T) {
+	positions := []mgl32.Vec3{{0.0, 64.0, 0.0}, {-1.0, 64.0, -1.0},
+		{-100000.0, 64.0, 50000.0}, {1e6, 64.0, -1e6}}
+	for _, pos := range positions {
+		uv := getCloudUVOffset(pos, 12345.6)
+		for _, coord := range uv {
+			if coord < 0.0 || coord >= 1.0 {
+				t.Errorf("got UV offset %v at %v, want within [0, 1)", uv, pos)
+			}
+		}
+	}
+}
+
+func TestCloudUVOffsetTiles(t *testing.T) {
+	// Moving the camera by a whole tile shows the same part of the texture
+	a := getCloudUVOffset(mgl32.Vec3{100.0, 64.0, 200.0}, 0.0)
+	b := getCloudUVOffset(mgl32.Vec3{100.0 + cloudTileSize, 64.0,
+		200.0 - cloudTileSize}, 0.0)
+	if !a.ApproxEqualThreshold(b, 1e-4) {
+		t.Errorf("got UV offset %v, want %v", b, a)
+	}
+
+	// Moving the camera half a tile moves the texture by half
+	c := getCloudUVOffset(mgl32.Vec3{100.0 + cloudTileSize/2.0, 64.0, 200.0},
+		0.0)
+	if got := wrapUV(c.X() - a.X()); mgl32.Abs(got-0.5) > 1e-4 {
+		t.Errorf("moving half a tile moved the texture by %v, want 0.5", got)
+	}
+}
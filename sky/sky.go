@@ -1,8 +1,6 @@
 package sky
 
 import (
-	"log"
-
 	"github.com/chewxy/math32"
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
@@ -18,10 +16,26 @@ import (
 // future.
 const worldTemperature float32 = 0.5
 
+// How much rain darkens the sky, as the fraction of the way the sky color is
+// moved towards a dark grey while it's raining fully.
+const rainSkyDarkening = 0.75
+
+// How much rain darkens the fog color while it's raining fully, as the
+// fraction of the red and green, and of the blue, that's removed. Blue is
+// darkened a little less, so the fog takes on a slightly blue-grey tint.
+const (
+	rainFogDarkening     = 0.5
+	rainFogBlueDarkening = 0.4
+)
+
 // Sky is responsible for drawing the background sky in the game.
 type Sky struct {
-	skyPlane     skyPlane
-	sunrisePlane sunrisePlane
+	skyPlane        skyPlane
+	voidPlane       voidPlane
+	sunrisePlane    sunrisePlane
+	celestialBodies celestialBodies
+	starField       starField
+	cloudLayer      cloudLayer
 }
 
 // RenderInfo stores a bunch of information required by the sky renderer in
@@ -29,59 +43,86 @@ type Sky struct {
 type RenderInfo struct {
 	WorldTime    float32
 	Camera       *camera.Camera
+	CameraPos    mgl32.Vec3
 	RenderRadius int
 	LookDir      mgl32.Vec3
+
+	// How heavily it's raining, between 0 and 1. Rain darkens the sky and
+	// hides the sun, moon, and stars
+	Rain float32
 }
 
-// SkyPlane stores information about the blue ceiling plane and the dark blue
-// void plane present in the sky.
+// SkyPlane stores information about the blue ceiling plane present in the sky.
 type skyPlane struct {
-	skyVao, skyVbo   uint32
-	voidVao, voidVbo uint32
-	program          uint32
-	mvpUnf           int32
-	colorUnf         int32
-	fogColorUnf      int32
-	farPlaneUnf      int32
+	vao, vbo uint32
+	program  *render.ReloadableProgram
 }
 
 // SunrisePlane stores information about the red/orange sunrise/sunset plane
 // present in the sky during sunrise and sunset.
 type sunrisePlane struct {
-	vao, vbo        uint32
-	program         uint32
-	mvpUnf          int32
-	sunriseColorUnf int32
+	vao, vbo uint32
+	program  *render.ReloadableProgram
 }
 
-// New creates a new sky renderer instance.
-func New() *Sky {
-	return &Sky{newSkyPlane(), newSunrisePlane()}
+// LoadShaders loads the shader program for each part of the sky. Tests replace
+// it to check that shaders failing to load are handled.
+var loadShaders = render.LoadReloadableShaders
+
+// New creates a new sky renderer instance. Returns an error if any of the sky's
+// shaders or textures fail to load.
+func New() (*Sky, error) {
+	s := &Sky{}
+	var err error
+	if s.skyPlane, err = newSkyPlane(); err != nil {
+		return nil, err
+	}
+	if s.voidPlane, err = newVoidPlane(); err != nil {
+		s.Destroy()
+		return nil, err
+	}
+	if s.sunrisePlane, err = newSunrisePlane(); err != nil {
+		s.Destroy()
+		return nil, err
+	}
+	if s.celestialBodies, err = newCelestialBodies(); err != nil {
+		s.Destroy()
+		return nil, err
+	}
+	if s.starField, err = newStarField(); err != nil {
+		s.Destroy()
+		return nil, err
+	}
+	if s.cloudLayer, err = newCloudLayer(); err != nil {
+		s.Destroy()
+		return nil, err
+	}
+	return s, nil
 }
 
-// Destroy releases all the resources allocated by the sky renderer.
+// Destroy releases all the resources allocated by the sky renderer. Parts of
+// the sky that haven't been created yet hold no resources (OpenGL ignores
+// deleting an ID of 0), so this is safe to call on a partly created sky.
 func (s *Sky) Destroy() {
 	s.skyPlane.destroy()
+	s.voidPlane.destroy()
 	s.sunrisePlane.destroy()
+	s.celestialBodies.destroy()
+	s.starField.destroy()
+	s.cloudLayer.destroy()
 }
 
 // NewSkyPlane builds the vertex data and allocates the required OpenGL
 // resources for the sky plane.
-func newSkyPlane() skyPlane {
+func newSkyPlane() (skyPlane, error) {
 	// Create the program
-	program, err := render.LoadShaders(
+	program, err := loadShaders(
 		"shaders/skyVert.glsl",
 		"shaders/skyFrag.glsl")
 	if err != nil {
-		log.Fatalln(err)
+		return skyPlane{}, err
 	}
-	gl.UseProgram(program)
-
-	// Cache the locations of uniforms
-	mvpUnf := gl.GetUniformLocation(program, gl.Str("mvp\x00"))
-	colorUnf := gl.GetUniformLocation(program, gl.Str("skyColor\x00"))
-	fogColorUnf := gl.GetUniformLocation(program, gl.Str("fogColor\x00"))
-	farPlaneUnf := gl.GetUniformLocation(program, gl.Str("farPlane\x00"))
+	program.Use()
 
 	// Create the sky plane
 	skyVertices := [...]float32{
@@ -90,25 +131,14 @@ func newSkyPlane() skyPlane {
 		-384.0, 16.0, 384.0, // sky will look noticeably square.
 		384.0, 16.0, 384.0,
 	}
-	skyVao, skyVbo := genPlane(program, skyVertices[:])
-
-	// Create the void plane
-	voidVertices := [...]float32{
-		-384.0, -16.0, -384.0, // Swap the winding order from the sky plane so
-		-384.0, -16.0, 384.0, // GL_CULL_FACE still works.
-		384.0, -16.0, -384.0,
-		384.0, -16.0, 384.0,
-	}
-	voidVao, voidVbo := genPlane(program, voidVertices[:])
-
-	// Create the object holding it all together
-	return skyPlane{skyVao, skyVbo, voidVao, voidVbo, program, mvpUnf,
-		colorUnf, fogColorUnf, farPlaneUnf}
+	vao, vbo := genPlane(program, skyVertices[:])
+	return skyPlane{vao, vbo, program}, nil
 }
 
 // Generates the sky or void plane VAO and VBO, and enables the vertex
 // attributes.
-func genPlane(program uint32, vertices []float32) (vao, vbo uint32) {
+func genPlane(program *render.ReloadableProgram,
+	vertices []float32) (vao, vbo uint32) {
 	// Create the VAO
 	gl.GenVertexArrays(1, &vao)
 	gl.BindVertexArray(vao)
@@ -120,7 +150,7 @@ func genPlane(program uint32, vertices []float32) (vao, vbo uint32) {
 		gl.STATIC_DRAW)
 
 	// Enable the position attribute
-	posAttr := uint32(gl.GetAttribLocation(program, gl.Str("position\x00")))
+	posAttr := program.Attrib("position")
 	gl.EnableVertexAttribArray(posAttr)
 	gl.VertexAttribPointer(posAttr, 3, gl.FLOAT, false, 0, gl.PtrOffset(0))
 	return
@@ -128,28 +158,22 @@ func genPlane(program uint32, vertices []float32) (vao, vbo uint32) {
 
 // Destroy releases all the resources allocated by the sky plane.
 func (p *skyPlane) destroy() {
-	gl.DeleteProgram(p.program)
-	gl.DeleteVertexArrays(1, &p.skyVao)
-	gl.DeleteVertexArrays(1, &p.voidVao)
-	gl.DeleteBuffers(1, &p.skyVbo)
-	gl.DeleteBuffers(1, &p.voidVbo)
+	p.program.Destroy()
+	gl.DeleteVertexArrays(1, &p.vao)
+	gl.DeleteBuffers(1, &p.vbo)
 }
 
 // NewSunrisePlane builds the vertex data and allocates the required OpenGL
 // resources for the sunrise plane.
-func newSunrisePlane() sunrisePlane {
+func newSunrisePlane() (sunrisePlane, error) {
 	// Create the program
-	program, err := render.LoadShaders(
+	program, err := loadShaders(
 		"shaders/sunriseVert.glsl",
 		"shaders/sunriseFrag.glsl")
 	if err != nil {
-		log.Fatalln(err)
+		return sunrisePlane{}, err
 	}
-	gl.UseProgram(program)
-
-	// Cache uniform locations
-	mvpUnf := gl.GetUniformLocation(program, gl.Str("mvp\x00"))
-	colorUnf := gl.GetUniformLocation(program, gl.Str("sunriseColor\x00"))
+	program.Use()
 
 	// Create the VAO
 	var vao uint32
@@ -164,21 +188,21 @@ func newSunrisePlane() sunrisePlane {
 	gl.BufferData(gl.ARRAY_BUFFER, 4*18*4, gl.Ptr(&vertices[0]), gl.STATIC_DRAW)
 
 	// Enable the position attribute
-	posAttr := uint32(gl.GetAttribLocation(program, gl.Str("position\x00")))
+	posAttr := program.Attrib("position")
 	gl.EnableVertexAttribArray(posAttr)
 	gl.VertexAttribPointer(posAttr, 3, gl.FLOAT, false, 4*4,
 		gl.PtrOffset(0))
 	// stride = 4*4 = 4 float32s (position, alpha multiplier) * 4 bytes each
 
 	// Enable the alpha multiplier attribute
-	alphaAttr := uint32(gl.GetAttribLocation(program, gl.Str("alpha\x00")))
+	alphaAttr := program.Attrib("alpha")
 	gl.EnableVertexAttribArray(alphaAttr)
 	gl.VertexAttribPointer(alphaAttr, 1, gl.FLOAT, false, 4*4,
 		gl.PtrOffset(3*4))
 	// stride = 4*4 = 4 float32s (position, alpha multiplier) * 4 bytes each
 	// offset = 3*4 = 3 float32s (position) * 4 bytes each
 
-	return sunrisePlane{vao, vbo, program, mvpUnf, colorUnf}
+	return sunrisePlane{vao, vbo, program}, nil
 }
 
 // GenSunrisePlaneVertices builds the vertex data array for the sunrise plane.
@@ -207,7 +231,7 @@ func genSunrisePlaneVertices() []float32 {
 
 // Destroy releases all the resources allocated by the sunrise plane.
 func (p *sunrisePlane) destroy() {
-	gl.DeleteProgram(p.program)
+	p.program.Destroy()
 	gl.DeleteVertexArrays(1, &p.vao)
 	gl.DeleteBuffers(1, &p.vbo)
 }
@@ -217,6 +241,11 @@ type color struct {
 	r, g, b float32
 }
 
+// Vec3 returns the color as a vector, for passing to a shader.
+func (c color) vec3() mgl32.Vec3 {
+	return mgl32.Vec3{c.r, c.g, c.b}
+}
+
 // HsvToRgb converts a color from HSV color space to RGB color space.
 func hsvToRgb(h, s, v float32) color {
 	option := int(h*6.0) % 6
@@ -265,9 +294,24 @@ func getCelestialAngle(worldTime float32) float32 {
 	return dayProgress + (celestialAngle-dayProgress)/3.0
 }
 
+// GetSkyBrightness returns the brightness multiplier for the sky and fog
+// colors, which is 1 during the day and 0 at night.
+func getSkyBrightness(celestialAngle float32) float32 {
+	brightness := math32.Cos(celestialAngle*math32.Pi*2.0)*2.0 + 0.5
+	return math.Clamp(brightness, 0.0, 1.0)
+}
+
+// GetRainBrightness returns the brightness multiplier for the sun, moon, and
+// stars, which are hidden behind the clouds when it's raining. `rain` is how
+// heavily it's raining, between 0 and 1.
+func getRainBrightness(rain float32) float32 {
+	return 1.0 - rain
+}
+
 // GetSkyColor returns the color used for the sky plane, and is normally a
-// slightly darker blue than the fog color.
-func getSkyColor(celestialAngle float32) color {
+// slightly darker blue than the fog color. When it's raining (`rain` is
+// between 0 and 1), the sky turns a darker grey.
+func getSkyColor(celestialAngle, rain float32) color {
 	// Calculate the base color based on the temperature
 	temperature := math.Clamp(worldTemperature/3.0, -1.0, 1.0)
 	base := hsvToRgb(
@@ -276,22 +320,30 @@ func getSkyColor(celestialAngle float32) color {
 		1.0)
 
 	// Calculate the brightness multiplier
-	brightness := math32.Cos(celestialAngle*math32.Pi*2.0)*2.0 + 0.5
-	brightness = math.Clamp(brightness, 0.0, 1.0)
+	brightness := getSkyBrightness(celestialAngle)
 
 	// Calculate the final color
-	return color{
+	sky := color{
 		base.r * brightness,
 		base.g * brightness,
 		base.b * brightness,
 	}
+
+	// Move the color towards a grey with a similar (but darker) brightness
+	// while it's raining
+	grey := (sky.r*0.3 + sky.g*0.59 + sky.b*0.11) * 0.6
+	darkening := rain * rainSkyDarkening
+	sky.r = math.Lerp(sky.r, grey, darkening)
+	sky.g = math.Lerp(sky.g, grey, darkening)
+	sky.b = math.Lerp(sky.b, grey, darkening)
+	return sky
 }
 
 // GetVoidColor returns the color used for the void plane, and is normally a
 // deeper blue than the sky color.
-func getVoidColor(celestialAngle float32) color {
+func getVoidColor(celestialAngle, rain float32) color {
 	// Calculate the void plane color based off the sky color
-	skyColor := getSkyColor(celestialAngle)
+	skyColor := getSkyColor(celestialAngle, rain)
 	return color{
 		skyColor.r*0.2 + 0.04,
 		skyColor.g*0.2 + 0.04,
@@ -319,12 +371,11 @@ func getSunriseColor(celestialAngle float32) (color, float32) {
 }
 
 // GetFogColor returns the background fog color, including the influence of
-// looking towards the sun during sunrise or sunset.
+// looking towards the sun during sunrise or sunset, and of rain.
 func getFogColor(celestialAngle float32, renderRadius int,
-	lookDir mgl32.Vec3) color {
+	lookDir mgl32.Vec3, rain float32) color {
 	// Calculate the brightness multiplier
-	brightness := math32.Cos(celestialAngle*math32.Pi*2.0)*2.0 + 0.5
-	brightness = math.Clamp(brightness, 0.0, 1.0)
+	brightness := getSkyBrightness(celestialAngle)
 
 	// Calculate the fog color using some magic numbers
 	fogColor := color{
@@ -360,20 +411,35 @@ func getFogColor(celestialAngle float32, renderRadius int,
 	}
 
 	// Modify the fog color with the sky color based on the render radius
-	sky := getSkyColor(celestialAngle)
+	sky := getSkyColor(celestialAngle, rain)
 	fractionalRadius := float32(renderRadius) / float32(world.MaxRenderRadius)
 	sightFactor := 1.0 - math32.Pow(fractionalRadius*0.75+0.25, 0.25)
 	fogColor.r += (sky.r - fogColor.r) * sightFactor
 	fogColor.g += (sky.g - fogColor.g) * sightFactor
 	fogColor.b += (sky.b - fogColor.b) * sightFactor
+
+	// Darken the fog while it's raining
+	fogColor.r *= 1.0 - rain*rainFogDarkening
+	fogColor.g *= 1.0 - rain*rainFogDarkening
+	fogColor.b *= 1.0 - rain*rainFogBlueDarkening
 	return fogColor
 }
 
+// FogColor returns the current fog color, which the sky fades into near the
+// horizon.
+func FogColor(info RenderInfo) mgl32.Vec3 {
+	celestialAngle := getCelestialAngle(info.WorldTime)
+	fog := getFogColor(celestialAngle, info.RenderRadius, info.LookDir,
+		info.Rain)
+	return mgl32.Vec3{fog.r, fog.g, fog.b}
+}
+
 // RenderBackground clears the screen to the current fog color.
 func (s *Sky) renderBackground(info RenderInfo) {
 	// Get the current fog color
 	celestialAngle := getCelestialAngle(info.WorldTime)
-	fogColor := getFogColor(celestialAngle, info.RenderRadius, info.LookDir)
+	fogColor := getFogColor(celestialAngle, info.RenderRadius, info.LookDir,
+		info.Rain)
 
 	// Clear the screen
 	gl.ClearColor(fogColor.r, fogColor.g, fogColor.b, 1.0)
@@ -383,41 +449,28 @@ func (s *Sky) renderBackground(info RenderInfo) {
 // RenderSky draws the sky plane using the current sky and fog colors, at a
 // fixed distance from the player (so that the sky always looks infinitely far
 // away).
-func (p *skyPlane) renderSky(info RenderInfo) {
+func (p *skyPlane) render(info RenderInfo) {
 	// Set the current shader program to the sky plane program
-	gl.UseProgram(p.program)
+	p.program.Use()
 
 	// Set the shader's MVP uniform to the camera's orientation matrix
-	gl.UniformMatrix4fv(p.mvpUnf, 1, false, &info.Camera.Orientation[0])
+	p.program.SetMat4("mvp", info.Camera.Orientation)
 
 	// Set the color of the sky plane to the sky color
 	celestialAngle := getCelestialAngle(info.WorldTime)
-	skyColor := getSkyColor(celestialAngle)
-	gl.Uniform3f(p.colorUnf, skyColor.r, skyColor.g, skyColor.b)
+	skyColor := getSkyColor(celestialAngle, info.Rain)
+	p.program.SetVec3("skyColor", skyColor.vec3())
 
 	// Set the fog color uniform
-	fogColor := getFogColor(celestialAngle, info.RenderRadius, info.LookDir)
-	gl.Uniform3f(p.fogColorUnf, fogColor.r, fogColor.g, fogColor.b)
+	fogColor := getFogColor(celestialAngle, info.RenderRadius, info.LookDir,
+		info.Rain)
+	p.program.SetVec3("fogColor", fogColor.vec3())
 
 	// Set the far plane distance, used for fog calculations
-	gl.Uniform1f(p.farPlaneUnf, info.Camera.FarPlane)
+	p.program.SetFloat("farPlane", info.Camera.FarPlane)
 
 	// Render the sky plane
-	gl.BindVertexArray(p.skyVao)
-	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
-}
-
-// RenderSky draws the void plane using the current void and fog colors, at a
-// fixed distance from the player.
-func (p *skyPlane) renderVoid(info RenderInfo) {
-	// Only change the sky color uniform from rendering the sky plane above,
-	// to the void color
-	celestialAngle := getCelestialAngle(info.WorldTime)
-	voidColor := getVoidColor(celestialAngle)
-	gl.Uniform3f(p.colorUnf, voidColor.r, voidColor.g, voidColor.b)
-
-	// Render the sky plane
-	gl.BindVertexArray(p.voidVao)
+	gl.BindVertexArray(p.vao)
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
 }
 
@@ -425,7 +478,7 @@ func (p *skyPlane) renderVoid(info RenderInfo) {
 // colors.
 func (p *sunrisePlane) render(info RenderInfo) {
 	// Set the current shader program to the sunrise plane program
-	gl.UseProgram(p.program)
+	p.program.Use()
 
 	// Calculate a rotation matrix based on whether it's currently sunrise or
 	// sunset, to change where the sunrise plane appears in the sky
@@ -442,11 +495,11 @@ func (p *sunrisePlane) render(info RenderInfo) {
 	xRot := mgl32.HomogRotate3D(math32.Pi/2.0, mgl32.Vec3{1.0, 0.0, 0.0})
 	zRot := mgl32.HomogRotate3D(math32.Pi/2.0, mgl32.Vec3{0.0, 0.0, 1.0})
 	mvp := info.Camera.Orientation.Mul4(xRot.Mul4(todRot.Mul4(zRot)))
-	gl.UniformMatrix4fv(p.mvpUnf, 1, false, &mvp[0])
+	p.program.SetMat4("mvp", mvp)
 
 	// Set the sunrise color uniform
 	color, alpha := getSunriseColor(celestialAngle)
-	gl.Uniform4f(p.sunriseColorUnf, color.r, color.g, color.b, alpha)
+	p.program.SetVec4("sunriseColor", color.vec3().Vec4(alpha))
 
 	// Render the sunrise plane with linear alpha blending enabled
 	gl.Enable(gl.BLEND)
@@ -469,10 +522,22 @@ func (s *Sky) Render(info RenderInfo) {
 
 	// Render components of the sky separately
 	s.renderBackground(info)
-	s.skyPlane.renderSky(info)
-	s.skyPlane.renderVoid(info)
+	s.skyPlane.render(info)
 	s.sunrisePlane.render(info)
+	s.celestialBodies.render(info)
+	s.starField.render(info)
+
+	// The void plane is rendered last, so it covers up the sun, moon, and
+	// stars once they've set below the horizon
+	s.voidPlane.render(info)
 
 	// Reset the OpenGL configuration
 	gl.Disable(gl.CULL_FACE)
 }
+
+// RenderClouds draws the cloud layer. Unlike the rest of the sky, the clouds
+// are depth tested against the world, so they should be rendered after the
+// world.
+func (s *Sky) RenderClouds(info RenderInfo) {
+	s.cloudLayer.render(info)
+}
@@ -0,0 +1,156 @@
+package sky
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/benanders/mineral/render"
+
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestNewWithFailedShaders(t *testing.T) {
+	load := loadShaders
+	defer func() { loadShaders = load }()
+	loadShaders = func(vertexPath,
+		fragmentPath string) (*render.ReloadableProgram, error) {
+		return nil, errors.New("shader failed to compile")
+	}
+
+	s, err := New()
+	if err == nil || s != nil {
+		t.Error("created sky with shaders that failed to load")
+	}
+}
+
+// Blueness returns the fraction of a color's brightness that comes from its
+// blue component.
+func blueness(c color) float32 {
+	return c.b / (c.r + c.g + c.b)
+}
+
+func TestVoidIsDeeperBlueThanSky(t *testing.T) {
+	// Celestial angles through the day (0 is noon), leaving out the night
+	// when the sky is black
+	for _, angle := range []float32{0.0, 0.1, 0.2, 0.8, 0.9} {
+		sky := getSkyColor(angle, 0.0)
+		void := getVoidColor(angle, 0.0)
+		if void.r+void.g+void.b >= sky.r+sky.g+sky.b {
+			t.Errorf("void %v is no darker than sky %v at angle %v", void,
+				sky, angle)
+		}
+		if blueness(void) <= blueness(sky) {
+			t.Errorf("void %v is no bluer than sky %v at angle %v", void, sky,
+				angle)
+		}
+	}
+}
+
+func TestCelestialAngle(t *testing.T) {
+	tests := []struct {
+		worldTime float32
+		want      float32
+	}{
+		{0.25, 0.0}, // Noon
+		{0.75, 0.5}, // Midnight
+		{3.25, 0.0},
+		{3.75, 0.5},
+	}
+	for _, test := range tests {
+		got := getCelestialAngle(test.worldTime)
+		if mgl32.Abs(got-test.want) > 1e-5 {
+			t.Errorf("got celestial angle %v at world time %v, want %v", got,
+				test.worldTime, test.want)
+		}
+	}
+}
+
+func TestSkyBrightness(t *testing.T) {
+	noon := getCelestialAngle(0.25)
+	midnight := getCelestialAngle(0.75)
+	if got := getSkyBrightness(noon); got != 1.0 {
+		t.Errorf("got sky brightness %v at noon, want 1", got)
+	}
+	if got := getSkyBrightness(midnight); got != 0.0 {
+		t.Errorf("got sky brightness %v at midnight, want 0", got)
+	}
+	if getSkyColor(midnight, 0.0) != (color{}) {
+		t.Error("sky isn't black at midnight")
+	}
+}
+
+func TestSunriseColorOnlyAtSunriseAndSunset(t *testing.T) {
+	for _, worldTime := range []float32{0.25, 0.75} {
+		_, alpha := getSunriseColor(getCelestialAngle(worldTime))
+		if alpha != 0.0 {
+			t.Errorf("got sunrise alpha %v at world time %v, want 0", alpha,
+				worldTime)
+		}
+	}
+	for _, worldTime := range []float32{0.0, 0.5} {
+		_, alpha := getSunriseColor(getCelestialAngle(worldTime))
+		if alpha <= 0.0 {
+			t.Errorf("no sunrise color at world time %v", worldTime)
+		}
+	}
+}
+
+func TestFogColorTowardsSunrise(t *testing.T) {
+	// The sun rises and sets along the x axis
+	sunrise := getCelestialAngle(0.0)
+	sinAngle := math32.Sin(sunrise * math32.Pi * 2.0)
+	sunDir := mgl32.Vec3{1.0, 0.0, 0.0}
+	if sinAngle < 0.0 {
+		sunDir = sunDir.Mul(-1.0)
+	}
+
+	// Looking towards the sunrise turns the fog orange
+	towards := getFogColor(sunrise, 8, sunDir, 0.0)
+	away := getFogColor(sunrise, 8, sunDir.Mul(-1.0), 0.0)
+	if towards.r <= away.r || towards.b >= away.b {
+		t.Errorf("fog %v towards sunrise isn't more orange than %v away",
+			towards, away)
+	}
+
+	// Only when the render radius is large enough for the horizon to show
+	towards = getFogColor(sunrise, 2, sunDir, 0.0)
+	away = getFogColor(sunrise, 2, sunDir.Mul(-1.0), 0.0)
+	if towards != away {
+		t.Error("fog changed with look direction at a small render radius")
+	}
+}
+
+// Brightness returns the sum of a color's components.
+func brightness(c color) float32 {
+	return c.r + c.g + c.b
+}
+
+func TestRainDarkensSky(t *testing.T) {
+	for _, angle := range []float32{0.0, 0.1, 0.9} {
+		// The sky and fog get darker the heavier it rains
+		lastSky, lastFog := float32(4.0), float32(4.0)
+		for _, rain := range []float32{0.0, 0.5, 1.0} {
+			sky := brightness(getSkyColor(angle, rain))
+			fog := brightness(getFogColor(angle, 8, mgl32.Vec3{0.0, 0.0, -1.0},
+				rain))
+			if sky >= lastSky || fog >= lastFog {
+				t.Errorf("sky %v and fog %v with rain %v at angle %v aren't "+
+					"darker than with less rain", sky, fog, rain, angle)
+			}
+			lastSky, lastFog = sky, fog
+		}
+
+		// Rain also turns the sky grey
+		clear, rain := getSkyColor(angle, 0.0), getSkyColor(angle, 1.0)
+		if rain.b-rain.r >= clear.b-clear.r {
+			t.Errorf("sky %v with rain at angle %v isn't greyer than %v",
+				rain, angle, clear)
+		}
+	}
+
+	// The sun, moon, and stars disappear behind the clouds
+	if got := getRainBrightness(1.0); got != 0.0 {
+		t.Errorf("got brightness %v in full rain, want 0", got)
+	}
+}
@@ -0,0 +1,140 @@
+package sky
+
+import (
+	"math/rand"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"github.com/benanders/mineral/render"
+)
+
+const (
+	// The number of stars in the sky.
+	numStars = 500
+
+	// The seed used to position the stars, so that they're in the same place
+	// every time the game is run.
+	starSeed = 10842
+
+	// The distance of the stars from the camera. Stars are always drawn at the
+	// same distance, so that they look infinitely far away.
+	starDistance = 100.0
+
+	// The size of each star, in pixels.
+	starSize = 2.0
+)
+
+// StarField stores information about the stars, which are points spread
+// randomly across the sky that rotate along with the sun and moon, and fade
+// in as the sky gets darker at night.
+type starField struct {
+	vao, vbo    uint32
+	program     *render.ReloadableProgram
+	numVertices int32
+}
+
+// NewStarField builds the vertex data and allocates the required OpenGL
+// resources for the stars.
+func newStarField() (starField, error) {
+	// Create the program
+	program, err := loadShaders(
+		"shaders/starVert.glsl",
+		"shaders/starFrag.glsl")
+	if err != nil {
+		return starField{}, err
+	}
+	program.Use()
+
+	// Create the VAO
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+
+	// Create the VBO and populate it with data
+	vertices := genStarVertices()
+	var vbo uint32
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(&vertices[0]),
+		gl.STATIC_DRAW)
+
+	// Enable the position attribute
+	posAttr := program.Attrib("position")
+	gl.EnableVertexAttribArray(posAttr)
+	gl.VertexAttribPointer(posAttr, 3, gl.FLOAT, false, 0, gl.PtrOffset(0))
+
+	return starField{vao, vbo, program, int32(len(vertices) / 3)}, nil
+}
+
+// GenStarVertices builds the vertex data array for the stars, with one point
+// per star, spread evenly over a sphere around the origin.
+func genStarVertices() []float32 {
+	random := rand.New(rand.NewSource(starSeed))
+	vertices := make([]float32, 0, numStars*3)
+	for len(vertices) < numStars*3 {
+		// Pick a random point within the unit sphere, discarding points outside
+		// it (which would bunch up towards the corners of the cube once
+		// projected onto the sphere) and points too close to the centre to
+		// normalise accurately
+		point := mgl32.Vec3{
+			random.Float32()*2.0 - 1.0,
+			random.Float32()*2.0 - 1.0,
+			random.Float32()*2.0 - 1.0,
+		}
+		if length := point.Len(); length > 1.0 || length < 0.01 {
+			continue
+		}
+
+		// Project the point onto the sphere
+		point = point.Normalize().Mul(starDistance)
+		vertices = append(vertices, point.X(), point.Y(), point.Z())
+	}
+	return vertices
+}
+
+// Destroy releases all the resources allocated by the stars.
+func (s *starField) destroy() {
+	s.program.Destroy()
+	gl.DeleteVertexArrays(1, &s.vao)
+	gl.DeleteBuffers(1, &s.vbo)
+}
+
+// GetStarAlpha returns the opacity of the stars, which are invisible during the
+// day and fully visible at night. Stars fade in as the sky darkens, and are
+// hidden while it's raining.
+func getStarAlpha(celestialAngle, rain float32) float32 {
+	return (1.0 - getSkyBrightness(celestialAngle)) * getRainBrightness(rain)
+}
+
+// Render draws the stars at their current positions in the sky.
+func (s *starField) render(info RenderInfo) {
+	// Don't bother rendering the stars during the day
+	celestialAngle := getCelestialAngle(info.WorldTime)
+	alpha := getStarAlpha(celestialAngle, info.Rain)
+	if alpha <= 0.0 {
+		return
+	}
+
+	// Set the current shader program
+	s.program.Use()
+
+	// Rotate the stars along with the sun and moon, and use the camera's
+	// orientation matrix so that they always appear at the same distance
+	rotation := getCelestialRotation(celestialAngle)
+	mvp := info.Camera.Orientation.Mul4(rotation)
+	s.program.SetMat4("mvp", mvp)
+	s.program.SetFloat("alpha", alpha)
+
+	// Render the stars additively on top of the sky
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE)
+	gl.PointSize(starSize)
+
+	// Render the stars
+	gl.BindVertexArray(s.vao)
+	gl.DrawArrays(gl.POINTS, 0, s.numVertices)
+
+	// Reset the OpenGL state
+	gl.Disable(gl.BLEND)
+}
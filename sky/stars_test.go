@@ -0,0 +1,43 @@
+package sky
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestStarAlpha(t *testing.T) {
+	// Stars are invisible at noon and fully visible at midnight
+	if got := getStarAlpha(getCelestialAngle(0.25), 0.0); got > 0.01 {
+		t.Errorf("got star alpha %v at noon, want about 0", got)
+	}
+	if got := getStarAlpha(getCelestialAngle(0.75), 0.0); got < 0.99 {
+		t.Errorf("got star alpha %v at midnight, want about 1", got)
+	}
+
+	// Rain hides the stars
+	if got := getStarAlpha(getCelestialAngle(0.75), 1.0); got != 0.0 {
+		t.Errorf("got star alpha %v in heavy rain, want 0", got)
+	}
+}
+
+func TestStarsAreOnSphere(t *testing.T) {
+	vertices := genStarVertices()
+	if len(vertices) != numStars*3 {
+		t.Fatalf("got %d star vertices, want %d", len(vertices)/3, numStars)
+	}
+	for i := 0; i < len(vertices); i += 3 {
+		star := mgl32.Vec3{vertices[i], vertices[i+1], vertices[i+2]}
+		if mgl32.Abs(star.Len()-starDistance) > 1e-3 {
+			t.Fatalf("star %v isn't %v from the camera", star, starDistance)
+		}
+	}
+
+	// The stars are in the same place every time
+	again := genStarVertices()
+	for i := range vertices {
+		if vertices[i] != again[i] {
+			t.Fatal("stars moved between generations")
+		}
+	}
+}
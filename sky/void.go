@@ -0,0 +1,72 @@
+package sky
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+
+	"github.com/benanders/mineral/render"
+)
+
+// VoidPlane stores information about the dark blue void plane beneath the
+// world, which covers up the bottom half of the sky (as in earlier versions of
+// Minecraft). The void is darkest looking straight down, and fades into the
+// fog towards the horizon.
+type voidPlane struct {
+	vao, vbo uint32
+	program  *render.ReloadableProgram
+}
+
+// NewVoidPlane builds the vertex data and allocates the required OpenGL
+// resources for the void plane.
+func newVoidPlane() (voidPlane, error) {
+	// Create the program
+	program, err := loadShaders(
+		"shaders/voidVert.glsl",
+		"shaders/voidFrag.glsl")
+	if err != nil {
+		return voidPlane{}, err
+	}
+	program.Use()
+
+	// Create the void plane, the same size as the sky plane but below the
+	// camera
+	vertices := [...]float32{
+		-384.0, -16.0, -384.0, // Swap the winding order from the sky plane so
+		-384.0, -16.0, 384.0, // GL_CULL_FACE still works.
+		384.0, -16.0, -384.0,
+		384.0, -16.0, 384.0,
+	}
+	vao, vbo := genPlane(program, vertices[:])
+	return voidPlane{vao, vbo, program}, nil
+}
+
+// Destroy releases all the resources allocated by the void plane.
+func (p *voidPlane) destroy() {
+	p.program.Destroy()
+	gl.DeleteVertexArrays(1, &p.vao)
+	gl.DeleteBuffers(1, &p.vbo)
+}
+
+// Render draws the void plane using the current void and fog colors, at a
+// fixed distance from the player (so that the void always looks infinitely
+// far away).
+func (p *voidPlane) render(info RenderInfo) {
+	p.program.Use()
+
+	// The void follows the camera's orientation, like the sky plane
+	p.program.SetMat4("mvp", info.Camera.Orientation)
+
+	// Set the void and fog colors
+	celestialAngle := getCelestialAngle(info.WorldTime)
+	voidColor := getVoidColor(celestialAngle, info.Rain)
+	p.program.SetVec3("voidColor", voidColor.vec3())
+	fogColor := getFogColor(celestialAngle, info.RenderRadius, info.LookDir,
+		info.Rain)
+	p.program.SetVec3("fogColor", fogColor.vec3())
+
+	// Set the far plane distance, used for fog calculations
+	p.program.SetFloat("farPlane", info.Camera.FarPlane)
+
+	// Render the void plane
+	gl.BindVertexArray(p.vao)
+	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
+}
@@ -2,8 +2,9 @@ package world
 
 import (
 	"bytes"
+	"fmt"
 	"image"
-	"image/draw"
+	"image/color"
 	_ "image/png" // Block textures are provided as .png images
 	"log"
 
@@ -12,12 +13,43 @@ import (
 	"github.com/benanders/mineral/render"
 
 	"github.com/BurntSushi/toml"
-	"github.com/go-gl/mathgl/mgl32"
+	"github.com/chewxy/math32"
+	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
-// Block is an ID representing the type of a block within the world.
+// Block represents a block within the world. The low bits of a block are the
+// ID of its type, and the high bits hold extra state for the block (like its
+// orientation), so that blocks of the same type can differ slightly.
 type Block uint32
 
+// Air is the ID of the empty block, which is always the first block listed in
+// `blocks.toml`.
+const Air Block = 0
+
+const (
+	// The number of low bits of a block that hold the ID of its type. The
+	// remaining bits hold the block's state.
+	blockTypeBits = 16
+
+	// Masks out a block's state, leaving only the ID of its type.
+	blockTypeMask = 1<<blockTypeBits - 1
+)
+
+// Type returns the ID of the block's type, without any of its state.
+func (b Block) Type() Block {
+	return b & blockTypeMask
+}
+
+// State returns the extra state stored with a block.
+func (b Block) State() uint16 {
+	return uint16(b >> blockTypeBits)
+}
+
+// WithState returns a block of the same type with its state replaced.
+func (b Block) WithState(state uint16) Block {
+	return b.Type() | Block(state)<<blockTypeBits
+}
+
 // BlockFace represents one of the 6 faces of a block.
 type blockFace uint
 
@@ -47,18 +79,72 @@ func (f blockFace) normal() (int, int, int) {
 	return faceNormals[f][0], faceNormals[f][1], faceNormals[f][2]
 }
 
+// The brightness multiplier applied to each face of a block, depending on the
+// direction it faces. Shading the faces differently makes it easier to tell
+// them apart, giving blocks a sense of volume. These are the same as
+// Minecraft's values.
+const (
+	topFaceShade    = 1.0
+	bottomFaceShade = 0.5
+	xFaceShade      = 0.6 // Left and right faces
+	zFaceShade      = 0.8 // Front and back faces
+)
+
+// Shade returns the brightness multiplier for a face. These are passed to the
+// chunk shader when the world is created, which picks the multiplier for each
+// vertex based on its normal.
+func (f blockFace) shade() float32 {
+	switch f {
+	case faceTop:
+		return topFaceShade
+	case faceBottom:
+		return bottomFaceShade
+	case faceLeft, faceRight:
+		return xFaceShade
+	default:
+		return zFaceShade
+	}
+}
+
 const (
 	// BlockAtlasSlot is the OpenGL texture slot into which the block atlas
 	// image is to be loaded.
-	blockAtlasSlot = 0
+	BlockAtlasSlot = 0
 
 	// The size of each block texture, in pixels.
 	blockTextureWidth  = 16
 	blockTextureHeight = 16
 
-	// The size of the block atlas image, in pixels.
+	// The width of the border around each block texture in the atlas, in
+	// pixels. The border repeats the pixels along the edge of the texture, so
+	// that sampling just outside a texture (which the GPU does along the seams
+	// between blocks) picks up the texture's own color rather than bleeding
+	// in color from the neighbouring texture in the atlas.
+	blockTexturePadding = 8
+
+	// The number of mipmap levels generated for the block atlas, beyond the
+	// full size image. Each level halves the size of the atlas, and with it
+	// the width of the border around each texture, so this is limited such
+	// that every level still has a border at least 1 pixel wide (otherwise
+	// neighbouring textures would bleed into each other in the distance).
+	atlasMipmapLevels = 3
+
+	// The size of the space each block texture takes up in the atlas,
+	// including its border, in pixels.
+	atlasCellWidth  = blockTextureWidth + 2*blockTexturePadding
+	atlasCellHeight = blockTextureHeight + 2*blockTexturePadding
+
+	// The size of the block atlas image, in pixels. The atlas is tall enough
+	// to fit every frame of an animated texture (like water) in one column.
 	atlasTextureWidth  = 256
-	atlasTextureHeight = 256
+	atlasTextureHeight = 1024
+
+	// The distance between consecutive frames of an animated texture in the
+	// atlas, as a fraction of the atlas's height.
+	frameHeight = float32(atlasCellHeight) / atlasTextureHeight
+
+	// The time each frame of an animated texture is shown for, in seconds.
+	animationFrameTime = 0.1
 )
 
 // BlocksInfo contains the properties of every block type.
@@ -66,9 +152,20 @@ type BlocksInfo struct {
 	Blocks []*BlockInfo
 }
 
-// Get returns information for the given block type.
+// Get returns information for the given block's type.
 func (info *BlocksInfo) get(b Block) *BlockInfo {
-	return info.Blocks[b]
+	return info.Blocks[b.Type()]
+}
+
+// Find returns the type of block with the given name. Returns false if there's
+// no block with that name.
+func (info *BlocksInfo) find(name string) (Block, bool) {
+	for id, block := range info.Blocks {
+		if block.Name == name {
+			return Block(id), true
+		}
+	}
+	return Air, false
 }
 
 // BlockInfo contains the properties of a block type.
@@ -77,28 +174,156 @@ type BlockInfo struct {
 	Visible     bool   // True if the block actually renders something
 	Collidable  bool   // True if the block has a collidable AABB
 	Transparent bool   // True if we can see the block behind at any angle
-	Texture     string // Path to the texture to use for all faces
-	UV          FaceUV // UV coordinates to use for all faces
+	Texture     string // Path to the default texture for all faces
+
+	// Optional paths to the textures for the top, bottom, and side faces of
+	// the block, overriding `Texture` for those faces
+	TextureTop    string
+	TextureBottom string
+	TextureSide   string
+
+	// An optional color that the top texture is multiplied by, as red, green,
+	// and blue components between 0 and 255. Used to color in textures that
+	// Minecraft provides in greyscale (e.g. the top of grass blocks)
+	TintTop []uint8
+
+	// UV coordinates in the texture atlas for each face, indexed by block face
+	UV [6]FaceUV
+
+	// The light level emitted by the block, up to a maximum of 15. Zero for
+	// blocks that don't emit any light
+	LightEmission uint8
+
+	// True if the block can be placed lying along any axis, rather than only
+	// standing upright (e.g. logs)
+	Orientable bool
+
+	// How long the block takes to break in survival mode, relative to other
+	// blocks. Blocks with no hardness break instantly, and blocks with a
+	// negative hardness can't be broken at all (e.g. bedrock)
+	Hardness float32
+
+	// The name of the block dropped when the block is broken in survival
+	// mode, which the player can pick up. Blocks drop themselves if this is
+	// empty, and nothing if it's "Air"
+	Drops string
+	drop  Block
+
+	// True if the block falls when there's nothing beneath it (e.g. sand)
+	Gravity bool
+
+	// True if entities can climb up and down the block (e.g. ladders and
+	// vines), rather than falling past it
+	Climbable bool
+
+	// True if the block is a fluid (e.g. water), which flows outwards from
+	// source blocks. The level of the fluid is stored in the block's state
+	Fluid bool
+
+	// The maximum number of blocks a fluid flows horizontally away from its
+	// source, up to a maximum of 15
+	FlowDistance int
+
+	// True if the block is partially see-through (e.g. water), so is drawn
+	// after all other blocks, blended with whatever is behind it
+	Translucent bool
+
+	// The name of the block's shape (see `blockShapes`). Blocks are full cubes
+	// if no shape is given
+	Shape string
+	shape *blockShape
+
+	// Optional sounds played when the block is broken, placed, and walked on,
+	// relative to the `asset/data/sounds` folder (see `audio.Play`)
+	BreakSound string
+	PlaceSound string
+	StepSound  string
+}
+
+// GetShape returns the block's shape, which is a full cube if the block's
+// shape hasn't been resolved from its name.
+func (info *BlockInfo) getShape() *blockShape {
+	if info.shape == nil {
+		return &cubeShape
+	}
+	return info.shape
 }
 
-// AABB returns an axis aligned bounding box for the block, used for collision
-// detection.
+// Breakable returns true if the block can be broken, which is everything
+// except blocks with a negative hardness.
+func (info *BlockInfo) Breakable() bool {
+	return info.Hardness >= 0.0
+}
+
+// Drop returns the type of block dropped when the block is broken in survival
+// mode, or air if it doesn't drop anything.
+func (info *BlockInfo) Drop() Block {
+	return info.drop
+}
+
+// AABB returns an axis aligned bounding box enclosing the whole block. For
+// blocks that aren't full cubes, this only encloses the parts of the block
+// that are actually there (e.g. the bottom half of a block for a slab).
 func (info *BlockInfo) AABB(p, q, x, y, z int) math.AABB {
-	// Add 0.5 since the AABB struct requires we specify the centre of the
-	// block, and blocks are always 1x1 units
-	rx := float32(p*ChunkWidth+x) + 0.5
-	ry := float32(y) + 0.5
-	rz := float32(q*ChunkDepth+z) + 0.5
-	return math.AABB{
-		Center: mgl32.Vec3{rx, ry, rz},
-		Size:   mgl32.Vec3{1.0, 1.0, 1.0},
+	aabbs := info.AABBs(p, q, x, y, z)
+	min, max := aabbs[0].Min(), aabbs[0].Max()
+	for _, aabb := range aabbs[1:] {
+		for axis := 0; axis < 3; axis++ {
+			min[axis] = math32.Min(min[axis], aabb.Min()[axis])
+			max[axis] = math32.Max(max[axis], aabb.Max()[axis])
+		}
 	}
+	return math.AABB{Center: min.Add(max).Mul(0.5), Size: max.Sub(min)}
+}
+
+// AABBs returns the axis aligned bounding boxes making up the block, used for
+// collision detection. Full cubes are a single box, but other shapes may need
+// more than one (e.g. stairs).
+func (info *BlockInfo) AABBs(p, q, x, y, z int) []math.AABB {
+	return info.getShape().aabbs(p, q, x, y, z)
+}
+
+// FaceTexture returns the path to the texture to use for the given face of the
+// block, falling back to the block's default texture if no texture is given
+// for the face specifically.
+func (info *BlockInfo) faceTexture(face blockFace) string {
+	var texture string
+	switch face {
+	case faceTop:
+		texture = info.TextureTop
+	case faceBottom:
+		texture = info.TextureBottom
+	default:
+		texture = info.TextureSide
+	}
+	if texture == "" {
+		texture = info.Texture
+	}
+	return texture
+}
+
+// FaceTint returns the color to multiply the texture for the given face of the
+// block by, or nil if the face's texture isn't tinted.
+func (info *BlockInfo) faceTint(face blockFace) []uint8 {
+	if face == faceTop {
+		return info.TintTop
+	}
+	return nil
+}
+
+// ParticleUV returns the UV coordinate of the texture used for particles when
+// the block breaks, which is the texture on the front of the block (so blocks
+// like grass break into pieces of their side texture).
+func (info *BlockInfo) ParticleUV() FaceUV {
+	return info.UV[faceFront]
 }
 
 // FaceUV represents the base UV coordinate for a block face in the block
-// texture atlas.
+// texture atlas. For animated textures, this is the UV coordinate of the first
+// frame, and the other frames follow below it in the atlas.
 type FaceUV struct {
-	X, Y float32
+	X, Y   float32
+	Frames int // Number of animation frames, or 1 if not animated
 }
 
 // Size returns the size of a block texture in the texture atlas, scaled such
@@ -116,7 +341,7 @@ func (uv FaceUV) Size() (float32, float32) {
 // and the OpenGL ID for the block texture atlas.
 func loadBlocksInfo() (BlocksInfo, uint32) {
 	blocksInfo := loadBlockProperties()
-	blockAtlas := loadBlockAtlas(blockAtlasSlot, blocksInfo)
+	blockAtlas := loadBlockAtlas(BlockAtlasSlot, blocksInfo)
 	return blocksInfo, blockAtlas
 }
 
@@ -136,21 +361,71 @@ func loadBlockProperties() BlocksInfo {
 		log.Fatalln("failed to decode `asset/data/blocks.toml`: ", err)
 	}
 
+	// Find the shape of each block
+	for id, info := range blocksInfo.Blocks {
+		shape, ok := blockShapes[info.Shape]
+		if !ok {
+			log.Fatalln("unknown shape `" + info.Shape + "` for block `" +
+				info.Name + "` in `asset/data/blocks.toml`")
+		}
+		info.shape = shape
+
+		// Check the fluid's flow distance fits in the block's state
+		if info.FlowDistance < 0 || info.FlowDistance > fluidLevelMask {
+			log.Fatalln("`FlowDistance` for block `" + info.Name + "` in " +
+				"`asset/data/blocks.toml` must be between 0 and 15")
+		}
+
+		// Check the tint color has a value for each component
+		if info.TintTop != nil && len(info.TintTop) != 3 {
+			log.Fatalln("`TintTop` for block `" + info.Name + "` in " +
+				"`asset/data/blocks.toml` must have 3 components")
+		}
+
+		// Find the block dropped when the block is broken
+		info.drop = Block(id)
+		if info.Drops != "" {
+			drop, ok := blocksInfo.find(info.Drops)
+			if !ok {
+				log.Fatalln("unknown block `" + info.Drops + "` dropped by " +
+					"block `" + info.Name + "` in `asset/data/blocks.toml`")
+			}
+			info.drop = drop
+		}
+	}
+
 	return blocksInfo
 }
 
 // LoadBlockAtlas creates a new texture atlas image from the individual textures
 // for each block, uploads it to the GPU in the given texture slot, and returns
 // an OpenGL texture ID.
-//
-// The function sets the UV coordinates for each block type in the blockInfos
-// array.
 func loadBlockAtlas(slot uint32, blocksInfo BlocksInfo) uint32 {
+	atlasImg := buildBlockAtlas(blocksInfo, loadBlockTexture)
+	texture := render.LoadTextureMipmapped(atlasImg, slot)
+
+	// Don't use the mipmap levels where the borders between textures vanish
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, atlasMipmapLevels)
+	return texture
+}
+
+// BuildBlockAtlas creates a new texture atlas image from the individual
+// textures for each block, without uploading it to the GPU. Each texture is
+// loaded by calling `load` with the texture's path and the block's name
+// (usually `loadBlockTexture`).
+//
+// The function sets the UV coordinates for each face of each block type in the
+// blockInfos array. Each distinct texture is only placed in the atlas once.
+func buildBlockAtlas(blocksInfo BlocksInfo,
+	load func(texture, name string) image.Image) *image.RGBA {
 	// Create the block atlas image
 	rect := image.Rect(0, 0, atlasTextureWidth, atlasTextureHeight)
 	atlasImg := image.NewRGBA(rect)
 
-	// Load each png and place it into the atlas
+	// Load each png and place it into the atlas. Blocks can share textures
+	// (and a block can use the same texture for several faces), so keep track
+	// of where each texture has already been placed
+	placed := make(map[string]FaceUV)
 	x, y := 0, 0
 	for _, info := range blocksInfo.Blocks {
 		// Only bother getting an image if the block is visible
@@ -158,49 +433,153 @@ func loadBlockAtlas(slot uint32, blocksInfo BlocksInfo) uint32 {
 			continue
 		}
 
-		// Check we've still got enough room in the atlas to fit another texture
-		if y > atlasTextureHeight-blockTextureHeight {
-			log.Fatalln("failed to fit all block textures in block atlas")
-		}
+		for face := faceLeft; face <= faceBack; face++ {
+			// Check if the texture is already in the atlas. The same texture
+			// with a different tint is a different texture in the atlas
+			texture := info.faceTexture(face)
+			tint := info.faceTint(face)
+			key := texture
+			if tint != nil {
+				key += fmt.Sprint(tint)
+			}
+			if uv, ok := placed[key]; ok {
+				info.UV[face] = uv
+				continue
+			}
+
+			// Load the texture, which may be a strip of animation frames
+			blockImg := load(texture, info.Name)
+			if tint != nil {
+				blockImg = tintTexture(blockImg, tint)
+			}
+			frames := textureFrames(blockImg)
+
+			// Textures are placed down each column of the atlas in turn, with
+			// the frames of an animated texture one above the other, so the
+			// chunk shader can find each frame by offsetting the texture's V
+			// coordinate. Move on to the next column if all the frames don't
+			// fit in this one
+			if y+frames*atlasCellHeight > atlasTextureHeight {
+				x += atlasCellWidth
+				y = 0
+			}
+			if x > atlasTextureWidth-atlasCellWidth ||
+				frames*atlasCellHeight > atlasTextureHeight {
+				log.Fatalln("failed to fit all block textures in block atlas")
+			}
 
-		// Get the .png file that contains the block's texture
-		pngData, err := asset.Asset(info.Texture)
-		if err != nil {
-			log.Fatalln("failed to load image `" + info.Texture +
-				"` for block " + info.Name)
+			// Copy each frame into the texture atlas, inside its border
+			bounds := blockImg.Bounds()
+			for frame := 0; frame < frames; frame++ {
+				rect := image.Rect(bounds.Min.X,
+					bounds.Min.Y+frame*blockTextureHeight,
+					bounds.Min.X+blockTextureWidth,
+					bounds.Min.Y+(frame+1)*blockTextureHeight)
+				frameImg := blockImg.(subImager).SubImage(rect)
+				drawPaddedTexture(atlasImg, frameImg, x,
+					y+frame*atlasCellHeight)
+			}
+
+			// Set the face's UV coordinates to the first frame of the texture,
+			// excluding its border
+			u := float32(x+blockTexturePadding) / float32(atlasTextureWidth)
+			v := float32(y+blockTexturePadding) / float32(atlasTextureHeight)
+			uv := FaceUV{X: u, Y: v, Frames: frames}
+			info.UV[face] = uv
+			placed[key] = uv
+
+			// Increment the offset at which textures are placed in the atlas
+			y += frames * atlasCellHeight
 		}
+	}
 
-		// Decode the .png file
-		blockImg, _, err := image.Decode(bytes.NewReader(pngData))
-		if err != nil {
-			log.Fatalln("failed to decode png image `" + info.Texture +
-				"` for block " + info.Name)
+	return atlasImg
+}
+
+// DrawPaddedTexture copies a block texture into the atlas cell whose top left
+// corner is at (x, y), surrounded by a border made by repeating the pixels
+// along each edge of the texture outwards.
+func drawPaddedTexture(atlasImg *image.RGBA, blockImg image.Image, x, y int) {
+	bounds := blockImg.Bounds()
+	for dy := 0; dy < atlasCellHeight; dy++ {
+		for dx := 0; dx < atlasCellWidth; dx++ {
+			// Find the closest pixel in the texture
+			sx := clampInt(dx-blockTexturePadding, 0, blockTextureWidth-1)
+			sy := clampInt(dy-blockTexturePadding, 0, blockTextureHeight-1)
+			c := blockImg.At(bounds.Min.X+sx, bounds.Min.Y+sy)
+			atlasImg.Set(x+dx, y+dy, c)
 		}
+	}
+}
 
-		// Ensure the block texture is of the correct size
-		w := blockImg.Bounds().Max.X - blockImg.Bounds().Min.X
-		h := blockImg.Bounds().Max.Y - blockImg.Bounds().Min.Y
-		if w != blockTextureWidth || h != blockTextureHeight {
-			log.Fatalln("image for block " + info.Name + " is incorrect size")
+// TintTexture returns a copy of a block texture with the red, green, and blue
+// components of each pixel multiplied by a tint color.
+func tintTexture(blockImg image.Image, tint []uint8) *image.RGBA {
+	bounds := blockImg.Bounds()
+	tinted := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(blockImg.At(x, y)).(color.RGBA)
+			tinted.SetRGBA(x, y, color.RGBA{
+				R: uint8(uint(c.R) * uint(tint[0]) / 255),
+				G: uint8(uint(c.G) * uint(tint[1]) / 255),
+				B: uint8(uint(c.B) * uint(tint[2]) / 255),
+				A: c.A,
+			})
 		}
+	}
+	return tinted
+}
 
-		// Copy the block's texture into the texture atlas
-		srcPoint := image.Point{0, 0}
-		dstRect := image.Rect(x, y, x+w, y+h)
-		draw.Draw(atlasImg, dstRect, blockImg, srcPoint, draw.Over)
+// ClampInt restricts an integer to lie between a minimum and maximum value.
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+	return v
+}
 
-		// Set the block's UV coordinates
-		info.UV.X = float32(x) / float32(atlasTextureWidth)
-		info.UV.Y = float32(y) / float32(atlasTextureHeight)
+// TextureFrames returns the number of animation frames in a block texture.
+// Animated textures are a vertical strip of square frames, one above the
+// other, and textures that aren't animated have a single frame. Returns 0 if
+// the texture isn't a valid size.
+func textureFrames(img image.Image) int {
+	w := img.Bounds().Dx()
+	h := img.Bounds().Dy()
+	if w != blockTextureWidth || h == 0 || h%blockTextureHeight != 0 {
+		return 0
+	}
+	return h / blockTextureHeight
+}
 
-		// Increment the offset at which textures are placed in the atlas
-		x += blockTextureWidth
-		if x > atlasTextureWidth-blockTextureWidth {
-			x = 0
-			y += blockTextureHeight
-		}
+// SubImager is implemented by all the image types returned when decoding a
+// .png image, and allows a part of the image to be extracted.
+type subImager interface {
+	SubImage(r image.Rectangle) image.Image
+}
+
+// LoadBlockTexture loads and decodes the .png image for a block texture,
+// ensuring it's the correct size.
+func loadBlockTexture(texture, name string) image.Image {
+	// Get the .png file that contains the texture
+	pngData, err := asset.Asset(texture)
+	if err != nil {
+		log.Fatalln("failed to load image `" + texture + "` for block " + name)
+	}
+
+	// Decode the .png file
+	img, _, err := image.Decode(bytes.NewReader(pngData))
+	if err != nil {
+		log.Fatalln("failed to decode png image `" + texture +
+			"` for block " + name)
 	}
 
-	// Upload the texture to the GPU
-	return render.LoadTexture(atlasImg, slot)
+	// Ensure the block texture is of the correct size. Animated textures (like
+	// water) are a vertical strip of frames (see `textureFrames`)
+	if textureFrames(img) == 0 {
+		log.Fatalln("image for block " + name + " is incorrect size")
+	}
+	return img
 }
@@ -0,0 +1,245 @@
+package world
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFaceShade(t *testing.T) {
+	tests := []struct {
+		face blockFace
+		want float32
+	}{
+		{faceTop, topFaceShade},
+		{faceBottom, bottomFaceShade},
+		{faceLeft, xFaceShade},
+		{faceRight, xFaceShade},
+		{faceFront, zFaceShade},
+		{faceBack, zFaceShade},
+	}
+	for _, test := range tests {
+		if got := test.face.shade(); got != test.want {
+			t.Errorf("got shade %v for face %v, want %v", got, test.face,
+				test.want)
+		}
+	}
+
+	// Faces pointing further up are brighter, as if lit from above
+	if !(faceTop.shade() > faceFront.shade() &&
+		faceFront.shade() > faceLeft.shade() &&
+		faceLeft.shade() > faceBottom.shade()) {
+		t.Error("faces aren't shaded from brightest on top to darkest below")
+	}
+}
+
+func TestTextureFrames(t *testing.T) {
+	tests := []struct {
+		width, height, want int
+	}{
+		{blockTextureWidth, blockTextureHeight, 1},
+		{blockTextureWidth, 32 * blockTextureHeight, 32},
+		{blockTextureWidth, blockTextureHeight + 4, 0},
+		{2 * blockTextureWidth, 2 * blockTextureHeight, 0},
+		{blockTextureWidth, 0, 0},
+	}
+	for _, test := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, test.width, test.height))
+		if got := textureFrames(img); got != test.want {
+			t.Errorf("got %d frames in a %dx%d texture, want %d", got,
+				test.width, test.height, test.want)
+		}
+	}
+}
+
+// SolidTexture creates a block texture with the given number of animation
+// frames, where every pixel of frame `i` is `c` with its green component
+// increased by `i`.
+func solidTexture(c color.RGBA, frames int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, blockTextureWidth,
+		frames*blockTextureHeight))
+	for y := 0; y < frames*blockTextureHeight; y++ {
+		for x := 0; x < blockTextureWidth; x++ {
+			frame := color.RGBA{c.R, c.G + uint8(y/blockTextureHeight), c.B,
+				c.A}
+			img.SetRGBA(x, y, frame)
+		}
+	}
+	return img
+}
+
+// TestTextureLoader returns a texture loader for `buildBlockAtlas` that loads
+// textures from the given map, failing the test if a texture isn't in it.
+func testTextureLoader(t *testing.T,
+	textures map[string]image.Image) func(texture, name string) image.Image {
+	return func(texture, name string) image.Image {
+		img, ok := textures[texture]
+		if !ok {
+			t.Fatalf("block %s uses unknown texture %q", name, texture)
+		}
+		return img
+	}
+}
+
+// AtlasColorAt returns the color of the atlas pixel at the top left corner of
+// the given UV coordinate.
+func atlasColorAt(atlas *image.RGBA, uv FaceUV) color.RGBA {
+	x := int(uv.X * atlasTextureWidth)
+	y := int(uv.Y * atlasTextureHeight)
+	return atlas.RGBAAt(x, y)
+}
+
+func TestPerFaceTextures(t *testing.T) {
+	top := color.RGBA{200, 0, 0, 255}
+	bottom := color.RGBA{0, 0, 200, 255}
+	side := color.RGBA{100, 100, 100, 255}
+	blocksInfo := BlocksInfo{Blocks: []*BlockInfo{
+		{Name: "Air"},
+		{Name: "Grass", Visible: true, TextureTop: "top.png",
+			TextureBottom: "bottom.png", TextureSide: "side.png"},
+	}}
+	atlas := buildBlockAtlas(blocksInfo, testTextureLoader(t,
+		map[string]image.Image{
+			"top.png":    solidTexture(top, 1),
+			"bottom.png": solidTexture(bottom, 1),
+			"side.png":   solidTexture(side, 1),
+		}))
+
+	// Each of the three textures has its own place in the atlas
+	info := blocksInfo.Blocks[1]
+	entries := make(map[FaceUV]bool)
+	for _, uv := range info.UV {
+		entries[uv] = true
+	}
+	if len(entries) != 3 {
+		t.Errorf("got %d atlas entries, want 3", len(entries))
+	}
+
+	// And each face samples its own texture
+	for face := faceLeft; face <= faceBack; face++ {
+		want := side
+		if face == faceTop {
+			want = top
+		} else if face == faceBottom {
+			want = bottom
+		}
+		if got := atlasColorAt(atlas, info.UV[face]); got != want {
+			t.Errorf("face %v samples color %v, want %v", face, got, want)
+		}
+	}
+}
+
+// ManyTextureBlocks returns the properties of `n` blocks which each have their
+// own solid colored texture, along with a loader for the textures.
+func manyTextureBlocks(t *testing.T,
+	n int) (BlocksInfo, func(texture, name string) image.Image) {
+	blocksInfo := BlocksInfo{Blocks: []*BlockInfo{{Name: "Air"}}}
+	textures := make(map[string]image.Image)
+	for i := 0; i < n; i++ {
+		texture := fmt.Sprintf("%d.png", i)
+		textures[texture] = solidTexture(color.RGBA{uint8(i), 100, 200, 255},
+			1)
+		blocksInfo.Blocks = append(blocksInfo.Blocks,
+			&BlockInfo{Name: texture, Visible: true, Texture: texture})
+	}
+	return blocksInfo, testTextureLoader(t, textures)
+}
+
+func TestAtlasUVsStayInsideBorder(t *testing.T) {
+	// Enough textures to fill more than one column of the atlas
+	const numTextures = 2*atlasTextureHeight/atlasCellHeight + 3
+	blocksInfo, load := manyTextureBlocks(t, numTextures)
+	atlas := buildBlockAtlas(blocksInfo, load)
+
+	w, h := blocksInfo.Blocks[1].UV[faceTop].Size()
+	if w*atlasTextureWidth != blockTextureWidth ||
+		h*atlasTextureHeight != blockTextureHeight {
+		t.Fatalf("got UV size %v x %v, want one %d x %d texture", w, h,
+			blockTextureWidth, blockTextureHeight)
+	}
+	for _, info := range blocksInfo.Blocks[1:] {
+		// The texture starts exactly one border's width into its cell, so
+		// the UVs are inset from the edges of the cell by the border
+		uv := info.UV[faceTop]
+		x := uv.X * atlasTextureWidth
+		y := uv.Y * atlasTextureHeight
+		cellX := int(x) - blockTexturePadding
+		cellY := int(y) - blockTexturePadding
+		if float32(int(x)) != x || float32(int(y)) != y ||
+			cellX%atlasCellWidth != 0 || cellY%atlasCellHeight != 0 {
+			t.Errorf("block %s has UV at pixel (%v, %v), want one border "+
+				"inside a cell", info.Name, x, y)
+			continue
+		}
+		if cellX+atlasCellWidth > atlasTextureWidth ||
+			cellY+atlasCellHeight > atlasTextureHeight {
+			t.Errorf("block %s's cell at (%d, %d) runs off the atlas",
+				info.Name, cellX, cellY)
+			continue
+		}
+
+		// The whole border around the texture repeats the texture's own
+		// color, so sampling just outside the UVs can't bleed in any color
+		// from the neighbouring cells
+		want := atlas.RGBAAt(int(x), int(y))
+		for _, corner := range [][2]int{
+			{cellX, cellY},
+			{cellX + atlasCellWidth - 1, cellY},
+			{cellX, cellY + atlasCellHeight - 1},
+			{cellX + atlasCellWidth - 1, cellY + atlasCellHeight - 1},
+		} {
+			if got := atlas.RGBAAt(corner[0], corner[1]); got != want {
+				t.Errorf("block %s's border at %v is %v, want %v",
+					info.Name, corner, got, want)
+			}
+		}
+	}
+}
+
+func TestAtlasBordersSurviveMipmaps(t *testing.T) {
+	// Each mipmap level halves the atlas, so the cells, the textures within
+	// them, and the borders around them must stay a whole number of pixels
+	// wide, and the borders at least 1 pixel wide, at every level in use
+	for level := 1; level <= atlasMipmapLevels; level++ {
+		scale := 1 << level
+		if atlasCellWidth%scale != 0 || atlasCellHeight%scale != 0 ||
+			blockTexturePadding%scale != 0 {
+			t.Errorf("cells aren't a whole number of pixels at mipmap "+
+				"level %d", level)
+		}
+		if blockTexturePadding/scale < 1 {
+			t.Errorf("border vanishes at mipmap level %d", level)
+		}
+	}
+}
+
+func TestAnimatedTextureFrames(t *testing.T) {
+	// A 16x64 texture is a strip of 4 animation frames
+	blocksInfo := BlocksInfo{Blocks: []*BlockInfo{
+		{Name: "Air"},
+		{Name: "Stone", Visible: true, Texture: "stone.png"},
+		{Name: "Lava", Visible: true, Texture: "lava.png"},
+	}}
+	lava := color.RGBA{200, 50, 0, 255}
+	atlas := buildBlockAtlas(blocksInfo, testTextureLoader(t,
+		map[string]image.Image{
+			"stone.png": solidTexture(color.RGBA{90, 90, 90, 255}, 1),
+			"lava.png":  solidTexture(lava, 4),
+		}))
+	uv := blocksInfo.Blocks[2].UV[faceTop]
+	if uv.Frames != 4 {
+		t.Fatalf("got %d frames in a 16x64 texture, want 4", uv.Frames)
+	}
+
+	// The chunk shader offsets the V coordinate by `frameHeight` for each
+	// frame, which lands on the top left of that frame in the atlas
+	for frame := 0; frame < uv.Frames; frame++ {
+		offset := FaceUV{X: uv.X, Y: uv.Y + float32(frame)*frameHeight}
+		want := color.RGBA{lava.R, lava.G + uint8(frame), lava.B, lava.A}
+		if got := atlasColorAt(atlas, offset); got != want {
+			t.Errorf("frame %d at V offset %v samples %v, want %v", frame,
+				offset.Y-uv.Y, got, want)
+		}
+	}
+}
@@ -1,14 +1,26 @@
 package world
 
 import (
+	"time"
+	"unsafe"
+
 	"github.com/go-gl/gl/v3.3-core/gl"
 )
 
-// The size of the chunk (width, height, and depth), in blocks.
+// The horizontal size of a chunk (width and depth), in blocks.
 const (
-	ChunkWidth  = 16
-	ChunkHeight = 256
-	ChunkDepth  = 16
+	ChunkWidth = 16
+	ChunkDepth = 16
+)
+
+// The height of the world (and so every chunk), in blocks, is chosen when the
+// world is created.
+const (
+	// DefaultHeight is the height of the world if no other height is given.
+	DefaultHeight = 256
+
+	// MaxHeight is the tallest world that can be created.
+	MaxHeight = 1024
 )
 
 // ChunkPos represents the position of a chunk as a pair of x, z values
@@ -21,39 +33,134 @@ type chunkPos struct {
 // information, block data, vertex data, and lighting data.
 type Chunk struct {
 	Blocks      blockData // The cached block data for the chunk
-	numVertices int32     // The number of vertices to render
+	light       lightData // The light level of each block in the chunk
+	numVertices int32     // The number of vertices in the VBO
+	numIndices  int32     // The number of indices to render
+	numOpaque   int32     // The number of indices that aren't translucent
+	lod         int       // The level of detail of the vertex data
 	vao, vbo    uint32    // OpenGL buffers
+	ebo         uint32    // OpenGL buffer holding the vertex indices
+	vboSize     int       // The size of the VBO's storage, in bytes
+	eboSize     int       // The size of the EBO's storage, in bytes
+
+	// True if the chunk has no vertex data (e.g. it's entirely air). Empty
+	// chunks don't have any OpenGL buffers allocated, so they're cheap to
+	// keep loaded
+	empty bool
+
+	// Which faces of the chunk are made up entirely of opaque blocks, indexed
+	// by `blockFace`, and whether the chunk is hidden behind opaque blocks on
+	// every side, in which case it isn't rendered (see `updateEnclosed`)
+	opaqueFaces [6]bool
+	enclosed    bool
+
+	// True if the chunk's blocks have changed since it was loaded or last
+	// saved, in which case it's written to the save folder (see `Save`)
+	dirty bool
+
+	// The time at which the chunk's vertex data was first uploaded, from
+	// which the chunk fades in (see `fadeFactor`)
+	loadTime time.Time
 }
 
 // NewChunk creates a new, empty chunk with no block, rendering, or lighting
-// data.
+// data. No OpenGL buffers are allocated until the chunk has some vertex data.
 func newChunk() *Chunk {
-	// Create a VAO and VBO, but don't upload any data
-	var vao, vbo uint32
-	gl.GenVertexArrays(1, &vao)
-	gl.GenBuffers(1, &vbo)
-	return &Chunk{vao: vao, vbo: vbo}
+	return &Chunk{empty: true}
+}
+
+// ChunkFadeDuration is the time taken for a newly loaded chunk to fade in from
+// the fog color, so chunks don't suddenly pop into existence at the edge of
+// the loaded area.
+const chunkFadeDuration = 500 * time.Millisecond
+
+// FadeFactor returns how far a chunk has faded in, `elapsed` after it was
+// loaded, from 0 (the fog color) to 1 (fully visible).
+func fadeFactor(elapsed time.Duration) float32 {
+	if elapsed <= 0 {
+		return 0.0
+	}
+	if elapsed >= chunkFadeDuration {
+		return 1.0
+	}
+	return float32(elapsed) / float32(chunkFadeDuration)
+}
+
+// State returns how far through loading the chunk is.
+func (c *Chunk) state() ChunkState {
+	switch {
+	case c.Blocks == nil:
+		return ChunkLoading
+	case c.empty:
+		return ChunkLoaded
+	default:
+		return ChunkMeshed
+	}
+}
+
+// AllocBuffers creates the chunk's VAO, VBO, and EBO, if they don't already
+// exist, but doesn't upload any data. Returns true if the buffers were
+// created, in which case the VAO's vertex attributes still need to be set up.
+func (c *Chunk) allocBuffers() bool {
+	if c.vao != 0 {
+		return false
+	}
+	gl.GenVertexArrays(1, &c.vao)
+	gl.GenBuffers(1, &c.vbo)
+	gl.GenBuffers(1, &c.ebo)
+	return true
+}
+
+// UploadBufferData fills the buffer bound to `target` with `size` bytes of
+// data, reusing the buffer's existing storage of `capacity` bytes if the data
+// fits in it. The old storage is orphaned first, so the driver can hand us
+// fresh memory rather than waiting for any draws still reading from it.
+// Returns the new capacity of the buffer.
+func uploadBufferData(target uint32, capacity, size int,
+	data unsafe.Pointer) int {
+	if size <= capacity {
+		gl.BufferData(target, capacity, nil, gl.STATIC_DRAW)
+		gl.BufferSubData(target, 0, size, data)
+		return capacity
+	}
+	gl.BufferData(target, size, data, gl.STATIC_DRAW)
+	return size
 }
 
 // Destroy releases all resources allocated when creating a chunk.
 func (c *Chunk) destroy() {
+	if c.vao == 0 {
+		return
+	}
 	gl.DeleteBuffers(1, &c.vbo)
+	gl.DeleteBuffers(1, &c.ebo)
 	gl.DeleteVertexArrays(1, &c.vao)
+	c.vao, c.vbo, c.ebo = 0, 0, 0
+	c.vboSize, c.eboSize = 0, 0
+}
+
+// RenderOpaque draws all the faces in the chunk that aren't translucent.
+func (c *Chunk) renderOpaque() {
+	gl.BindVertexArray(c.vao)
+	gl.DrawElements(gl.TRIANGLES, c.numOpaque, gl.UNSIGNED_INT,
+		gl.PtrOffset(0))
 }
 
-// Render draws the chunk to the screen.
-func (c *Chunk) render() {
+// RenderTranslucent draws all the translucent faces in the chunk, which come
+// after the rest of the chunk's vertex data.
+func (c *Chunk) renderTranslucent() {
 	gl.BindVertexArray(c.vao)
-	gl.DrawArrays(gl.TRIANGLES, 0, c.numVertices)
+	gl.DrawElements(gl.TRIANGLES, c.numIndices-c.numOpaque, gl.UNSIGNED_INT,
+		gl.PtrOffset(int(c.numOpaque)*4))
 }
 
 // blockData represents an array of blocks within a chunk.
 type blockData []Block
 
-// NewBlockData creates a new blocks array for a chunk, with length equal to
-// the number of blocks in a chunk.
-func newBlockData() blockData {
-	return make([]Block, ChunkWidth*ChunkHeight*ChunkDepth)
+// Height returns the height of the chunk the block list belongs to, which is
+// implied by the length of the list.
+func (b blockData) height() int {
+	return len(b) / (ChunkWidth * ChunkDepth)
 }
 
 // At returns the block at the given coordinate within the block list. If the
@@ -61,7 +168,7 @@ func newBlockData() blockData {
 func (b blockData) At(x, y, z int) *Block {
 	// Prevent an array out of bounds exception
 	if x < 0 || x >= ChunkWidth ||
-		y < 0 || y >= ChunkHeight ||
+		y < 0 || y >= b.height() ||
 		z < 0 || z >= ChunkDepth {
 		return nil
 	}
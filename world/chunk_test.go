@@ -0,0 +1,207 @@
+package world
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllAirChunkHasNoBuffers(t *testing.T) {
+	w := newTestWorld()
+	blocks := newBlockData(w.Height)
+	light := genLight(blocks, borderLight{}, &w.blocksInfo)
+	m, numOpaque := genVertices(vertexGenInfo{0, 0, blocks, light,
+		borderLight{}, false, 0, &w.blocksInfo})
+	if len(m.indices) != 0 {
+		t.Fatalf("all air chunk has %d indices", len(m.indices))
+	}
+
+	// Handing the result to the world doesn't touch OpenGL, since there's
+	// nothing to upload
+	w.pending[chunkPos{0, 0}] = true
+	w.handleFinishedTask(blockVertexGenResult{0, 0, blocks, light, m,
+		numOpaque, 0})
+	chunk := w.FindChunk(0, 0)
+	if chunk == nil {
+		t.Fatal("all air chunk isn't tracked")
+	}
+	if !chunk.empty {
+		t.Error("all air chunk isn't marked empty")
+	}
+	if chunk.vao != 0 || chunk.vbo != 0 || chunk.ebo != 0 {
+		t.Error("all air chunk has buffers allocated")
+	}
+	if state := chunk.state(); state != ChunkLoaded {
+		t.Errorf("all air chunk in state %v, want ChunkLoaded", state)
+	}
+	if w.pending[chunkPos{0, 0}] {
+		t.Error("all air chunk is still pending")
+	}
+}
+
+func TestBlockDataAtOutOfBounds(t *testing.T) {
+	blocks := newBlockData(testHeight)
+	defer freeBlockData(blocks)
+	if blocks.At(15, testHeight-1, 15) == nil {
+		t.Error("no block at the far corner of the chunk")
+	}
+	outside := [][3]int{{-1, 0, 0}, {ChunkWidth, 0, 0}, {0, -1, 0},
+		{0, testHeight, 0}, {0, 1 << 30, 0}, {0, 0, -1}, {0, 0, ChunkDepth}}
+	for _, pos := range outside {
+		if blocks.At(pos[0], pos[1], pos[2]) != nil {
+			t.Errorf("got block outside the chunk at %v", pos)
+		}
+	}
+}
+
+func TestBlockDataAtTopLayer(t *testing.T) {
+	const height = 48
+	w := NewHeadless(height, 1)
+	chunk := loadTestChunk(w, 0, 0)
+	stone := testBlock(t, w, "Stone")
+	if !w.SetBlock(3, height-1, 11, stone) {
+		t.Fatal("failed to set block at the top of the world")
+	}
+
+	// The top layer is the last in the block list, laid out row by row
+	blocks := chunk.Blocks
+	if got := blocks.At(3, height-1, 11); got == nil || *got != stone {
+		t.Fatal("block set at the top of the world isn't there")
+	}
+	layer := len(blocks) - ChunkWidth*ChunkDepth
+	if blocks.At(3, height-1, 11) != &blocks[layer+11*ChunkWidth+3] {
+		t.Error("top layer block isn't in the last layer of the block list")
+	}
+	for i, block := range blocks[layer:] {
+		if block != Air && i != 11*ChunkWidth+3 {
+			t.Errorf("found block %d in the top layer at index %d", block, i)
+		}
+	}
+}
+
+func TestUploadChunkCountsVertices(t *testing.T) {
+	var uploaded *mesh
+	upload := uploadMesh
+	defer func() { uploadMesh = upload }()
+	uploadMesh = func(w *World, chunk *Chunk, m *mesh) {
+		uploaded = m
+	}
+
+	// A mesh of two quads
+	m := &mesh{}
+	for quad := 0; quad < 2; quad++ {
+		for i := 0; i < 4*valuesPerVertex; i++ {
+			m.vertices = append(m.vertices, float32(len(m.vertices)))
+		}
+		m.addQuad()
+	}
+	w := newTestWorld()
+	var chunk Chunk
+	w.uploadChunk(&chunk, m, 6)
+	if uploaded != m {
+		t.Fatal("mesh wasn't uploaded")
+	}
+	if chunk.numVertices != 8 || chunk.numIndices != 12 {
+		t.Errorf("got %d vertices and %d indices, want 8 and 12",
+			chunk.numVertices, chunk.numIndices)
+	}
+	if got := int(chunk.numVertices) * valuesPerVertex; got != len(m.vertices) {
+		t.Errorf("uploading %d values, want all %d", got, len(m.vertices))
+	}
+
+	// Nothing is uploaded for an empty mesh
+	uploaded = nil
+	w.uploadChunk(&chunk, &mesh{}, 0)
+	if uploaded != nil || chunk.numVertices != 0 || !chunk.empty {
+		t.Error("uploaded an empty mesh")
+	}
+}
+
+func TestIndexedMeshUsesLessMemory(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+
+	// Scatter some blocks over the floor, for a representative mix of faces
+	stone := testBlock(t, w, "Stone")
+	for x := 0; x < ChunkWidth; x += 3 {
+		for z := 0; z < ChunkDepth; z += 2 {
+			w.SetBlock(x, 3+x%2, z, stone)
+		}
+	}
+	m := genTestMesh(w, 0, 0, 0)
+	defer freeMesh(m)
+
+	// Each face shares two of its six corners between its triangles
+	numVertices := len(m.vertices) / valuesPerVertex
+	if numVertices*3 != len(m.indices)*2 {
+		t.Errorf("got %d vertices for %d indices, want two thirds as many",
+			numVertices, len(m.indices))
+	}
+
+	// Without indices, every index would be a whole vertex of its own
+	indexed := len(m.vertices)*4 + len(m.indices)*4
+	unindexed := len(m.indices) * valuesPerVertex * 4
+	if indexed*5 > unindexed*4 {
+		t.Errorf("indexed mesh takes %d bytes, more than 80%% of the %d "+
+			"bytes without indices", indexed, unindexed)
+	}
+}
+
+// AirResult returns the finished result of generating the chunk (p, q) as all
+// air, which can be handled without touching OpenGL.
+func airResult(w *World, p, q int) blockVertexGenResult {
+	blocks := newBlockData(w.Height)
+	light := genLight(blocks, borderLight{}, &w.blocksInfo)
+	m, numOpaque := genVertices(vertexGenInfo{p, q, blocks, light,
+		borderLight{}, false, 0, &w.blocksInfo})
+	return blockVertexGenResult{p, q, blocks, light, m, numOpaque, 0}
+}
+
+func TestUploadFinishedDrainsWithinBudget(t *testing.T) {
+	const numResults, limit = 5, 2
+	w := newTestWorld()
+	for p := 0; p < numResults; p++ {
+		w.pending[chunkPos{p, 0}] = true
+		w.finished = append(w.finished, airResult(w, p, 0))
+	}
+
+	// Each upload handles at most `limit` results, oldest first
+	uploaded := 0
+	for uploaded < numResults {
+		w.uploadFinished(limit)
+		want := uploaded + limit
+		if want > numResults {
+			want = numResults
+		}
+		for p := 0; p < numResults; p++ {
+			if loaded := w.FindChunk(p, 0) != nil; loaded != (p < want) {
+				t.Fatalf("chunk (%d, 0) loaded is %v after uploading %d "+
+					"results", p, loaded, want)
+			}
+		}
+		if len(w.finished) != numResults-want {
+			t.Fatalf("%d results waiting after uploading %d, want %d",
+				len(w.finished), want, numResults-want)
+		}
+		uploaded = want
+	}
+}
+
+func TestFadeFactor(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    float32
+	}{
+		{-time.Second, 0.0},
+		{0, 0.0},
+		{chunkFadeDuration / 4, 0.25},
+		{chunkFadeDuration / 2, 0.5},
+		{chunkFadeDuration, 1.0},
+		{time.Hour, 1.0},
+	}
+	for _, test := range tests {
+		if got := fadeFactor(test.elapsed); got != test.want {
+			t.Errorf("got fade factor %v after %v, want %v", got,
+				test.elapsed, test.want)
+		}
+	}
+}
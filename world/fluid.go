@@ -0,0 +1,216 @@
+package world
+
+const (
+	// The bits of a fluid block's state used to store its level. A level of 0
+	// is a source block, and the level increases by one for every block the
+	// fluid flows horizontally away from its source.
+	fluidLevelMask = 0xf
+
+	// The bit of a fluid block's state that's set if the fluid is falling
+	// straight down, rather than flowing outwards from a source.
+	fluidFallingBit = 0x10
+
+	// The number of update ticks between each step of fluid flow.
+	flowTicks = 5
+
+	// The height of the top surface of a source block, as a fraction of a full
+	// block. Flowing fluid is lower, depending on its level.
+	fluidSourceHeight = 8.0 / 9.0
+)
+
+// FluidLevel returns the level of a fluid block. A level of 0 is a source
+// block, and the level increases as the fluid flows away from its source.
+func (b Block) FluidLevel() int {
+	return int(b.State() & fluidLevelMask)
+}
+
+// FluidFalling returns true if a fluid block is falling straight down.
+func (b Block) FluidFalling() bool {
+	return b.State()&fluidFallingBit != 0
+}
+
+// IsFluidSource returns true if a fluid block is a source block, which never
+// dries up.
+func (b Block) IsFluidSource() bool {
+	return b.FluidLevel() == 0 && !b.FluidFalling()
+}
+
+// WithFluidLevel returns the same fluid block with a different level, and
+// either falling or not.
+func (b Block) WithFluidLevel(level int, falling bool) Block {
+	state := uint16(level) & fluidLevelMask
+	if falling {
+		state |= fluidFallingBit
+	}
+	return b.WithState(b.State()&^(fluidLevelMask|fluidFallingBit) | state)
+}
+
+// FluidHeight returns the height of a fluid block's top surface, as a fraction
+// of a full block, given how far the fluid can flow. Falling fluid fills the
+// whole block, and flowing fluid gets lower the further it is from its source.
+func fluidHeight(b Block, flowDistance int) float32 {
+	if b.FluidFalling() {
+		return 1.0
+	}
+	return fluidSourceHeight * float32(flowDistance+1-b.FluidLevel()) /
+		float32(flowDistance+1)
+}
+
+// CheckFlowing schedules the fluid at the given world-space coordinates, and
+// any fluid next to it, to flow on the next step of fluid flow. Called
+// whenever a block is changed, since the change may give neighbouring fluid
+// somewhere new to flow, or cut it off from its source.
+func (w *World) checkFlowing(wx, wy, wz int) {
+	w.scheduleFlow(wx, wy, wz)
+	for face := faceLeft; face <= faceBack; face++ {
+		nx, ny, nz := face.normal()
+		w.scheduleFlow(wx+nx, wy+ny, wz+nz)
+	}
+}
+
+// ScheduleFlow schedules the block at the given world-space coordinates to
+// flow on the next step of fluid flow, if it's a fluid.
+func (w *World) scheduleFlow(wx, wy, wz int) {
+	b, ok := w.GetBlock(wx, wy, wz)
+	if ok && w.blocksInfo.get(b).Fluid {
+		w.flowing[[3]int{wx, wy, wz}] = true
+	}
+}
+
+// UpdateFlowing performs a step of fluid flow every `flowTicks` update ticks,
+// for every fluid block scheduled to flow. Changing a block schedules its
+// neighbours for the next step, so fluid spreads outwards one block per step
+// until it settles.
+func (w *World) updateFlowing() {
+	if len(w.flowing) == 0 {
+		w.flowTimer = 0
+		return
+	}
+	w.flowTimer++
+	if w.flowTimer < flowTicks {
+		return
+	}
+	w.flowTimer = 0
+
+	// Flow the blocks in a fixed order, so fluid always spreads in the same way
+	positions := make([][3]int, 0, len(w.flowing))
+	for pos := range w.flowing {
+		positions = append(positions, pos)
+	}
+	sortBlockPositions(positions)
+	w.flowing = make(map[[3]int]bool)
+	for _, pos := range positions {
+		w.flow(pos[0], pos[1], pos[2])
+	}
+}
+
+// Flow performs a single step of fluid flow for the fluid at the given
+// world-space coordinates.
+//
+// Fluid that isn't a source block first updates its level based on the fluid
+// around it, drying up if it's been cut off from its source. The fluid then
+// flows straight down if it can. Otherwise, it spreads outwards into the
+// blocks next to it, one level higher than itself, until it reaches the
+// fluid's maximum flow distance.
+func (w *World) flow(wx, wy, wz int) {
+	b, ok := w.GetBlock(wx, wy, wz)
+	if !ok || !w.blocksInfo.get(b).Fluid {
+		return
+	}
+	if !b.IsFluidSource() {
+		supported := w.supportedFluid(wx, wy, wz, b)
+		if supported != b {
+			w.SetBlock(wx, wy, wz, supported)
+			return
+		}
+	}
+
+	// Flow straight down in preference to spreading out
+	if w.flowsDown(wx, wy, wz, b) {
+		falling := b.WithFluidLevel(0, true)
+		if w.canFlowInto(wx, wy-1, wz, falling) {
+			w.SetBlock(wx, wy-1, wz, falling)
+		}
+		return
+	}
+
+	// Spread out sideways, stopping at the fluid's maximum flow distance
+	level := b.FluidLevel() + 1
+	if b.FluidFalling() {
+		level = 1
+	}
+	if level > w.blocksInfo.get(b).FlowDistance {
+		return
+	}
+	flowing := b.WithFluidLevel(level, false)
+	for face := faceLeft; face <= faceBack; face++ {
+		nx, ny, nz := face.normal()
+		if ny == 0 && w.canFlowInto(wx+nx, wy, wz+nz, flowing) {
+			w.SetBlock(wx+nx, wy, wz+nz, flowing)
+		}
+	}
+}
+
+// FlowsDown returns true if the fluid at the given world-space coordinates
+// flows down into the block beneath it, rather than spreading out sideways.
+// Fluid flows down into air, and into fluid of the same type.
+func (w *World) flowsDown(wx, wy, wz int, fluid Block) bool {
+	below, ok := w.GetBlock(wx, wy-1, wz)
+	return ok && (below == Air || below.Type() == fluid.Type())
+}
+
+// CanFlowInto returns true if the given fluid block can replace the block at
+// the given world-space coordinates. Fluid can flow into air, and can replace
+// fluid of the same type that's further from its source (or that's flowing
+// rather than falling).
+func (w *World) canFlowInto(wx, wy, wz int, fluid Block) bool {
+	b, ok := w.GetBlock(wx, wy, wz)
+	if !ok {
+		return false
+	} else if b == Air {
+		return true
+	} else if b.Type() != fluid.Type() || b.IsFluidSource() ||
+		b.FluidFalling() {
+		return false
+	}
+	return fluid.FluidFalling() || b.FluidLevel() > fluid.FluidLevel()
+}
+
+// SupportedFluid works out what a non-source fluid block at the given
+// world-space coordinates should be, given the fluid around it. Fluid falls if
+// there's fluid of the same type above it, and otherwise flows outwards from
+// the nearest neighbouring fluid that's closer to its source. Returns air if
+// nothing is supplying the block with fluid.
+func (w *World) supportedFluid(wx, wy, wz int, fluid Block) Block {
+	above, ok := w.GetBlock(wx, wy+1, wz)
+	if ok && above.Type() == fluid.Type() {
+		return fluid.WithFluidLevel(0, true)
+	}
+
+	// Find the lowest level of fluid flowing sideways into this block. Falling
+	// fluid spreads out like a source block once it lands, but doesn't spread
+	// out at all while it's still falling
+	best := -1
+	for face := faceLeft; face <= faceBack; face++ {
+		nx, ny, nz := face.normal()
+		if ny != 0 {
+			continue
+		}
+		n, ok := w.GetBlock(wx+nx, wy, wz+nz)
+		if !ok || n.Type() != fluid.Type() ||
+			w.flowsDown(wx+nx, wy, wz+nz, n) {
+			continue
+		}
+		level := n.FluidLevel()
+		if n.FluidFalling() {
+			level = 0
+		}
+		if best < 0 || level < best {
+			best = level
+		}
+	}
+	if best < 0 || best+1 > w.blocksInfo.get(fluid).FlowDistance {
+		return Air
+	}
+	return fluid.WithFluidLevel(best+1, false)
+}
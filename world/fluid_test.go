@@ -0,0 +1,77 @@
+package world
+
+import "testing"
+
+// TickFlowing runs the given number of steps of fluid flow.
+func tickFlowing(w *World, steps int) {
+	for i := 0; i < steps*flowTicks; i++ {
+		w.updateFlowing()
+	}
+}
+
+func TestWaterSpreadsFromSource(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	water := testBlock(t, w, "Water")
+	flowDistance := w.blocksInfo.get(water).FlowDistance
+
+	// A source block on the floor at y = 3
+	w.SetBlock(8, 3, 8, water)
+	tickFlowing(w, 2*flowDistance)
+
+	// The level rises by one for each block away from the source, until the
+	// water stops at its maximum flow distance
+	for dx := 0; dx <= flowDistance; dx++ {
+		b, _ := w.GetBlock(8-dx, 3, 8)
+		if b.Type() != water || b.FluidLevel() != dx || b.FluidFalling() {
+			t.Errorf("got block %v %d blocks from the source, want water at "+
+				"level %d", b, dx, dx)
+		}
+	}
+	if b, _ := w.GetBlock(8-flowDistance-1, 3, 8); b != Air {
+		t.Errorf("water flowed further than its maximum flow distance")
+	}
+	if b, _ := w.GetBlock(8, 4, 8); b != Air {
+		t.Errorf("water flowed upwards")
+	}
+}
+
+func TestWaterFallsThenSpreads(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	water := testBlock(t, w, "Water")
+
+	// A source block floating above the floor at y = 3
+	w.SetBlock(8, 8, 8, water)
+	tickFlowing(w, 20)
+	for y := 3; y < 8; y++ {
+		b, _ := w.GetBlock(8, y, 8)
+		if b.Type() != water || !b.FluidFalling() {
+			t.Errorf("got block %v at y = %d, want falling water", b, y)
+		}
+	}
+
+	// Falling water spreads out like a source once it lands
+	if b, _ := w.GetBlock(9, 3, 8); b.Type() != water || b.FluidLevel() != 1 {
+		t.Errorf("got block %v next to the landed water, want level 1", b)
+	}
+	if b, _ := w.GetBlock(9, 4, 8); b != Air {
+		t.Errorf("falling water spread out before it landed")
+	}
+}
+
+func TestWaterDriesUpWithoutSource(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	water := testBlock(t, w, "Water")
+	w.SetBlock(8, 3, 8, water)
+	tickFlowing(w, 20)
+
+	w.SetBlock(8, 3, 8, Air)
+	tickFlowing(w, 40)
+	for x := 0; x < ChunkWidth; x++ {
+		if b, _ := w.GetBlock(x, 3, 8); b != Air {
+			t.Errorf("got block %v at x = %d after removing the source", b, x)
+		}
+	}
+}
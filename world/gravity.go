@@ -0,0 +1,91 @@
+package world
+
+import (
+	"sort"
+)
+
+// The number of update ticks it takes a falling block to move down by one
+// block.
+const fallTicks = 2
+
+// CheckFalling starts the block at the given world-space coordinates falling
+// if it's affected by gravity and there's air beneath it. Called whenever a
+// block is changed, for both the changed block and the block above it (which
+// may have lost its support).
+//
+// Any blocks affected by gravity stacked on top of the falling block start
+// falling too, so that the whole stack falls together.
+func (w *World) checkFalling(wx, wy, wz int) {
+	if !w.shouldFall(wx, wy, wz) {
+		return
+	}
+	for y := wy; w.hasGravity(wx, y, wz); y++ {
+		w.falling[[3]int{wx, y, wz}] = true
+	}
+}
+
+// HasGravity returns true if the block at the given world-space coordinates
+// is affected by gravity.
+func (w *World) hasGravity(wx, wy, wz int) bool {
+	b, ok := w.GetBlock(wx, wy, wz)
+	return ok && w.blocksInfo.get(b).Gravity
+}
+
+// ShouldFall returns true if the block at the given world-space coordinates is
+// affected by gravity and has air directly beneath it. Blocks don't fall out
+// of the bottom of the world, or into chunks that aren't loaded.
+func (w *World) shouldFall(wx, wy, wz int) bool {
+	if !w.hasGravity(wx, wy, wz) {
+		return false
+	}
+	below, ok := w.GetBlock(wx, wy-1, wz)
+	return ok && below == Air
+}
+
+// UpdateFalling moves every falling block down by one block, every
+// `fallTicks` update ticks. A block stops falling once it lands on top of
+// something that isn't air.
+func (w *World) updateFalling() {
+	if len(w.falling) == 0 {
+		w.fallTimer = 0
+		return
+	}
+	w.fallTimer++
+	if w.fallTimer < fallTicks {
+		return
+	}
+	w.fallTimer = 0
+
+	// Move the lowest blocks first, so that each block in a falling stack
+	// leaves a gap for the block above it to fall into on the same tick.
+	// Moving a block down adds it (and anything above it) back into the set of
+	// falling blocks if it's still falling
+	positions := make([][3]int, 0, len(w.falling))
+	for pos := range w.falling {
+		positions = append(positions, pos)
+	}
+	sortBlockPositions(positions)
+	w.falling = make(map[[3]int]bool)
+	for _, pos := range positions {
+		if !w.shouldFall(pos[0], pos[1], pos[2]) {
+			continue // Already landed, or was replaced
+		}
+		b, _ := w.GetBlock(pos[0], pos[1], pos[2])
+		w.SetBlock(pos[0], pos[1], pos[2], Air)
+		w.SetBlock(pos[0], pos[1]-1, pos[2], b)
+	}
+}
+
+// SortBlockPositions sorts a list of world-space block coordinates from the
+// bottom of the world upwards, by y, then x, then z.
+func sortBlockPositions(positions [][3]int) {
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		} else if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		return a[2] < b[2]
+	})
+}
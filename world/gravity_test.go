@@ -0,0 +1,57 @@
+package world
+
+import "testing"
+
+// TickFalling runs the falling block updates for the given number of update
+// ticks.
+func tickFalling(w *World, ticks int) {
+	for i := 0; i < ticks; i++ {
+		w.updateFalling()
+	}
+}
+
+func TestSandFallsWhenUnsupported(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	stone := testBlock(t, w, "Stone")
+	sand := testBlock(t, w, "Sand")
+	gravel := testBlock(t, w, "Gravel")
+
+	// A stack of sand and gravel resting on a stone pillar, above the floor at
+	// y = 3
+	w.SetBlock(5, 3, 5, stone)
+	w.SetBlock(5, 4, 5, stone)
+	w.SetBlock(5, 5, 5, sand)
+	w.SetBlock(5, 6, 5, gravel)
+	tickFalling(w, 10*fallTicks)
+	if b, _ := w.GetBlock(5, 5, 5); b != sand {
+		t.Fatal("supported sand fell")
+	}
+
+	// Breaking the pillar drops the whole stack onto the floor
+	w.BreakBlock(5, 4, 5)
+	w.BreakBlock(5, 3, 5)
+	tickFalling(w, 10*fallTicks)
+	want := map[int]Block{3: sand, 4: gravel, 5: Air, 6: Air}
+	for y, block := range want {
+		if b, _ := w.GetBlock(5, y, 5); b != block {
+			t.Errorf("got block %v at y = %d, want %v", b, y, block)
+		}
+	}
+	if len(w.falling) != 0 {
+		t.Errorf("%d blocks still falling after landing", len(w.falling))
+	}
+}
+
+func TestFallingTakesTime(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	sand := testBlock(t, w, "Sand")
+	w.SetBlock(5, 10, 5, sand)
+
+	// The sand moves down a block every `fallTicks` ticks
+	tickFalling(w, 3*fallTicks)
+	if b, _ := w.GetBlock(5, 7, 5); b != sand {
+		t.Errorf("sand isn't 3 blocks lower after %d ticks", 3*fallTicks)
+	}
+}
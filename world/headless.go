@@ -0,0 +1,166 @@
+package world
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// NewHeadless creates a world `height` blocks tall without any OpenGL
+// resources or worker goroutines, for tools and tests that only need the
+// world's blocks (like moving entities around). No chunks are loaded until
+// `LoadChunkHeadless` is called. The world must never be rendered.
+func NewHeadless(height int, seed int64) *World {
+	w := &World{
+		Height:     height,
+		Seed:       seed,
+		chunks:     make(map[chunkPos]*Chunk),
+		blocksInfo: loadBlockProperties(),
+		pending:    make(map[chunkPos]bool),
+		pinned:     make(map[chunkPos]bool),
+		falling:    make(map[[3]int]bool),
+		flowing:    make(map[[3]int]bool),
+		rand:       rand.New(rand.NewSource(seed)),
+	}
+	w.registerTickHandlers()
+	return w
+}
+
+// LoadChunkHeadless generates the block and light data for the chunk (p, q) on
+// the calling goroutine, replacing the chunk if it's already loaded. No vertex
+// data is generated. Light from the chunk doesn't spill into neighbouring
+// chunks that are already loaded, so chunks should be loaded before any
+// blocks are changed.
+func (w *World) LoadChunkHeadless(p, q int) {
+	chunk := newChunk()
+	chunk.Blocks = w.loadBlocks(p, q)
+	chunk.light = genLight(chunk.Blocks, w.borderLight(p, q), &w.blocksInfo)
+	chunk.opaqueFaces = opaqueFaces(chunk.Blocks, &w.blocksInfo)
+	w.chunks[chunkPos{p, q}] = chunk
+	w.updateEnclosedAround(p, q)
+}
+
+// GenChunksHeadless synchronously generates the block, light, and vertex data
+// for every chunk within `radius` chunks of the centre of the world, without
+// using any worker goroutines or OpenGL. The chunks are always processed in
+// the same order, so the output is completely deterministic.
+//
+// Returns the mesh for each chunk, indexed by chunk position.
+func genChunksHeadless(blocksInfo *BlocksInfo, radius, height int,
+	smooth bool) map[chunkPos]*mesh {
+	// Only fill in what's needed to generate the chunks; the world has no
+	// OpenGL resources
+	w := &World{Height: height, chunks: make(map[chunkPos]*Chunk)}
+
+	// Generate block data for every chunk
+	positions := headlessChunkPositions(radius)
+	for _, pos := range positions {
+		chunk := newChunk()
+		chunk.Blocks = genBlocks(pos.p, pos.q, height)
+		w.chunks[pos] = chunk
+	}
+
+	// Calculate each chunk's light, recalculating a chunk's neighbours until
+	// the light along every chunk border settles, in the same way as when
+	// chunks are loaded on the worker goroutines
+	dirty := positions
+	for len(dirty) > 0 {
+		next := make(map[chunkPos]bool)
+		for _, pos := range dirty {
+			chunk := w.chunks[pos]
+			border := w.borderLight(pos.p, pos.q)
+			light := genLight(chunk.Blocks, border, blocksInfo)
+			for face := faceLeft; face <= faceBack; face++ {
+				nx, ny, nz := face.normal()
+				neighbour := chunkPos{pos.p + nx, pos.q + nz}
+				if ny == 0 && w.chunks[neighbour] != nil &&
+					sideLightChanged(chunk.light, light, face) {
+					next[neighbour] = true
+				}
+			}
+			chunk.light = light
+		}
+
+		// Keep the processing order deterministic
+		dirty = nil
+		for pos := range next {
+			dirty = append(dirty, pos)
+		}
+		sortChunkPositions(dirty)
+	}
+
+	// Generate each chunk's vertex data
+	meshes := make(map[chunkPos]*mesh)
+	for _, pos := range positions {
+		chunk := w.chunks[pos]
+		meshes[pos], _ = genVertices(vertexGenInfo{pos.p, pos.q, chunk.Blocks,
+			chunk.light, w.borderLight(pos.p, pos.q), smooth, 0, blocksInfo})
+	}
+	return meshes
+}
+
+// HeadlessChunkPositions returns the position of every chunk within `radius`
+// chunks of the centre of the world, in a fixed order.
+func headlessChunkPositions(radius int) []chunkPos {
+	var positions []chunkPos
+	for p := -radius; p <= radius; p++ {
+		for q := -radius; q <= radius; q++ {
+			if p*p+q*q <= radius*radius {
+				positions = append(positions, chunkPos{p, q})
+			}
+		}
+	}
+	return positions
+}
+
+// SortChunkPositions sorts a list of chunk positions by p, then q.
+func sortChunkPositions(positions []chunkPos) {
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].p != positions[j].p {
+			return positions[i].p < positions[j].p
+		}
+		return positions[i].q < positions[j].q
+	})
+}
+
+// MeshDigestRadius is the radius, in chunks, of the area around the centre of
+// the world whose vertex data is summarised by the saved golden digest.
+const MeshDigestRadius = 4
+
+// MeshDigest generates every chunk within `radius` chunks of the centre of the
+// world without OpenGL, and returns a summary of the generated vertex data,
+// with one line per chunk listing the chunk's position, its number of
+// vertices, and a SHA-256 hash of its vertex data followed by its indices.
+//
+// Comparing the digest against a previously saved copy catches any change to
+// world generation, lighting, or meshing, so that changes to the vertex data
+// are always made deliberately.
+func MeshDigest(radius, height int) string {
+	// Load the block properties and lay out the texture atlas, which sets each
+	// block's UV coordinates, but don't upload anything to the GPU
+	blocksInfo := loadBlockProperties()
+	buildBlockAtlas(blocksInfo, loadBlockTexture)
+
+	// Hash each chunk's vertex data
+	chunks := genChunksHeadless(&blocksInfo, radius, height, false)
+	digest := ""
+	for _, pos := range headlessChunkPositions(radius) {
+		m := chunks[pos]
+		hash := sha256.New()
+		buf := make([]byte, 4)
+		for _, v := range m.vertices {
+			binary.LittleEndian.PutUint32(buf, math.Float32bits(v))
+			hash.Write(buf)
+		}
+		for _, index := range m.indices {
+			binary.LittleEndian.PutUint32(buf, index)
+			hash.Write(buf)
+		}
+		digest += fmt.Sprintf("%d %d %d %x\n", pos.p, pos.q,
+			len(m.vertices)/valuesPerVertex, hash.Sum(nil))
+	}
+	return digest
+}
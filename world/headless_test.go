@@ -0,0 +1,36 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/benanders/mineral/asset"
+)
+
+func TestMeshDigest(t *testing.T) {
+	// The digest depends on the layout of the block atlas, so it can't be
+	// generated without the textures extracted by `buildAssets.go`
+	if _, err := asset.AssetDir("textures"); err != nil {
+		t.Skip("block textures haven't been extracted")
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "spawn_mesh.digest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Report the first chunk whose vertex data changed
+	got := MeshDigest(MeshDigestRadius, DefaultHeight)
+	gotLines := strings.Split(got, "\n")
+	wantLines := strings.Split(string(want), "\n")
+	for i := 0; i < len(gotLines) && i < len(wantLines); i++ {
+		if gotLines[i] != wantLines[i] {
+			t.Fatalf("got chunk %q, want %q (run `make golden-mesh` if the "+
+				"change is deliberate)", gotLines[i], wantLines[i])
+		}
+	}
+	if len(gotLines) != len(wantLines) {
+		t.Fatalf("got %d chunks, want %d", len(gotLines)-1, len(wantLines)-1)
+	}
+}
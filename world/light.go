@@ -0,0 +1,371 @@
+package world
+
+// MaxLight is the brightest light level a block can have, which is the light
+// level of every block with direct access to the sky.
+const maxLight = 15
+
+// LightData stores the light level of every block within a chunk, using the
+// same layout as the chunk's block data.
+type lightData []uint8
+
+// NewLightData creates a new, completely dark light array for a chunk of the
+// given height.
+func newLightData(height int) lightData {
+	return make([]uint8, ChunkWidth*height*ChunkDepth)
+}
+
+// Height returns the height of the chunk the light array belongs to, which is
+// implied by the length of the array.
+func (l lightData) height() int {
+	return len(l) / (ChunkWidth * ChunkDepth)
+}
+
+// At returns the light level at the given coordinate within the light array.
+// If the given coordinates are outside the light array's boundaries, then
+// returns nil.
+func (l lightData) At(x, y, z int) *uint8 {
+	if x < 0 || x >= ChunkWidth ||
+		y < 0 || y >= l.height() ||
+		z < 0 || z >= ChunkDepth {
+		return nil
+	}
+	return &l[y*ChunkWidth*ChunkDepth+z*ChunkWidth+x]
+}
+
+// BorderLight stores the light levels of the blocks just outside each of the
+// four vertical sides of a chunk, taken from the neighbouring chunks. It's
+// indexed by block face (only the left, right, front, and back faces are
+// used), and each side is indexed by `y*ChunkWidth + i`, where `i` is the
+// position along the side. A nil side means the neighbouring chunk isn't
+// loaded.
+//
+// Light is calculated separately for each chunk on a worker goroutine, using
+// only the chunk's own block data and a copy of its neighbours' border light
+// levels (taken when the job is handed to a worker). Light from a neighbour
+// seeds the flood fill along the chunk's sides, so light propagates across
+// chunk borders. When a chunk first loads, or the light along one of its sides
+// changes, the neighbouring chunks on those sides are regenerated so that
+// they receive the new light. Light that's removed is cleared from every
+// chunk it reached first (see `unlight`), so it can't bounce back across a
+// border.
+type borderLight [6][]uint8
+
+// BorderIndex returns the index into one side of the border light for the
+// block at (x, y, z), which must lie on the corresponding side of the chunk.
+func borderIndex(face blockFace, x, y, z int) int {
+	if face == faceLeft || face == faceRight {
+		return y*ChunkWidth + z
+	}
+	return y*ChunkWidth + x
+}
+
+// BorderLight copies the light levels along each side of the chunk that
+// border the chunk (p, q) out of the neighbouring chunks, if they're loaded.
+func (w *World) borderLight(p, q int) borderLight {
+	var border borderLight
+	for face := faceLeft; face <= faceBack; face++ {
+		// Skip the top and bottom faces
+		nx, ny, nz := face.normal()
+		if ny != 0 {
+			continue
+		}
+
+		// Check the neighbour has some light data
+		neighbour := w.FindChunk(p+nx, q+nz)
+		if neighbour == nil || neighbour.light == nil {
+			continue
+		}
+
+		// Copy the neighbour's blocks that touch this chunk
+		height := neighbour.light.height()
+		side := make([]uint8, height*ChunkWidth)
+		for y := 0; y < height; y++ {
+			for i := 0; i < ChunkWidth; i++ {
+				var x, z int
+				switch face {
+				case faceLeft:
+					x, z = ChunkWidth-1, i
+				case faceRight:
+					x, z = 0, i
+				case faceFront:
+					x, z = i, 0
+				case faceBack:
+					x, z = i, ChunkDepth-1
+				}
+				side[y*ChunkWidth+i] = *neighbour.light.At(x, y, z)
+			}
+		}
+		border[face] = side
+	}
+	return border
+}
+
+// GenLight calculates the light level of every block in a chunk. Skylight
+// floods straight down each column from the top of the world until it hits
+// an opaque block, and then spreads out horizontally (and down) into shadowed
+// areas, losing one light level for every block it travels. Light emitting
+// blocks spread their light in the same way.
+//
+// Light is always recalculated from scratch for the whole chunk, so removing
+// a light source (or placing a block that casts a shadow) darkens the area it
+// used to light within the chunk. Light that spread into neighbouring chunks
+// is removed by `unlight` first.
+func genLight(blocks blockData, border borderLight,
+	blocksInfo *BlocksInfo) lightData {
+	height := blocks.height()
+	light := newLightData(height)
+	var queue []int
+
+	// Flood skylight down each column
+	for x := 0; x < ChunkWidth; x++ {
+		for z := 0; z < ChunkDepth; z++ {
+			for y := height - 1; y >= 0; y-- {
+				if !blocksInfo.get(*blocks.At(x, y, z)).Transparent {
+					break
+				}
+				*light.At(x, y, z) = maxLight
+				queue = append(queue, lightIndex(x, y, z))
+			}
+		}
+	}
+
+	// Seed the flood fill with light emitted by blocks like torches. Block
+	// light and skylight share the same light level, with the brighter of the
+	// two winning
+	for x := 0; x < ChunkWidth; x++ {
+		for y := 0; y < height; y++ {
+			for z := 0; z < ChunkDepth; z++ {
+				emission := blocksInfo.get(*blocks.At(x, y, z)).LightEmission
+				if emission > maxLight {
+					emission = maxLight
+				}
+				if level := light.At(x, y, z); *level < emission {
+					*level = emission
+					queue = append(queue, lightIndex(x, y, z))
+				}
+			}
+		}
+	}
+
+	// Seed the flood fill with light coming in from neighbouring chunks
+	for face := faceLeft; face <= faceBack; face++ {
+		if border[face] == nil {
+			continue
+		}
+		for y := 0; y < height; y++ {
+			for i := 0; i < ChunkWidth; i++ {
+				// Find the block on this side of the chunk
+				var x, z int
+				switch face {
+				case faceLeft:
+					x, z = 0, i
+				case faceRight:
+					x, z = ChunkWidth-1, i
+				case faceFront:
+					x, z = i, ChunkDepth-1
+				case faceBack:
+					x, z = i, 0
+				default:
+					continue
+				}
+
+				// Light the block if it's darker than its neighbour
+				incoming := border[face][y*ChunkWidth+i]
+				if incoming <= 1 ||
+					!blocksInfo.get(*blocks.At(x, y, z)).Transparent {
+					continue
+				}
+				if level := light.At(x, y, z); *level < incoming-1 {
+					*level = incoming - 1
+					queue = append(queue, lightIndex(x, y, z))
+				}
+			}
+		}
+	}
+
+	// Spread the light out into neighbouring transparent blocks
+	spreadLight(light, blocks, blocksInfo, queue)
+	return light
+}
+
+// SpreadLight performs a breadth first flood fill of light, starting at the
+// given blocks, into all neighbouring transparent blocks. Light decreases by
+// one level for every block it travels.
+func spreadLight(light lightData, blocks blockData, blocksInfo *BlocksInfo,
+	queue []int) {
+	for len(queue) > 0 {
+		x, y, z := lightCoords(queue[0])
+		queue = queue[1:]
+
+		// Check there's enough light left to spread
+		level := *light.At(x, y, z)
+		if level <= 1 {
+			continue
+		}
+
+		// Spread to each neighbour
+		for face := faceLeft; face <= faceBack; face++ {
+			nx, ny, nz := face.normal()
+			bx, by, bz := x+nx, y+ny, z+nz
+			neighbour := light.At(bx, by, bz)
+			if neighbour == nil || *neighbour >= level-1 ||
+				!blocksInfo.get(*blocks.At(bx, by, bz)).Transparent {
+				continue
+			}
+			*neighbour = level - 1
+			queue = append(queue, lightIndex(bx, by, bz))
+		}
+	}
+}
+
+// BlockLight returns a pointer to the light level of the block at the given
+// world-space coordinates, or nil if the chunk containing the block isn't
+// loaded or has no light data yet.
+func (w *World) blockLight(wx, wy, wz int) *uint8 {
+	p, q, x, y, z := ToChunkSpace(wx, wy, wz)
+	chunk := w.FindChunk(p, q)
+	if chunk == nil || chunk.light == nil {
+		return nil
+	}
+	return chunk.light.At(x, y, z)
+}
+
+// Unlight removes the light that spread out from the block at the given
+// world-space coordinates, in every loaded chunk, and regenerates each chunk
+// it darkens. It's called before a block changes in a way that might make the
+// area around it darker, like removing a light source or placing a block that
+// casts a shadow.
+//
+// Light removal is a breadth first search outwards from the block, clearing
+// every block whose light is dimmer than the block it was reached from (and
+// so could have come from it), as well as skylight falling straight down. The
+// regenerated chunks then fill the darkened area back in from whatever light
+// sources remain. Without this, stale light in a neighbouring chunk would
+// flow back across the chunk border, and only fade one level each time the
+// chunks on either side of the border were regenerated.
+func (w *World) unlight(wx, wy, wz int) {
+	type lit struct {
+		x, y, z int
+		level   uint8
+	}
+	start := w.blockLight(wx, wy, wz)
+	if start == nil || *start == 0 {
+		return
+	}
+	queue := []lit{{wx, wy, wz, *start}}
+	*start = 0
+
+	darkened := make(map[chunkPos]bool)
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		p, q, _, _, _ := ToChunkSpace(b.x, b.y, b.z)
+		darkened[chunkPos{p, q}] = true
+
+		// Clear each neighbour that was lit from this block
+		for face := faceLeft; face <= faceBack; face++ {
+			nx, ny, nz := face.normal()
+			x, y, z := b.x+nx, b.y+ny, b.z+nz
+			level := w.blockLight(x, y, z)
+			if level == nil || *level == 0 {
+				continue
+			}
+			skylight := ny < 0 && b.level == maxLight && *level == maxLight
+			if *level < b.level || skylight {
+				queue = append(queue, lit{x, y, z, *level})
+				*level = 0
+			}
+		}
+	}
+	for pos := range darkened {
+		w.regenChunk(pos.p, pos.q)
+	}
+}
+
+// SideLightChanged returns true if the light levels along the given side of a
+// chunk differ between two light arrays.
+func sideLightChanged(before, after lightData, face blockFace) bool {
+	if before == nil {
+		return true
+	}
+	for y := 0; y < after.height(); y++ {
+		for i := 0; i < ChunkWidth; i++ {
+			var x, z int
+			switch face {
+			case faceLeft:
+				x, z = 0, i
+			case faceRight:
+				x, z = ChunkWidth-1, i
+			case faceFront:
+				x, z = i, ChunkDepth-1
+			case faceBack:
+				x, z = i, 0
+			}
+			if *before.At(x, y, z) != *after.At(x, y, z) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LightIndex packs a coordinate within a chunk into a single index, for use
+// in the light flood fill queue.
+func lightIndex(x, y, z int) int {
+	return y*ChunkWidth*ChunkDepth + z*ChunkWidth + x
+}
+
+// LightCoords unpacks a light flood fill queue index into a coordinate within
+// a chunk.
+func lightCoords(index int) (x, y, z int) {
+	x = index % ChunkWidth
+	z = (index / ChunkWidth) % ChunkDepth
+	y = index / (ChunkWidth * ChunkDepth)
+	return
+}
+
+// LightAt returns the light level of the block at the given coordinates
+// relative to the chunk being meshed, which may lie just outside the chunk.
+func lightAt(info vertexGenInfo, x, y, z int) uint8 {
+	// Everything above the world is lit by the sky, and everything below it
+	// is dark
+	if y >= info.light.height() {
+		return maxLight
+	} else if y < 0 {
+		return 0
+	}
+
+	// Blocks within the chunk
+	if level := info.light.At(x, y, z); level != nil {
+		return *level
+	}
+
+	// Blocks diagonally across a chunk corner aren't included in the border
+	// light, so use the closest block that is
+	if (x < 0 || x >= ChunkWidth) && (z < 0 || z >= ChunkDepth) {
+		if z < 0 {
+			z = 0
+		} else {
+			z = ChunkDepth - 1
+		}
+	}
+
+	// Blocks in a neighbouring chunk
+	var face blockFace
+	switch {
+	case x < 0:
+		face = faceLeft
+	case x >= ChunkWidth:
+		face = faceRight
+	case z < 0:
+		face = faceBack
+	default:
+		face = faceFront
+	}
+	if info.border[face] == nil {
+		// Assume faces bordering unloaded chunks are lit, since they'll
+		// usually be hidden once the neighbour loads anyway
+		return maxLight
+	}
+	return info.border[face][borderIndex(face, x, y, z)]
+}
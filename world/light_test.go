@@ -0,0 +1,170 @@
+package world
+
+import "testing"
+
+func TestOverhangCastsShadow(t *testing.T) {
+	w := newTestWorld()
+	stone := testBlock(t, w, "Stone")
+
+	// A stone floor, with a roof 8 blocks up over half of the chunk
+	blocks := genBlocks(0, 0, w.Height)
+	for x := 0; x < ChunkWidth/2; x++ {
+		for z := 0; z < ChunkDepth; z++ {
+			*blocks.At(x, 10, z) = stone
+		}
+	}
+	light := genLight(blocks, borderLight{}, &w.blocksInfo)
+
+	exposed := *light.At(12, 5, 8)
+	if exposed != maxLight {
+		t.Errorf("exposed block has light %d, want %d", exposed, maxLight)
+	}
+	shadowed := *light.At(2, 5, 8)
+	if shadowed >= exposed {
+		t.Errorf("block under overhang has light %d, not less than %d",
+			shadowed, exposed)
+	}
+
+	// Light spreading in from the open side loses a level per block
+	edge := *light.At(ChunkWidth/2-1, 5, 8)
+	if edge != maxLight-1 {
+		t.Errorf("block at edge of overhang has light %d, want %d", edge,
+			maxLight-1)
+	}
+	if shadowed >= edge {
+		t.Errorf("block deeper under overhang has light %d, not less than "+
+			"%d", shadowed, edge)
+	}
+}
+
+func TestLightCrossesChunkBorders(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+
+	// A chunk roofed over entirely still receives light from its neighbour
+	stone := testBlock(t, w, "Stone")
+	blocks := genBlocks(1, 0, w.Height)
+	for x := 0; x < ChunkWidth; x++ {
+		for z := 0; z < ChunkDepth; z++ {
+			*blocks.At(x, 10, z) = stone
+		}
+	}
+	light := genLight(blocks, w.borderLight(1, 0), &w.blocksInfo)
+	if got := *light.At(0, 5, 8); got != maxLight-1 {
+		t.Errorf("block next to lit neighbour has light %d, want %d", got,
+			maxLight-1)
+	}
+	if got := *light.At(ChunkWidth-1, 5, 8); got >= maxLight-1 {
+		t.Errorf("block far from lit neighbour has light %d", got)
+	}
+
+	// Without the neighbour's light the chunk is dark
+	dark := genLight(blocks, borderLight{}, &w.blocksInfo)
+	if got := *dark.At(0, 5, 8); got != 0 {
+		t.Errorf("enclosed block has light %d without neighbour, want 0",
+			got)
+	}
+}
+
+// CaveRoof is the height of the roof of the caves made by `loadCaveChunk`.
+const caveRoof = 7
+
+// LoadCaveChunk loads the chunk (p, q) into a headless world, and fills it
+// with stone from `caveRoof` up to the top of the world. This leaves a
+// completely dark cave between the floor and the roof.
+func loadCaveChunk(t *testing.T, w *World, p, q int) {
+	chunk := loadTestChunk(w, p, q)
+	stone := testBlock(t, w, "Stone")
+	for x := 0; x < ChunkWidth; x++ {
+		for z := 0; z < ChunkDepth; z++ {
+			for y := caveRoof; y < w.Height; y++ {
+				*chunk.Blocks.At(x, y, z) = stone
+			}
+		}
+	}
+	chunk.light = genLight(chunk.Blocks, w.borderLight(p, q), &w.blocksInfo)
+}
+
+// SettleLight runs every queued chunk regeneration on the calling goroutine,
+// recalculating each chunk's light and passing any change along its sides on
+// to its neighbours in the same way as `handleFinishedTask`, until the light
+// settles. Returns the number of regenerations.
+func settleLight(w *World) int {
+	regens := 0
+	for len(w.queue) > 0 {
+		job := w.queue.pop()
+		chunk := w.FindChunk(job.pos.p, job.pos.q)
+		border := w.borderLight(job.pos.p, job.pos.q)
+		light := genLight(chunk.Blocks, border, &w.blocksInfo)
+		for face := faceLeft; face <= faceBack; face++ {
+			nx, ny, nz := face.normal()
+			if ny == 0 && sideLightChanged(chunk.light, light, face) {
+				w.regenChunk(job.pos.p+nx, job.pos.q+nz)
+			}
+		}
+		chunk.light = light
+		regens++
+	}
+	return regens
+}
+
+func TestLightSourceLightsCave(t *testing.T) {
+	w := newTestWorld()
+	loadCaveChunk(t, w, 0, 0)
+	glowstone := testBlock(t, w, "Glowstone")
+	if got := *w.blockLight(10, 4, 8); got != 0 {
+		t.Fatalf("cave has light %d before placing glowstone, want 0", got)
+	}
+
+	// The light spreads out from the glowstone, losing a level per block
+	w.SetBlock(8, 4, 8, glowstone)
+	settleLight(w)
+	if got := *w.blockLight(10, 4, 8); got != maxLight-2 {
+		t.Errorf("block 2 away from glowstone has light %d, want %d", got,
+			maxLight-2)
+	}
+	if got := *w.blockLight(8, 6, 12); got != maxLight-6 {
+		t.Errorf("block 6 away from glowstone has light %d, want %d", got,
+			maxLight-6)
+	}
+
+	// Removing the glowstone leaves the cave dark again
+	w.BreakBlock(8, 4, 8)
+	settleLight(w)
+	for _, pos := range [][3]int{{8, 4, 8}, {10, 4, 8}, {8, 6, 12}} {
+		if got := *w.blockLight(pos[0], pos[1], pos[2]); got != 0 {
+			t.Errorf("block at %v has light %d after removing glowstone, "+
+				"want 0", pos, got)
+		}
+	}
+}
+
+func TestLightSourceAcrossChunkBorder(t *testing.T) {
+	w := newTestWorld()
+	loadCaveChunk(t, w, 0, 0)
+	loadCaveChunk(t, w, 1, 0)
+	glowstone := testBlock(t, w, "Glowstone")
+
+	// Glowstone on the edge of one chunk lights up the next
+	w.SetBlock(ChunkWidth-1, 4, 8, glowstone)
+	settleLight(w)
+	if got := *w.blockLight(ChunkWidth+1, 4, 8); got != maxLight-2 {
+		t.Errorf("block in neighbouring chunk has light %d, want %d", got,
+			maxLight-2)
+	}
+
+	// Removing it darkens both chunks, without the stale light bouncing back
+	// and forth across the border
+	w.BreakBlock(ChunkWidth-1, 4, 8)
+	if regens := settleLight(w); regens > 4 {
+		t.Errorf("took %d chunk regenerations for the light to settle, "+
+			"want at most 4", regens)
+	}
+	for _, x := range []int{ChunkWidth - 2, ChunkWidth - 1, ChunkWidth,
+		ChunkWidth + 1} {
+		if got := *w.blockLight(x, 4, 8); got != 0 {
+			t.Errorf("block at x = %d has light %d after removing "+
+				"glowstone, want 0", x, got)
+		}
+	}
+}
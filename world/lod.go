@@ -0,0 +1,211 @@
+package world
+
+// The fractions of the render radius beyond which chunks are meshed at each
+// reduced level of detail. Chunks closer than the first distance are meshed
+// at full detail (level 0), and each level of detail after that halves the
+// resolution of the chunk's mesh along every axis. Scaling the distances with
+// the render radius means the outer chunks are always simplified, however far
+// the player can see.
+var lodFractions = [...]float32{0.5, 0.75}
+
+// MinLODDistance is the distance, in chunks, from the player's chunk within
+// which chunks are always meshed at full detail, so that small render radii
+// don't simplify the chunks right next to the player. Each further level of
+// detail starts at least a chunk further away than the one before.
+const minLODDistance = 4
+
+// ChunkLOD returns the level of detail a chunk should be meshed at, given its
+// squared distance from the player's chunk and the render radius.
+func chunkLOD(distSq, radius int) int {
+	lod := 0
+	for i, fraction := range lodFractions {
+		dist := float32(radius) * fraction
+		if least := float32(minLODDistance + i); dist < least {
+			dist = least
+		}
+		if float32(distSq) > dist*dist {
+			lod++
+		}
+	}
+	return lod
+}
+
+// GenLODVertices generates reduced detail vertex data for a chunk, for chunks
+// far enough away from the player that the detail wouldn't be noticed.
+//
+// The chunk is divided into cubic cells, 2^lod blocks along each side, and
+// each cell is drawn as a single box. A cell takes on the appearance of its
+// topmost visible block, and its box only reaches up to the top of that
+// block, so the ground stays at the same height as the full detail chunks next
+// to it. Every cell is drawn with flat lighting, whatever the shapes of the
+// blocks inside it.
+func genLODVertices(info vertexGenInfo) (*mesh, int32) {
+	size := 1 << uint(info.lod)
+	height := info.blocks.height()
+
+	// Pick the block representing each cell
+	cellsX, cellsZ := ChunkWidth/size, ChunkDepth/size
+	cellsY := (height + size - 1) / size
+	cells := make([]lodCell, cellsX*cellsY*cellsZ)
+	cellAt := func(cx, cy, cz int) *lodCell {
+		if cx < 0 || cx >= cellsX || cy < 0 || cy >= cellsY ||
+			cz < 0 || cz >= cellsZ {
+			return nil
+		}
+		return &cells[(cy*cellsZ+cz)*cellsX+cx]
+	}
+	for cx := 0; cx < cellsX; cx++ {
+		for cy := 0; cy < cellsY; cy++ {
+			for cz := 0; cz < cellsZ; cz++ {
+				*cellAt(cx, cy, cz) = findLODCell(info, cx*size, cy*size,
+					cz*size, size)
+			}
+		}
+	}
+
+	// Generate vertex data for each visible face of each cell, into meshes
+	// taken from the pool (as in `genVertices`)
+	opaque, translucent := newMesh(), newMesh()
+	for cx := 0; cx < cellsX; cx++ {
+		for cy := 0; cy < cellsY; cy++ {
+			for cz := 0; cz < cellsZ; cz++ {
+				current := *cellAt(cx, cy, cz)
+				currentInfo := info.blocksInfo.get(current.block)
+				if !currentInfo.Visible {
+					continue
+				}
+				m := opaque
+				if currentInfo.Translucent {
+					m = translucent
+				}
+
+				x, y, z := cx*size, cy*size, cz*size
+				box := shapeBox{max: [3]float32{float32(size),
+					float32(current.height), float32(size)}}
+				for face := faceLeft; face <= faceBack; face++ {
+					nx, ny, nz := face.normal()
+					neighbour := cellAt(cx+nx, cy+ny, cz+nz)
+					if neighbour == nil || !isLODSideHidden(info, current,
+						*neighbour, face, size) {
+						genLODFace(m, info, current.block, x, y, z, face, box)
+					}
+				}
+			}
+		}
+	}
+
+	numOpaque := int32(len(opaque.indices))
+	opaque.append(translucent)
+	freeMesh(translucent)
+	return opaque, numOpaque
+}
+
+// LODCell is a cell of a reduced detail chunk, drawn as a single box.
+type lodCell struct {
+	block  Block // The block the cell looks like, or air if it's empty
+	height int   // The height of the cell's box, in blocks
+}
+
+// FindLODCell returns the cell of the given size whose minimum corner is at
+// (x, y, z) within the chunk. The cell looks like the first visible block
+// found searching down from the top of the cell, and reaches up to the top of
+// that block. Returns an empty cell if there are no visible blocks in it.
+func findLODCell(info vertexGenInfo, x, y, z, size int) lodCell {
+	for dy := size - 1; dy >= 0; dy-- {
+		for dx := 0; dx < size; dx++ {
+			for dz := 0; dz < size; dz++ {
+				b := info.blocks.At(x+dx, y+dy, z+dz)
+				if b != nil && info.blocksInfo.get(*b).Visible {
+					return lodCell{*b, dy + 1}
+				}
+			}
+		}
+	}
+	return lodCell{Air, 0}
+}
+
+// IsLODSideHidden returns true if the given face of a cell is hidden by the
+// cell next to it. The neighbour only hides the face if its box covers the
+// whole face, and like full blocks, transparent cells only hide cells of the
+// same type.
+func isLODSideHidden(info vertexGenInfo, current, neighbour lodCell,
+	face blockFace, size int) bool {
+	neighbourInfo := info.blocksInfo.get(neighbour.block)
+	if !neighbourInfo.Visible {
+		return false
+	}
+	switch face {
+	case faceTop:
+		// The cell above only touches this one if this one is full height
+		if current.height < size {
+			return false
+		}
+	case faceBottom:
+		if neighbour.height < size {
+			return false
+		}
+	default:
+		if neighbour.height < current.height {
+			return false
+		}
+	}
+	if neighbourInfo.Transparent {
+		return neighbour.block.Type() == current.block.Type()
+	}
+	return true
+}
+
+// GenLODFace adds the vertex data for a face of a cell to the mesh. The cell's
+// box is measured in blocks from the cell's minimum corner at (x, y, z) within
+// the chunk. The block's texture is stretched across the whole face, and the
+// face is lit by the block just in front of its minimum corner.
+func genLODFace(m *mesh, info vertexGenInfo, block Block,
+	x, y, z int, face blockFace, box shapeBox) {
+	nx, ny, nz := face.normal()
+	front := [3]int{x, y, z}
+	normal := [3]int{nx, ny, nz}
+	for axis := 0; axis < 3; axis++ {
+		if normal[axis] > 0 {
+			front[axis] += int(box.max[axis])
+		} else if normal[axis] < 0 {
+			front[axis]--
+		}
+	}
+	light := float32(lightAt(info, front[0], front[1], front[2])) / maxLight
+
+	// Blocks lying on their side use the textures of a different face
+	textureFace, rotate := orientFace(face, block.Axis())
+	uv := info.blocksInfo.get(block).UV[textureFace]
+	w, h := uv.Size()
+
+	// Iterate over the 4 corners of the face
+	for vertex := 0; vertex < 4; vertex++ {
+		corner := &cubeVertices[faceCorners[face][vertex]]
+		var position [3]float32
+		for axis := 0; axis < 3; axis++ {
+			position[axis] = corner[axis] * box.max[axis]
+		}
+		m.vertices = append(m.vertices,
+			float32(info.p*ChunkWidth+x)+position[0],
+			float32(y)+position[1],
+			float32(info.q*ChunkDepth+z)+position[2],
+			float32(nx), float32(ny), float32(nz))
+
+		// UV, stretching the texture over the face
+		u, v := faceUV(face, *corner)
+		if rotate {
+			u, v = v, 1.0-u
+		}
+		m.vertices = append(m.vertices, uv.X+w*u, uv.Y+h*v, light,
+			float32(uv.Frames))
+	}
+	m.addQuad()
+}
+
+// MinInt returns the smaller of two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
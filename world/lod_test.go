@@ -0,0 +1,107 @@
+package world
+
+import "testing"
+
+func TestChunkLODScalesWithRadius(t *testing.T) {
+	tests := []struct {
+		dist, radius, want int
+	}{
+		// At the default render radius, the outer chunks are simplified
+		{4, 8, 0},
+		{5, 8, 1},
+		{6, 8, 1},
+		{7, 8, 2},
+		{8, 8, 2},
+
+		// At larger radii, the boundaries move further out
+		{8, 16, 0},
+		{9, 16, 1},
+		{13, 16, 2},
+
+		// Small radii don't simplify the chunks next to the player
+		{2, 2, 0},
+		{3, 3, 0},
+	}
+	for _, test := range tests {
+		if got := chunkLOD(test.dist*test.dist, test.radius); got != test.want {
+			t.Errorf("got level of detail %d for a chunk %d away with radius "+
+				"%d, want %d", got, test.dist, test.radius, test.want)
+		}
+	}
+}
+
+// MaxTopY returns the highest y coordinate of any upward facing vertex in a
+// mesh, which is the height of the surface of a chunk.
+func maxTopY(m *mesh) float32 {
+	top := float32(0.0)
+	for i := 0; i < len(m.vertices); i += valuesPerVertex {
+		y, ny := m.vertices[i+1], m.vertices[i+4]
+		if ny > 0.0 && y > top {
+			top = y
+		}
+	}
+	return top
+}
+
+func TestLODReducesVertices(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+
+	// Add some bumps to the floor, so there's more to simplify
+	stone := testBlock(t, w, "Stone")
+	for x := 0; x < ChunkWidth; x += 3 {
+		for z := 0; z < ChunkDepth; z += 2 {
+			w.SetBlock(x, 3, z, stone)
+		}
+	}
+
+	full := genTestMesh(w, 0, 0, 0)
+	defer freeMesh(full)
+	last := len(full.vertices)
+	for lod := 1; lod <= len(lodFractions); lod++ {
+		m := genTestMesh(w, 0, 0, lod)
+		if len(m.vertices)*2 > last {
+			t.Errorf("level of detail %d has %d vertices, more than half of "+
+				"the %d at the level before", lod, len(m.vertices)/
+				valuesPerVertex, last/valuesPerVertex)
+		}
+		last = len(m.vertices)
+		freeMesh(m)
+	}
+}
+
+func TestLODKeepsSurfaceHeight(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+
+	// Raise half of the floor by a block, so the surface is at y = 3 on one
+	// side of the chunk, and y = 4 on the other
+	stone := testBlock(t, w, "Stone")
+	for x := 0; x < ChunkWidth/2; x++ {
+		for z := 0; z < ChunkDepth; z++ {
+			w.SetBlock(x, 3, z, stone)
+		}
+	}
+
+	full := genTestMesh(w, 0, 0, 0)
+	defer freeMesh(full)
+	want := maxTopY(full)
+	for lod := 1; lod <= len(lodFractions); lod++ {
+		m := genTestMesh(w, 0, 0, lod)
+		if got := maxTopY(m); got != want {
+			t.Errorf("surface at y = %v at level of detail %d, want %v", got,
+				lod, want)
+		}
+
+		// The lower half of the floor isn't raised either
+		for i := 0; i < len(m.vertices); i += valuesPerVertex {
+			x, y, ny := m.vertices[i], m.vertices[i+1], m.vertices[i+4]
+			if ny > 0.0 && x > ChunkWidth/2 && y != testFloorY {
+				t.Errorf("lower floor at y = %v at level of detail %d, want "+
+					"%v", y, lod, testFloorY)
+				break
+			}
+		}
+		freeMesh(m)
+	}
+}
@@ -0,0 +1,98 @@
+package world
+
+// OpaqueFaces returns which faces of a chunk (indexed by `blockFace`) are
+// made up entirely of opaque blocks. Nothing inside the chunk can be seen
+// through an opaque face.
+func opaqueFaces(blocks blockData, blocksInfo *BlocksInfo) [6]bool {
+	isOpaque := func(x, y, z int) bool {
+		return !blocksInfo.get(*blocks.At(x, y, z)).Transparent
+	}
+	height := blocks.height()
+	var faces [6]bool
+	faces[faceLeft] = allOpaque(ChunkDepth, height, func(z, y int) bool {
+		return isOpaque(0, y, z)
+	})
+	faces[faceRight] = allOpaque(ChunkDepth, height, func(z, y int) bool {
+		return isOpaque(ChunkWidth-1, y, z)
+	})
+	faces[faceTop] = allOpaque(ChunkWidth, ChunkDepth, func(x, z int) bool {
+		return isOpaque(x, height-1, z)
+	})
+	faces[faceBottom] = allOpaque(ChunkWidth, ChunkDepth, func(x, z int) bool {
+		return isOpaque(x, 0, z)
+	})
+	faces[faceFront] = allOpaque(ChunkWidth, height, func(x, y int) bool {
+		return isOpaque(x, y, ChunkDepth-1)
+	})
+	faces[faceBack] = allOpaque(ChunkWidth, height, func(x, y int) bool {
+		return isOpaque(x, y, 0)
+	})
+	return faces
+}
+
+// AllOpaque returns true if `isOpaque` is true for every point in a `w` by
+// `h` grid, stopping as soon as it finds one that isn't.
+func allOpaque(w, h int, isOpaque func(a, b int) bool) bool {
+	for a := 0; a < w; a++ {
+		for b := 0; b < h; b++ {
+			if !isOpaque(a, b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsOnChunkFace returns true if the given chunk-space block coordinates lie
+// on one of the faces of a chunk that's `height` blocks tall.
+func isOnChunkFace(x, y, z, height int) bool {
+	return x == 0 || x == ChunkWidth-1 || z == 0 || z == ChunkDepth-1 ||
+		y == 0 || y == height-1
+}
+
+// UpdateEnclosed recalculates whether the chunk at (p, q) is enclosed: whether
+// it's hidden behind opaque blocks on every side, so it can't be seen from
+// outside. This is the case when every face of the chunk is opaque, and every
+// neighbouring chunk is loaded with an opaque face against the chunk (so the
+// faces of the chunk's outermost blocks are hidden too).
+//
+// This is a cheap, conservative check: a chunk that isn't enclosed might still
+// be hidden behind terrain further away. Since chunks span the whole height of
+// the world, a chunk is only ever enclosed if it's solid right up to the top
+// of the world.
+func (w *World) updateEnclosed(p, q int) {
+	chunk := w.FindChunk(p, q)
+	if chunk == nil {
+		return
+	}
+	chunk.enclosed = false
+	if chunk.Blocks == nil {
+		return
+	}
+	for face := faceLeft; face <= faceBack; face++ {
+		if !chunk.opaqueFaces[face] {
+			return
+		}
+		nx, ny, nz := face.normal()
+		if ny != 0 {
+			continue // There are no chunks above or below
+		}
+		neighbour := w.FindChunk(p+nx, q+nz)
+		if neighbour == nil || neighbour.Blocks == nil ||
+			!neighbour.opaqueFaces[face.opposite()] {
+			return
+		}
+	}
+	chunk.enclosed = true
+}
+
+// UpdateEnclosedAround recalculates whether the chunk at (p, q) and each of
+// its neighbours are enclosed (see `updateEnclosed`). It's called whenever
+// the chunk is loaded, unloaded, or has a block on one of its faces changed.
+func (w *World) updateEnclosedAround(p, q int) {
+	w.updateEnclosed(p, q)
+	w.updateEnclosed(p-1, q)
+	w.updateEnclosed(p+1, q)
+	w.updateEnclosed(p, q-1)
+	w.updateEnclosed(p, q+1)
+}
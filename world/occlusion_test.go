@@ -0,0 +1,69 @@
+package world
+
+import "testing"
+
+// LoadSolidTestChunk loads the chunk (p, q) into a headless world and fills it
+// with stone right up to the top of the world.
+func loadSolidTestChunk(t *testing.T, w *World, p, q int) {
+	t.Helper()
+	chunk := loadTestChunk(w, p, q)
+	stone := testBlock(t, w, "Stone")
+	for i := range chunk.Blocks {
+		chunk.Blocks[i] = stone
+	}
+	chunk.opaqueFaces = opaqueFaces(chunk.Blocks, &w.blocksInfo)
+	w.updateEnclosedAround(p, q)
+}
+
+func TestOpaqueFaces(t *testing.T) {
+	w := newTestWorld()
+	chunk := loadTestChunk(w, 0, 0)
+
+	// Generated chunks are only stone at the bottom of the world
+	faces := opaqueFaces(chunk.Blocks, &w.blocksInfo)
+	for face, opaque := range faces {
+		if opaque != (blockFace(face) == faceBottom) {
+			t.Errorf("face %d opaque is %v", face, opaque)
+		}
+	}
+}
+
+func TestSolidChunkIsEnclosedByNeighbours(t *testing.T) {
+	w := newTestWorld()
+	loadSolidTestChunk(t, w, 0, 0)
+	if w.FindChunk(0, 0).enclosed {
+		t.Fatal("chunk enclosed without any neighbours loaded")
+	}
+	loadSolidTestChunk(t, w, -1, 0)
+	loadSolidTestChunk(t, w, 1, 0)
+	loadSolidTestChunk(t, w, 0, -1)
+	loadSolidTestChunk(t, w, 0, 1)
+	if !w.FindChunk(0, 0).enclosed {
+		t.Fatal("solid chunk surrounded by solid chunks isn't enclosed")
+	}
+	if w.FindChunk(1, 0).enclosed {
+		t.Error("chunk with neighbours missing is enclosed")
+	}
+
+	// Opening up the face of a neighbour against the chunk exposes it
+	if _, ok := w.BreakBlock(16, 10, 5); !ok {
+		t.Fatal("failed to break block")
+	}
+	if w.FindChunk(0, 0).enclosed {
+		t.Error("chunk enclosed with a hole in its neighbour's face")
+	}
+}
+
+func TestBreakingTopOfSolidChunkExposesIt(t *testing.T) {
+	w := newTestWorld()
+	for _, pos := range []chunkPos{{0, 0}, {-1, 0}, {1, 0}, {0, -1},
+		{0, 1}} {
+		loadSolidTestChunk(t, w, pos.p, pos.q)
+	}
+	if _, ok := w.BreakBlock(5, testHeight-1, 5); !ok {
+		t.Fatal("failed to break block")
+	}
+	if w.FindChunk(0, 0).enclosed {
+		t.Error("chunk enclosed with a hole in its top face")
+	}
+}
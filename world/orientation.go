@@ -0,0 +1,73 @@
+package world
+
+import (
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Axis is the axis along which an orientable block (like a log) lies. It's
+// stored in the lowest bits of the block's state.
+type Axis uint16
+
+// All axes an orientable block can lie along. Blocks stand upright along the
+// y axis by default, when they have no state.
+const (
+	AxisY Axis = iota
+	AxisX
+	AxisZ
+)
+
+// The bits of a block's state used to store its axis.
+const axisMask = 0x3
+
+// Axis returns the axis along which the block lies.
+func (b Block) Axis() Axis {
+	return Axis(b.State() & axisMask)
+}
+
+// WithAxis returns the same block lying along a different axis, leaving the
+// rest of its state unchanged.
+func (b Block) WithAxis(axis Axis) Block {
+	return b.WithState(b.State()&^axisMask | uint16(axis)&axisMask)
+}
+
+// AxisFromDirection returns the axis most closely aligned with a direction.
+// Used to orient blocks placed by an entity along the direction it's facing.
+func AxisFromDirection(dir mgl32.Vec3) Axis {
+	ax, ay, az := math32.Abs(dir.X()), math32.Abs(dir.Y()), math32.Abs(dir.Z())
+	if ay >= ax && ay >= az {
+		return AxisY
+	} else if ax >= az {
+		return AxisX
+	}
+	return AxisZ
+}
+
+// FaceAxes is indexed by block face, and gives the axis each face's normal
+// points along.
+var faceAxes = [...]Axis{AxisX, AxisX, AxisY, AxisY, AxisZ, AxisZ}
+
+// FaceVAxes is indexed by block face, and gives the axis along which the v
+// texture coordinate runs across each face (see `faceUV`).
+var faceVAxes = [...]Axis{AxisY, AxisY, AxisX, AxisZ, AxisY, AxisY}
+
+// OrientFace works out how to texture a face of a block lying along the given
+// axis. Returns the face of an upright block whose texture should be used, and
+// whether the texture should be rotated by 90 degrees.
+//
+// The faces at either end of the block's axis use the block's top and bottom
+// textures, and the other faces use its side texture, rotated so that it runs
+// along the block's axis.
+func orientFace(face blockFace, axis Axis) (blockFace, bool) {
+	if axis == AxisY {
+		return face, false
+	}
+	if faceAxes[face] == axis {
+		// The ends of the block
+		if face == faceLeft || face == faceBack {
+			return faceBottom, false
+		}
+		return faceTop, false
+	}
+	return faceFront, faceVAxes[face] != axis
+}
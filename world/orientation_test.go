@@ -0,0 +1,84 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestBlockStatePacking(t *testing.T) {
+	block := Block(7).WithState(0xbeef)
+	if got := block.Type(); got != 7 {
+		t.Errorf("got type %v, want 7", got)
+	}
+	if got := block.State(); got != 0xbeef {
+		t.Errorf("got state %#x, want 0xbeef", got)
+	}
+
+	// Replacing the state leaves the type alone
+	block = block.WithState(1)
+	if block.Type() != 7 || block.State() != 1 {
+		t.Errorf("got type %v and state %v, want 7 and 1", block.Type(),
+			block.State())
+	}
+}
+
+func TestBlockAxis(t *testing.T) {
+	log := Block(7)
+	if got := log.Axis(); got != AxisY {
+		t.Errorf("block with no state lies along axis %v, want y", got)
+	}
+	for _, axis := range []Axis{AxisX, AxisY, AxisZ} {
+		oriented := log.WithAxis(axis)
+		if oriented.Axis() != axis {
+			t.Errorf("got axis %v, want %v", oriented.Axis(), axis)
+		}
+		if oriented.Type() != log {
+			t.Errorf("orienting block changed its type to %v", oriented.Type())
+		}
+	}
+
+	// The rest of the block's state is kept
+	block := log.WithState(0xff00).WithAxis(AxisZ)
+	if block.Axis() != AxisZ || block.State()&^axisMask != 0xff00 {
+		t.Errorf("got state %#x, want axis z and the rest unchanged",
+			block.State())
+	}
+}
+
+func TestAxisFromDirection(t *testing.T) {
+	tests := []struct {
+		dir  mgl32.Vec3
+		want Axis
+	}{
+		{mgl32.Vec3{0.0, -1.0, 0.0}, AxisY},
+		{mgl32.Vec3{0.9, 0.1, -0.3}, AxisX},
+		{mgl32.Vec3{-0.2, 0.3, -0.9}, AxisZ},
+	}
+	for _, test := range tests {
+		if got := AxisFromDirection(test.dir); got != test.want {
+			t.Errorf("got axis %v for direction %v, want %v", got, test.dir,
+				test.want)
+		}
+	}
+}
+
+func TestOrientFace(t *testing.T) {
+	// Upright blocks are textured as usual
+	for face := faceLeft; face <= faceBack; face++ {
+		if got, rotated := orientFace(face, AxisY); got != face || rotated {
+			t.Errorf("upright face %v textured as %v", face, got)
+		}
+	}
+
+	// The ends of a block lying along the x axis are its left and right faces
+	if got, _ := orientFace(faceRight, AxisX); got != faceTop {
+		t.Errorf("got face %v at the end of the block, want top", got)
+	}
+	if got, _ := orientFace(faceLeft, AxisX); got != faceBottom {
+		t.Errorf("got face %v at the end of the block, want bottom", got)
+	}
+	if got, _ := orientFace(faceTop, AxisX); got != faceFront {
+		t.Errorf("got face %v along the side of the block, want front", got)
+	}
+}
@@ -0,0 +1,60 @@
+package world
+
+import (
+	"sync"
+)
+
+// MeshPool holds meshes whose vertex data has already been uploaded to the
+// GPU, so that their backing arrays can be reused to generate the vertex data
+// for other chunks. This saves allocating (and the garbage collector freeing)
+// several hundred kilobytes for every chunk loaded.
+//
+// Pools are safe to use from multiple goroutines, so meshes can be taken from
+// the pool on the worker goroutines and returned on the main goroutine.
+var meshPool sync.Pool
+
+// BlockDataPool holds the block data of unloaded chunks, and the copies of
+// block data made for regenerating a chunk's vertex data, for reuse by other
+// chunks.
+var blockDataPool sync.Pool
+
+// NewMesh returns an empty mesh, reusing the backing arrays of a previously
+// freed mesh if there is one.
+func newMesh() *mesh {
+	if m, ok := meshPool.Get().(*mesh); ok {
+		return m
+	}
+	return &mesh{}
+}
+
+// FreeMesh empties a mesh and returns it to the pool. The mesh must not be
+// used again afterwards.
+//
+// Uploading vertex data with `glBufferData` or `glBufferSubData` copies it
+// before returning, so a mesh can be freed as soon as it's been uploaded.
+func freeMesh(m *mesh) {
+	m.vertices = m.vertices[:0]
+	m.indices = m.indices[:0]
+	meshPool.Put(m)
+}
+
+// NewBlockData creates a new blocks array for a chunk of the given height,
+// with length equal to the number of blocks in the chunk. Every block in the
+// array is air.
+func newBlockData(height int) blockData {
+	size := ChunkWidth * height * ChunkDepth
+	if b, ok := blockDataPool.Get().(*blockData); ok && len(*b) == size {
+		blocks := *b
+		for i := range blocks {
+			blocks[i] = Air
+		}
+		return blocks
+	}
+	return make([]Block, size)
+}
+
+// FreeBlockData returns a blocks array to the pool. Nothing may hold on to
+// the array afterwards.
+func freeBlockData(b blockData) {
+	blockDataPool.Put(&b)
+}
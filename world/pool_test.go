@@ -0,0 +1,56 @@
+package world
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+// DrainPools empties the mesh and block data pools.
+func drainPools() {
+	for meshPool.Get() != nil {
+	}
+	for blockDataPool.Get() != nil {
+	}
+}
+
+func TestMeshPoolReducesAllocations(t *testing.T) {
+	// Stop the garbage collector from emptying the pool part way through
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	chunk := w.FindChunk(0, 0)
+	light := genLight(chunk.Blocks, borderLight{}, &w.blocksInfo)
+	gen := func() *mesh {
+		m, _ := genVertices(vertexGenInfo{0, 0, chunk.Blocks, light,
+			borderLight{}, false, 0, &w.blocksInfo})
+		return m
+	}
+
+	// Without freeing them, every mesh grows its vertex and index arrays
+	// from scratch
+	drainPools()
+	fresh := testing.AllocsPerRun(10, func() { gen() })
+	pooled := testing.AllocsPerRun(10, func() { freeMesh(gen()) })
+	if pooled >= fresh {
+		t.Errorf("got %v allocations per chunk with pooled meshes, and %v "+
+			"without", pooled, fresh)
+	}
+}
+
+func TestBlockDataPoolReducesAllocations(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	const runs = 10
+	drainPools()
+	fresh := testing.AllocsPerRun(runs, func() { genBlocks(0, 0, testHeight) })
+
+	// Fill the pool with enough block data for every run, including the
+	// warm up run
+	for i := 0; i < runs+1; i++ {
+		freeBlockData(make(blockData, ChunkWidth*testHeight*ChunkDepth))
+	}
+	pooled := testing.AllocsPerRun(runs, func() { genBlocks(0, 0, testHeight) })
+	if fresh == 0 || pooled != 0 {
+		t.Errorf("got %v allocations per chunk with pooled block data, and "+
+			"%v without", pooled, fresh)
+	}
+}
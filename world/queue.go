@@ -0,0 +1,99 @@
+package world
+
+import (
+	"container/heap"
+)
+
+// ChunkJob is a request to generate data for a chunk on one of the world's
+// worker goroutines.
+type chunkJob struct {
+	pos  chunkPos // The chunk to generate data for
+	dist int      // Squared distance from the player's chunk
+
+	// True if only the chunk's vertex data is to be regenerated from its
+	// existing block data, rather than generating the chunk from scratch
+	regen bool
+
+	// A copy of the chunk's block data for regen jobs, and the light levels
+	// along the borders of the neighbouring chunks, taken when the job is
+	// handed to a worker
+	blocks blockData
+	border borderLight
+
+	// True if the chunk's vertex data is to be generated with smooth lighting,
+	// and the level of detail to generate it at
+	smooth bool
+	lod    int
+}
+
+// JobQueue is a priority queue of chunk jobs, ordered by squared distance from
+// the player's chunk so that the nearest chunks are generated first.
+//
+// Implements the `heap.Interface` interface; use the `push` and `pop` methods
+// rather than manipulating the slice directly.
+type jobQueue []chunkJob
+
+// Len implements the `heap.Interface` interface.
+func (jq jobQueue) Len() int { return len(jq) }
+
+// Less implements the `heap.Interface` interface.
+func (jq jobQueue) Less(i, j int) bool { return jq[i].dist < jq[j].dist }
+
+// Swap implements the `heap.Interface` interface.
+func (jq jobQueue) Swap(i, j int) { jq[i], jq[j] = jq[j], jq[i] }
+
+// Push implements the `heap.Interface` interface.
+func (jq *jobQueue) Push(x interface{}) {
+	*jq = append(*jq, x.(chunkJob))
+}
+
+// Pop implements the `heap.Interface` interface.
+func (jq *jobQueue) Pop() interface{} {
+	old := *jq
+	job := old[len(old)-1]
+	*jq = old[:len(old)-1]
+	return job
+}
+
+// Push adds a job for the chunk at the given coordinates to the queue, keyed
+// on its squared distance from the central chunk (p, q).
+func (jq *jobQueue) push(job chunkJob, p, q int) {
+	job.dist = chunkDistSq(job.pos, p, q)
+	heap.Push(jq, job)
+}
+
+// Pop removes and returns the job closest to the central chunk.
+func (jq *jobQueue) pop() chunkJob {
+	return heap.Pop(jq).(chunkJob)
+}
+
+// Rekey recalculates the distance of every job in the queue from a new central
+// chunk (p, q), and restores the heap ordering. This should be called whenever
+// the player moves into a different chunk.
+func (jq *jobQueue) rekey(p, q int) {
+	for i := range *jq {
+		(*jq)[i].dist = chunkDistSq((*jq)[i].pos, p, q)
+	}
+	heap.Init(jq)
+}
+
+// Prune removes every job for which `drop` returns true from the queue, and
+// restores the heap ordering.
+func (jq *jobQueue) prune(drop func(job chunkJob) bool) {
+	kept := (*jq)[:0]
+	for _, job := range *jq {
+		if !drop(job) {
+			kept = append(kept, job)
+		}
+	}
+	*jq = kept
+	heap.Init(jq)
+}
+
+// ChunkDistSq returns the squared distance between a chunk and the central
+// chunk (p, q), in chunks.
+func chunkDistSq(pos chunkPos, p, q int) int {
+	dp := pos.p - p
+	dq := pos.q - q
+	return dp*dp + dq*dq
+}
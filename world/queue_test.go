@@ -0,0 +1,60 @@
+package world
+
+import "testing"
+
+func TestJobQueuePopsNearestFirst(t *testing.T) {
+	var queue jobQueue
+	positions := []chunkPos{{5, 0}, {0, 0}, {-3, 4}, {1, 1}, {0, -2}, {7, 7},
+		{-1, 0}}
+	for _, pos := range positions {
+		queue.push(chunkJob{pos: pos}, 0, 0)
+	}
+
+	last := -1
+	for i := range positions {
+		job := queue.pop()
+		if job.dist < last {
+			t.Fatalf("job %d at %v has distance %d, after distance %d", i,
+				job.pos, job.dist, last)
+		}
+		last = job.dist
+	}
+	if queue.Len() != 0 {
+		t.Errorf("%d jobs left in queue", queue.Len())
+	}
+}
+
+func TestJobQueueRekey(t *testing.T) {
+	var queue jobQueue
+	queue.push(chunkJob{pos: chunkPos{0, 0}}, 0, 0)
+	queue.push(chunkJob{pos: chunkPos{10, 0}}, 0, 0)
+
+	// Moving next to the far chunk makes it the nearest
+	queue.rekey(9, 0)
+	if job := queue.pop(); job.pos != (chunkPos{10, 0}) || job.dist != 1 {
+		t.Errorf("got job at %v with distance %d, want (10, 0) with 1",
+			job.pos, job.dist)
+	}
+}
+
+func TestJobQueuePrune(t *testing.T) {
+	var queue jobQueue
+	for p := 0; p < 5; p++ {
+		queue.push(chunkJob{pos: chunkPos{p, 0}}, 0, 0)
+	}
+	queue.prune(func(job chunkJob) bool { return job.pos.p%2 == 1 })
+
+	var got []chunkPos
+	for queue.Len() > 0 {
+		got = append(got, queue.pop().pos)
+	}
+	want := []chunkPos{{0, 0}, {2, 0}, {4, 0}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package world
+
+import (
+	"github.com/chewxy/math32"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Raycast steps through the blocks along a ray, starting at `origin` and
+// travelling in the direction `dir` for at most `maxDist` units, and returns
+// the world-space coordinates of the first solid, visible block that the ray
+// hits, along with the face of the block that the ray entered through. The
+// hit face's normal points back towards the ray's origin, so a block placed
+// against the hit block should be offset along it.
+//
+// If the ray starts inside a solid block, then that block is returned, and
+// the face is the one facing back along the ray's direction.
+//
+// Returns false if the ray doesn't hit anything within `maxDist`, or if it
+// leaves the loaded chunks or the vertical bounds of the world before hitting
+// anything.
+//
+// The traversal is a voxel DDA (see "A Fast Voxel Traversal Algorithm for Ray
+// Tracing" by Amanatides and Woo), which visits every block the ray passes
+// through in order, without skipping any.
+func (w *World) Raycast(origin, dir mgl32.Vec3, maxDist float32) (
+	hitBlock [3]int, hitFace blockFace, ok bool) {
+	// Can't cast a ray without a direction
+	if dir.Len() == 0.0 {
+		return hitBlock, 0, false
+	}
+	dir = dir.Normalize()
+
+	// The block containing the start of the ray
+	x, y, z := ToWorldSpace(origin.X(), origin.Y(), origin.Z())
+
+	// For each axis, the direction we step in, the distance along the ray to
+	// the first block boundary, and the distance along the ray between
+	// successive block boundaries
+	stepX, tMaxX, tDeltaX := raycastAxis(origin.X(), dir.X())
+	stepY, tMaxY, tDeltaY := raycastAxis(origin.Y(), dir.Y())
+	stepZ, tMaxZ, tDeltaZ := raycastAxis(origin.Z(), dir.Z())
+
+	// If the ray starts inside a block, then the face we "entered" through is
+	// the one facing back along the dominant axis of the ray
+	hitFace = entryFace(dir)
+
+	for t := float32(0.0); t <= maxDist; {
+		// Check the block we're in
+		block, loaded := w.GetBlock(x, y, z)
+		if !loaded {
+			// Either left the loaded chunks, or the top or bottom of the world
+			return hitBlock, 0, false
+		}
+		info := w.GetBlockInfo(block)
+		if info.Visible && info.Collidable {
+			return [3]int{x, y, z}, hitFace, true
+		}
+
+		// Step into the next block along whichever axis has the nearest
+		// block boundary
+		if tMaxX < tMaxY && tMaxX < tMaxZ {
+			x += stepX
+			t = tMaxX
+			tMaxX += tDeltaX
+			hitFace = faceRight
+			if stepX > 0 {
+				hitFace = faceLeft
+			}
+		} else if tMaxY < tMaxZ {
+			y += stepY
+			t = tMaxY
+			tMaxY += tDeltaY
+			hitFace = faceTop
+			if stepY > 0 {
+				hitFace = faceBottom
+			}
+		} else {
+			z += stepZ
+			t = tMaxZ
+			tMaxZ += tDeltaZ
+			hitFace = faceFront
+			if stepZ > 0 {
+				hitFace = faceBack
+			}
+		}
+	}
+
+	return hitBlock, 0, false
+}
+
+// RaycastAxis calculates the DDA traversal parameters along a single axis: the
+// direction to step in (-1, 0, or 1), the distance along the ray to the first
+// block boundary, and the distance along the ray between block boundaries.
+func raycastAxis(origin, dir float32) (step int, tMax, tDelta float32) {
+	if dir > 0.0 {
+		return 1, (math32.Floor(origin) + 1.0 - origin) / dir, 1.0 / dir
+	} else if dir < 0.0 {
+		return -1, (origin - math32.Floor(origin)) / -dir, 1.0 / -dir
+	}
+
+	// Never step along an axis that the ray is parallel to
+	return 0, math32.Inf(1), math32.Inf(1)
+}
+
+// EntryFace returns the face of a block that a ray travelling in the given
+// direction would enter through, based on the ray's dominant axis.
+func entryFace(dir mgl32.Vec3) blockFace {
+	ax, ay, az := math32.Abs(dir.X()), math32.Abs(dir.Y()), math32.Abs(dir.Z())
+	if ax >= ay && ax >= az {
+		if dir.X() > 0.0 {
+			return faceLeft
+		}
+		return faceRight
+	} else if ay >= az {
+		if dir.Y() > 0.0 {
+			return faceBottom
+		}
+		return faceTop
+	}
+	if dir.Z() > 0.0 {
+		return faceBack
+	}
+	return faceFront
+}
+
+// AdjacentBlock returns the world-space coordinates of the block touching the
+// given face of a block. Used to find where to place a block against a face
+// hit by a raycast.
+func AdjacentBlock(block [3]int, face blockFace) [3]int {
+	nx, ny, nz := face.normal()
+	return [3]int{block[0] + nx, block[1] + ny, block[2] + nz}
+}
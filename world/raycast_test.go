@@ -0,0 +1,111 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestRaycastAlongX(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	w.SetBlock(5, 10, 2, testBlock(t, w, "Stone"))
+
+	origin := mgl32.Vec3{0.5, 10.5, 2.5}
+	hit, face, ok := w.Raycast(origin, mgl32.Vec3{1.0, 0.0, 0.0}, 10.0)
+	if !ok {
+		t.Fatal("ray didn't hit anything")
+	}
+	if hit != [3]int{5, 10, 2} {
+		t.Errorf("ray hit block %v, want (5, 10, 2)", hit)
+	}
+	if face != faceLeft {
+		t.Errorf("ray hit face %v, want the left face", face)
+	}
+
+	// The same ray in the opposite direction misses, and a ray that's too
+	// short doesn't reach the block
+	if _, _, ok := w.Raycast(origin, mgl32.Vec3{-1.0, 0.0, 0.0}, 0.4); ok {
+		t.Error("ray in the opposite direction hit something")
+	}
+	if _, _, ok := w.Raycast(origin, mgl32.Vec3{1.0, 0.0, 0.0}, 4.0); ok {
+		t.Error("ray hit a block beyond its maximum distance")
+	}
+}
+
+func TestRaycastDown(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+
+	// The floor of generated chunks is 3 blocks of stone
+	hit, face, ok := w.Raycast(mgl32.Vec3{3.5, 8.5, 3.5},
+		mgl32.Vec3{0.0, -1.0, 0.0}, 10.0)
+	if !ok || hit != [3]int{3, 2, 3} || face != faceTop {
+		t.Errorf("got hit %v on face %v (ok %v), want top of (3, 2, 3)", hit,
+			face, ok)
+	}
+}
+
+func TestRaycastStartingInsideBlock(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	hit, face, ok := w.Raycast(mgl32.Vec3{3.5, 1.5, 3.5},
+		mgl32.Vec3{1.0, 0.0, 0.0}, 10.0)
+	if !ok || hit != [3]int{3, 1, 3} || face != faceLeft {
+		t.Errorf("got hit %v on face %v (ok %v), want left of (3, 1, 3)", hit,
+			face, ok)
+	}
+}
+
+func TestRaycastLeavingLoadedChunks(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+
+	// Nothing is hit before the ray leaves the only loaded chunk
+	if _, _, ok := w.Raycast(mgl32.Vec3{8.5, 10.5, 8.5},
+		mgl32.Vec3{1.0, 0.0, 0.0}, 100.0); ok {
+		t.Error("ray hit something outside the loaded chunks")
+	}
+	if _, _, ok := w.Raycast(mgl32.Vec3{8.5, 10.5, 8.5},
+		mgl32.Vec3{0.0, 1.0, 0.0}, 100.0); ok {
+		t.Error("ray hit something above the world")
+	}
+}
+
+func TestAdjacentBlock(t *testing.T) {
+	block := [3]int{-3, 10, 7}
+	tests := []struct {
+		face blockFace
+		want [3]int
+	}{
+		{faceLeft, [3]int{-4, 10, 7}},
+		{faceRight, [3]int{-2, 10, 7}},
+		{faceTop, [3]int{-3, 11, 7}},
+		{faceBottom, [3]int{-3, 9, 7}},
+		{faceFront, [3]int{-3, 10, 8}},
+		{faceBack, [3]int{-3, 10, 6}},
+	}
+	for _, test := range tests {
+		if got := AdjacentBlock(block, test.face); got != test.want {
+			t.Errorf("got block %v next to face %v, want %v", got, test.face,
+				test.want)
+		}
+	}
+}
+
+func TestAdjacentBlockFacesRay(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	w.SetBlock(5, 10, 2, testBlock(t, w, "Stone"))
+
+	// A block placed against the face hit by a ray sits in front of the face,
+	// on the side the ray came from
+	hit, face, ok := w.Raycast(mgl32.Vec3{7.5, 10.5, 2.5},
+		mgl32.Vec3{-1.0, 0.0, 0.0}, 10.0)
+	if !ok {
+		t.Fatal("ray didn't hit anything")
+	}
+	if got := AdjacentBlock(hit, face); got != [3]int{6, 10, 2} {
+		t.Errorf("got adjacent block %v, want (6, 10, 2)", got)
+	}
+}
@@ -0,0 +1,123 @@
+package world
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Only chunks the player has edited are saved, since every other chunk can be
+// generated again from scratch. Each saved chunk is a file in the save folder
+// holding the chunk's blocks in the same order as `blockData`, with each
+// block stored as 4 little endian bytes.
+
+// BytesPerBlock is the number of bytes each block takes up in a chunk file.
+const bytesPerBlock = 4
+
+// SetSaveDir sets the folder that edited chunks are saved to, and loaded from
+// in place of generating them. Chunks aren't saved if `dir` is empty, which is
+// the default. This must be called before any chunks are loaded, since the
+// folder is read by the worker goroutines.
+func (w *World) SetSaveDir(dir string) {
+	w.saveDir = dir
+}
+
+// Save writes every loaded chunk that's been edited since it was loaded (or
+// last saved) to the save folder, creating the folder if necessary. Edited
+// chunks are also saved when they're unloaded, so this only needs calling
+// before the world is destroyed.
+func (w *World) Save() error {
+	if w.saveDir == "" {
+		return nil
+	}
+	for pos, chunk := range w.chunks {
+		if !chunk.dirty || chunk.Blocks == nil {
+			continue
+		}
+		if err := saveChunk(w.saveDir, pos, chunk.Blocks); err != nil {
+			return err
+		}
+		chunk.dirty = false
+	}
+	return nil
+}
+
+// SaveUnloadedChunk saves a chunk that's about to be unloaded if it's been
+// edited, so the edits aren't lost. There's nothing to return an error to, so
+// it's logged instead.
+func (w *World) saveUnloadedChunk(pos chunkPos, chunk *Chunk) {
+	if w.saveDir == "" || !chunk.dirty || chunk.Blocks == nil {
+		return
+	}
+	if err := saveChunk(w.saveDir, pos, chunk.Blocks); err != nil {
+		log.Println(err)
+	}
+}
+
+// LoadBlocks returns the block data for the chunk (p, q), loading it from the
+// save folder if the chunk was saved, or generating it otherwise. Chunks that
+// fail to load are generated again, so a damaged file doesn't stop the game.
+func (w *World) loadBlocks(p, q int) blockData {
+	if w.saveDir != "" {
+		blocks, err := loadChunk(w.saveDir, chunkPos{p, q}, w.Height)
+		if err != nil {
+			log.Println(err)
+		} else if blocks != nil {
+			return blocks
+		}
+	}
+	return genBlocks(p, q, w.Height)
+}
+
+// ChunkPath returns the path of the file the chunk at `pos` is saved to.
+func chunkPath(dir string, pos chunkPos) string {
+	return filepath.Join(dir, fmt.Sprintf("chunk.%d.%d", pos.p, pos.q))
+}
+
+// SaveChunk writes a chunk's block data to its file in the save folder. The
+// data is written to a temporary file first, so an existing save isn't left
+// half written if the game is killed part way through.
+func saveChunk(dir string, pos chunkPos, blocks blockData) error {
+	data := make([]byte, len(blocks)*bytesPerBlock)
+	for i, block := range blocks {
+		binary.LittleEndian.PutUint32(data[i*bytesPerBlock:], uint32(block))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create save folder: %v", err)
+	}
+	path := chunkPath(dir, pos)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to save chunk `%v`: %v", path, err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to save chunk `%v`: %v", path, err)
+	}
+	return nil
+}
+
+// LoadChunk reads a chunk's block data from its file in the save folder.
+// Returns nil without an error if the chunk hasn't been saved.
+func loadChunk(dir string, pos chunkPos, height int) (blockData, error) {
+	path := chunkPath(dir, pos)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load chunk `%v`: %v", path, err)
+	}
+
+	// Chunks saved in a world of a different height can't be loaded
+	blocks := newBlockData(height)
+	if len(data) != len(blocks)*bytesPerBlock {
+		freeBlockData(blocks)
+		return nil, fmt.Errorf("failed to load chunk `%v`: wrong size", path)
+	}
+	for i := range blocks {
+		blocks[i] = Block(binary.LittleEndian.Uint32(data[i*bytesPerBlock:]))
+	}
+	return blocks, nil
+}
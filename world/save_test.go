@@ -0,0 +1,76 @@
+package world
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSaveOnlyEditedChunks(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWorld()
+	w.SetSaveDir(dir)
+	loadTestChunk(w, 0, 0)
+	loadTestChunk(w, 1, 0)
+	glowstone := testBlock(t, w, "Glowstone")
+	w.SetBlock(5, 10, 5, glowstone)
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the edited chunk is written to the save folder
+	if _, err := os.Stat(chunkPath(dir, chunkPos{0, 0})); err != nil {
+		t.Errorf("edited chunk wasn't saved: %v", err)
+	}
+	if _, err := os.Stat(chunkPath(dir, chunkPos{1, 0})); err == nil {
+		t.Error("saved a chunk that wasn't edited")
+	}
+	if w.FindChunk(0, 0).dirty {
+		t.Error("chunk still needs saving after it was saved")
+	}
+
+	// The saved chunk is loaded in place of generating it
+	loaded := newTestWorld()
+	loaded.SetSaveDir(dir)
+	loadTestChunk(loaded, 0, 0)
+	if block, _ := loaded.GetBlock(5, 10, 5); block != glowstone {
+		t.Errorf("got block %v in the loaded chunk, want glowstone %v", block,
+			glowstone)
+	}
+	if loaded.FindChunk(0, 0).dirty {
+		t.Error("loaded chunk needs saving before it was edited")
+	}
+}
+
+func TestUnloadingSavesEditedChunk(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWorld()
+	w.SetSaveDir(dir)
+	loadTestChunk(w, 0, 0)
+	w.SetBlock(5, 10, 5, testBlock(t, w, "Stone"))
+
+	// Moving far away unloads the chunk, which saves it
+	w.GenChunksAround(100, 100)
+	if w.FindChunk(0, 0) != nil {
+		t.Fatal("chunk wasn't unloaded")
+	}
+	if _, err := os.Stat(chunkPath(dir, chunkPos{0, 0})); err != nil {
+		t.Errorf("edited chunk wasn't saved when unloaded: %v", err)
+	}
+}
+
+func TestLoadChunkOfWrongHeight(t *testing.T) {
+	dir := t.TempDir()
+	blocks := genBlocks(0, 0, testHeight)
+	defer freeBlockData(blocks)
+	if err := saveChunk(dir, chunkPos{0, 0}, blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	// A world of a different height generates the chunk instead
+	w := NewHeadless(testHeight*2, 1)
+	w.SetSaveDir(dir)
+	chunk := loadTestChunk(w, 0, 0)
+	if got := chunk.Blocks.height(); got != testHeight*2 {
+		t.Errorf("got chunk %d blocks tall, want %d", got, testHeight*2)
+	}
+}
@@ -0,0 +1,106 @@
+package world
+
+import (
+	"github.com/benanders/mineral/math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ShapeBox is an axis aligned box within a block, with coordinates relative to
+// the block's minimum corner, between 0 and 1.
+type shapeBox struct {
+	min, max [3]float32
+}
+
+// OnBoundary returns true if the given face of the box lies on the boundary of
+// the block containing it, rather than somewhere inside the block.
+func (b shapeBox) onBoundary(face blockFace) bool {
+	switch face {
+	case faceLeft:
+		return b.min[0] == 0.0
+	case faceRight:
+		return b.max[0] == 1.0
+	case faceBottom:
+		return b.min[1] == 0.0
+	case faceTop:
+		return b.max[1] == 1.0
+	case faceBack:
+		return b.min[2] == 0.0
+	default:
+		return b.max[2] == 1.0
+	}
+}
+
+// BlockShape describes the geometry of a block as a set of boxes, which are
+// used both to generate the block's vertex data and for collision detection.
+type blockShape struct {
+	boxes []shapeBox
+
+	// Whether the shape completely covers each side of the block, indexed by
+	// block face. Faces of neighbouring blocks are only hidden by a side that's
+	// completely covered
+	fullFaces [6]bool
+}
+
+// The shapes that a block can have, specified by the `Shape` property in
+// `blocks.toml`.
+var (
+	// A full 1x1x1 cube, which is the default shape.
+	cubeShape = blockShape{
+		boxes:     []shapeBox{{[3]float32{0, 0, 0}, [3]float32{1, 1, 1}}},
+		fullFaces: [6]bool{true, true, true, true, true, true},
+	}
+
+	// The bottom half of a block.
+	slabShape = blockShape{
+		boxes:     []shapeBox{{[3]float32{0, 0, 0}, [3]float32{1, 0.5, 1}}},
+		fullFaces: [6]bool{faceBottom: true},
+	}
+
+	// A slab with a step on top of its back half. Blocks don't store an
+	// orientation yet, so stairs always climb towards the back (negative z).
+	stairsShape = blockShape{
+		boxes: []shapeBox{
+			{[3]float32{0, 0, 0}, [3]float32{1, 0.5, 1}},
+			{[3]float32{0, 0.5, 0}, [3]float32{1, 1, 0.5}},
+		},
+		fullFaces: [6]bool{faceBottom: true, faceBack: true},
+	}
+)
+
+// BlockShapes maps the names used by the `Shape` property in `blocks.toml` to
+// the shape they represent.
+var blockShapes = map[string]*blockShape{
+	"":       &cubeShape,
+	"cube":   &cubeShape,
+	"slab":   &slabShape,
+	"stairs": &stairsShape,
+}
+
+// Opposite returns the face pointing in the opposite direction to this one.
+func (f blockFace) opposite() blockFace {
+	// Faces are listed in pairs of opposite directions
+	return f ^ 1
+}
+
+// AABBs returns the world-space AABBs for each box in a shape, for a block at
+// the given chunk and block coordinates.
+func (s *blockShape) aabbs(p, q, x, y, z int) []math.AABB {
+	ox := float32(p*ChunkWidth + x)
+	oy := float32(y)
+	oz := float32(q*ChunkDepth + z)
+	aabbs := make([]math.AABB, len(s.boxes))
+	for i, box := range s.boxes {
+		size := mgl32.Vec3{box.max[0] - box.min[0], box.max[1] - box.min[1],
+			box.max[2] - box.min[2]}
+		aabbs[i] = math.AABB{
+			Center: mgl32.Vec3{
+				ox + box.min[0] + size.X()/2.0,
+				oy + box.min[1] + size.Y()/2.0,
+				oz + box.min[2] + size.Z()/2.0,
+			},
+			Size: size,
+		}
+	}
+	return aabbs
+}
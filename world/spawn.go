@@ -0,0 +1,41 @@
+package world
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// DefaultSpawnHeight is the height the player spawns at if there's nothing
+// solid to stand on in the spawn column.
+const defaultSpawnHeight = 64
+
+// FindSpawn returns a safe place for the player to spawn in the chunk (p, q):
+// standing on top of the highest solid block in the chunk's first column (at
+// x = 0, z = 0 within the chunk). Fluids don't count as solid, so the player
+// never spawns at the bottom of a lake. Everything above the highest solid
+// block is free to move through, so the player has room to stand.
+//
+// The returned position is the middle of the bottom of the player, which is
+// centred on the column horizontally. If the column has no solid blocks, the
+// player spawns in the air at `defaultSpawnHeight` instead.
+//
+// The chunk's block data is loaded or generated if the chunk isn't loaded yet,
+// so this can be called before any chunks have been loaded.
+func (w *World) FindSpawn(p, q int) mgl32.Vec3 {
+	var blocks blockData
+	if chunk := w.FindChunk(p, q); chunk != nil && chunk.Blocks != nil {
+		blocks = chunk.Blocks
+	} else {
+		blocks = w.loadBlocks(p, q)
+		defer freeBlockData(blocks)
+	}
+
+	x := float32(p*ChunkWidth) + 0.5
+	z := float32(q*ChunkDepth) + 0.5
+	for y := blocks.height() - 1; y >= 0; y-- {
+		info := w.blocksInfo.get(*blocks.At(0, y, 0))
+		if info.Collidable && !info.Fluid {
+			return mgl32.Vec3{x, float32(y + 1), z}
+		}
+	}
+	return mgl32.Vec3{x, defaultSpawnHeight, z}
+}
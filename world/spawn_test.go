@@ -0,0 +1,65 @@
+package world
+
+import "testing"
+
+// CheckSpawn fails the test unless the spawn position returned by `FindSpawn`
+// is on top of a solid block, with two blocks of air above it for the player
+// to stand in.
+func checkSpawn(t *testing.T, w *World, p, q int) {
+	t.Helper()
+	spawn := w.FindSpawn(p, q)
+	x, y, z := ToWorldSpace(spawn.X(), spawn.Y(), spawn.Z())
+	if below, _ := w.GetBlock(x, y-1, z); !w.GetBlockInfo(below).Collidable {
+		t.Errorf("spawn at %v isn't on a solid block", spawn)
+	}
+	for dy := 0; dy < 2; dy++ {
+		if block, _ := w.GetBlock(x, y+dy, z); block != Air {
+			t.Errorf("spawn at %v has block %v at y = %d", spawn, block, y+dy)
+		}
+	}
+}
+
+func TestFindSpawn(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	if got := w.FindSpawn(0, 0).Y(); got != testFloorY {
+		t.Errorf("got spawn at y = %v on a flat floor, want %v", got,
+			testFloorY)
+	}
+	checkSpawn(t, w, 0, 0)
+
+	// The player spawns on top of the highest solid block, even with air
+	// underneath it
+	w.SetBlock(0, 10, 0, testBlock(t, w, "Stone"))
+	if got := w.FindSpawn(0, 0).Y(); got != 11.0 {
+		t.Errorf("got spawn at y = %v on a block at y = 10, want 11", got)
+	}
+	checkSpawn(t, w, 0, 0)
+}
+
+func TestFindSpawnInUnloadedChunk(t *testing.T) {
+	w := newTestWorld()
+	spawn := w.FindSpawn(3, -2)
+	if spawn.Y() != testFloorY {
+		t.Errorf("got spawn at y = %v in an unloaded chunk, want %v",
+			spawn.Y(), testFloorY)
+	}
+	if spawn.X() != 3*ChunkWidth+0.5 || spawn.Z() != -2*ChunkDepth+0.5 {
+		t.Errorf("got spawn at %v, want the middle of the chunk's first "+
+			"column", spawn)
+	}
+	loadTestChunk(w, 3, -2)
+	checkSpawn(t, w, 3, -2)
+}
+
+func TestFindSpawnWithoutGround(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	for y := 0; y < testFloorY; y++ {
+		w.SetBlock(0, y, 0, Air)
+	}
+	if got := w.FindSpawn(0, 0).Y(); got != defaultSpawnHeight {
+		t.Errorf("got spawn at y = %v above an empty column, want %v", got,
+			defaultSpawnHeight)
+	}
+}
@@ -6,11 +6,11 @@ type blockGenInfo struct {
 	p, q int // The location of the chunk to generate terrain data for
 }
 
-// GenBlocks takes the coordinates for a chunk and procedurally generates the
-// chunk's block data.
-func genBlocks(p, q int) blockData {
+// GenBlocks takes the coordinates and height of a chunk and procedurally
+// generates the chunk's block data.
+func genBlocks(p, q, height int) blockData {
 	// Create the block array
-	blocks := newBlockData()
+	blocks := newBlockData(height)
 
 	// Populate the bottom 3 layers with stone
 	for x := 0; x < ChunkWidth; x++ {
@@ -0,0 +1,112 @@
+package world
+
+const (
+	// The number of random blocks in each chunk that are ticked every update.
+	randomTicksPerChunk = 3
+
+	// The radius, in chunks, around the chunk that chunks are being loaded
+	// around, within which blocks receive random ticks.
+	randomTickRadius = 8
+
+	// The maximum horizontal and vertical distance (in blocks) that grass can
+	// spread from a grass block onto a dirt block in a single tick. Grass
+	// spreads further downwards than upwards, so it can creep down hills.
+	grassSpreadRadius = 1
+	grassSpreadAbove  = 1
+	grassSpreadBelow  = 3
+)
+
+// TickHandler is called when a block receives a random tick, with the block
+// and its world-space coordinates.
+type tickHandler func(w *World, b Block, wx, wy, wz int)
+
+// RegisterTickHandlers sets up the function called when each type of block
+// receives a random tick. Blocks without a handler ignore random ticks.
+func (w *World) registerTickHandlers() {
+	w.tickHandlers = make(map[Block]tickHandler)
+
+	// Dirt turns into grass when there's grass nearby
+	dirt, hasDirt := w.blocksInfo.find("Dirt")
+	grass, hasGrass := w.blocksInfo.find("Grass")
+	if hasDirt && hasGrass {
+		w.tickHandlers[dirt] = func(w *World, b Block, wx, wy, wz int) {
+			if w.canGrassSpreadTo(wx, wy, wz, grass) {
+				w.SetBlock(wx, wy, wz, grass)
+			}
+		}
+	}
+}
+
+// RandomTick picks a few random blocks in each chunk near the player and calls
+// the tick handler for each of their types. Random ticks are used for things
+// that happen slowly over time, like grass spreading.
+//
+// Chunks are visited in a fixed order so that, together with the world's
+// seed, the same blocks are always picked in the same order.
+func (w *World) randomTick() {
+	if len(w.tickHandlers) == 0 {
+		return
+	}
+	r := randomTickRadius
+	for p := w.centerP - r; p <= w.centerP+r; p++ {
+		for q := w.centerQ - r; q <= w.centerQ+r; q++ {
+			dp, dq := p-w.centerP, q-w.centerQ
+			if dp*dp+dq*dq > r*r {
+				continue
+			}
+			chunk := w.FindChunk(p, q)
+			if chunk == nil || chunk.Blocks == nil {
+				continue
+			}
+			for i := 0; i < randomTicksPerChunk; i++ {
+				w.tickRandomBlock(chunk, p, q)
+			}
+		}
+	}
+}
+
+// TickRandomBlock picks a random block in a chunk and calls the tick handler
+// for its type, if it has one.
+func (w *World) tickRandomBlock(chunk *Chunk, p, q int) {
+	// Always pick all three coordinates, so the sequence of random numbers
+	// doesn't depend on which blocks happen to have handlers
+	x := w.rand.Intn(ChunkWidth)
+	y := w.rand.Intn(chunk.Blocks.height())
+	z := w.rand.Intn(ChunkDepth)
+	block := *chunk.Blocks.At(x, y, z)
+	if handler, ok := w.tickHandlers[block.Type()]; ok {
+		handler(w, block, p*ChunkWidth+x, y, q*ChunkDepth+z)
+	}
+}
+
+// CanGrassSpreadTo returns true if grass can grow on the block at the given
+// world-space coordinates. The block must have direct access to the sky, and
+// there must be a grass block nearby for the grass to spread from.
+func (w *World) canGrassSpreadTo(wx, wy, wz int, grass Block) bool {
+	if !w.hasSkyAccess(wx, wy, wz) {
+		return false
+	}
+	for dx := -grassSpreadRadius; dx <= grassSpreadRadius; dx++ {
+		for dy := -grassSpreadBelow; dy <= grassSpreadAbove; dy++ {
+			for dz := -grassSpreadRadius; dz <= grassSpreadRadius; dz++ {
+				b, ok := w.GetBlock(wx+dx, wy+dy, wz+dz)
+				if ok && b.Type() == grass {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// HasSkyAccess returns true if every block above the one at the given
+// world-space coordinates, up to the top of the world, is transparent.
+func (w *World) hasSkyAccess(wx, wy, wz int) bool {
+	for y := wy + 1; y < w.Height; y++ {
+		b, ok := w.GetBlock(wx, y, wz)
+		if !ok || !w.blocksInfo.get(b).Transparent {
+			return false
+		}
+	}
+	return true
+}
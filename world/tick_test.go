@@ -0,0 +1,37 @@
+package world
+
+import "testing"
+
+func TestGrassSpreadsToDirt(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	dirt := testBlock(t, w, "Dirt")
+	grass := testBlock(t, w, "Grass")
+	stone := testBlock(t, w, "Stone")
+
+	// A row of dirt with grass at one end, a dirt block next to the grass
+	// that's covered over, and a dirt block far away from any grass
+	for x := 0; x < ChunkWidth; x++ {
+		w.SetBlock(x, 3, 5, dirt)
+	}
+	w.SetBlock(0, 3, 5, grass)
+	w.SetBlock(1, 3, 6, dirt)
+	w.SetBlock(1, 4, 6, stone)
+	w.SetBlock(10, 3, 12, dirt)
+
+	// The world's random number generator is seeded, so the same blocks are
+	// ticked every time the test is run
+	for i := 0; i < 20000; i++ {
+		w.randomTick()
+	}
+
+	if b, _ := w.GetBlock(1, 3, 5); b != grass {
+		t.Error("grass didn't spread to the dirt next to it")
+	}
+	if b, _ := w.GetBlock(1, 3, 6); b != dirt {
+		t.Error("grass spread to dirt without access to the sky")
+	}
+	if b, _ := w.GetBlock(10, 3, 12); b != dirt {
+		t.Error("grass spread to dirt with no grass nearby")
+	}
+}
@@ -2,13 +2,71 @@ package world
 
 // ValuesPerVertex tells us the number of floating point values emitted per
 // vertex.
-const valuesPerVertex = 8
+const valuesPerVertex = 10
+
+// CubeVertices lists all the vertices that make up a cube.
+var cubeVertices = [...][3]float32{
+	{0.0, 0.0, 1.0}, // Left,  bottom, front
+	{1.0, 0.0, 1.0}, // Right, bottom, front
+	{1.0, 1.0, 1.0}, // Right, top,    front
+	{0.0, 1.0, 1.0}, // Left,  top,    front
+	{0.0, 0.0, 0.0}, // Left,  bottom, back
+	{1.0, 0.0, 0.0}, // Right, bottom, back
+	{1.0, 1.0, 0.0}, // Right, top,    back
+	{0.0, 1.0, 0.0}, // Left,  top,    back
+}
+
+// FaceCorners lists the four corners of each face of a cube, going around
+// the face, indexed by face. A corner is specified by an index into the
+// `cubeVertices` array above.
+var faceCorners = [...][4]uint16{
+	{7, 4, 0, 3}, // Left
+	{2, 1, 5, 6}, // Right
+	{6, 7, 3, 2}, // Top
+	{0, 4, 5, 1}, // Bottom
+	{3, 0, 1, 2}, // Front
+	{6, 5, 4, 7}, // Back
+}
+
+// QuadIndices lists the corners of a face (as indices into the face's entry in
+// `faceCorners`) that make up the two triangles the face is drawn with.
+var quadIndices = [...]uint32{0, 1, 2, 2, 3, 0}
+
+// Mesh stores the vertex data for a chunk, and the indices of the vertices
+// making up each triangle. Each face is made up of four vertices, which are
+// shared between the face's two triangles.
+type mesh struct {
+	vertices []float32
+	indices  []uint32
+}
+
+// AddQuad adds the indices for the two triangles of a face, made up of the
+// last four vertices added to the mesh.
+func (m *mesh) addQuad() {
+	first := uint32(len(m.vertices)/valuesPerVertex - 4)
+	for _, index := range quadIndices {
+		m.indices = append(m.indices, first+index)
+	}
+}
+
+// Append adds the vertices and triangles of another mesh after this mesh's.
+func (m *mesh) append(other *mesh) {
+	offset := uint32(len(m.vertices) / valuesPerVertex)
+	m.vertices = append(m.vertices, other.vertices...)
+	for _, index := range other.indices {
+		m.indices = append(m.indices, offset+index)
+	}
+}
 
 // VertexGenInfo contains the necessary information to generate vertex data for
 // a chunk.
 type vertexGenInfo struct {
-	p, q   int       // The chunk to generate vertex data for
-	blocks blockData // A copy of the chunk's block data
+	p, q   int         // The chunk to generate vertex data for
+	blocks blockData   // A copy of the chunk's block data
+	light  lightData   // The light level of each block in the chunk
+	border borderLight // Light levels from the neighbouring chunks
+	smooth bool        // True to interpolate light across faces
+	lod    int         // The level of detail to generate (see `genLODVertices`)
 
 	// Information about each block type, indexed by ID. This is only ever read
 	// from (never written to), so we're not going to get any race conditions.
@@ -17,94 +75,223 @@ type vertexGenInfo struct {
 
 // GenVertices takes the block data for a chunk and generates the chunk's
 // vertex data, based on the faces of the blocks that are visible.
-func genVertices(info vertexGenInfo) []float32 {
-	// Generate vertex data for each block in the chunk
-	var vertices []float32
+//
+// The faces of translucent blocks are placed after all the other faces, so
+// that they can be drawn separately once everything behind them has been
+// drawn. Returns the mesh, and the number of indices making up triangles that
+// aren't translucent.
+func genVertices(info vertexGenInfo) (*mesh, int32) {
+	// Distant chunks are built from fewer, larger blocks
+	if info.lod > 0 {
+		return genLODVertices(info)
+	}
+
+	// Generate vertex data for each block in the chunk. The meshes come from
+	// the pool, and the translucent faces are only needed until they're added
+	// after the opaque ones, so their mesh goes straight back to it
+	opaque, translucent := newMesh(), newMesh()
 	for x := 0; x < ChunkWidth; x++ {
-		for y := 0; y < ChunkHeight; y++ {
+		for y := 0; y < info.blocks.height(); y++ {
 			for z := 0; z < ChunkDepth; z++ {
-				genVerticesForBlock(&vertices, info, x, y, z)
+				m := opaque
+				if info.blocksInfo.get(*info.blocks.At(x, y, z)).Translucent {
+					m = translucent
+				}
+				genVerticesForBlock(m, info, x, y, z)
 			}
 		}
 	}
 
-	return vertices
+	numOpaque := int32(len(opaque.indices))
+	opaque.append(translucent)
+	freeMesh(translucent)
+	return opaque, numOpaque
 }
 
 // GenVerticesForBlock determines which faces of the block at the given
 // coordinates are visible, and adds them to the vertex data.
-func genVerticesForBlock(vertices *[]float32, info vertexGenInfo, x, y, z int) {
+func genVerticesForBlock(m *mesh, info vertexGenInfo, x, y, z int) {
 	// Don't generate vertices for invisible blocks
 	current := info.blocks.At(x, y, z)
 	if current == nil || !info.blocksInfo.get(*current).Visible {
 		return
 	}
 
-	// Generate vertex data for each face
-	for face := faceLeft; face <= faceBack; face++ {
-		// Get the coordinate of the block next to this face
-		nx, ny, nz := face.normal()
-		bx, by, bz := x+nx, y+ny, z+nz
-
-		// Only generate vertex data if the block next to this face is
-		// semi-transparent, or if the block is at a chunk border
-		neighbour := info.blocks.At(bx, by, bz)
-		if neighbour == nil || info.blocksInfo.get(*neighbour).Transparent {
-			genVerticesForFace(vertices, info, *current, x, y, z, face)
+	// Fluids fill the block up to the height of their surface
+	blockInfo := info.blocksInfo.get(*current)
+	boxes := blockInfo.getShape().boxes
+	if blockInfo.Fluid {
+		height := renderedFluidHeight(info, x, y, z)
+		boxes = []shapeBox{{[3]float32{0, 0, 0}, [3]float32{1, height, 1}}}
+	}
+
+	// Generate vertex data for each face of each box making up the block. Only
+	// faces on the boundary of the block can be hidden by the block next to
+	// them; faces inside the block (like the top of a slab) are always visible
+	for _, box := range boxes {
+		for face := faceLeft; face <= faceBack; face++ {
+			if !box.onBoundary(face) || !isSideHidden(info, x, y, z, face) {
+				genVerticesForFace(m, info, *current, x, y, z, face, box)
+			}
 		}
 	}
 }
 
-// GenVerticesForFace adds the vertex data for a visible face of a block to
-// the vertices list.
-func genVerticesForFace(vertices *[]float32, info vertexGenInfo, block Block,
-	x, y, z int, face blockFace) {
-	// All vertices that make up a cube
-	cubeVertices := [...][3]float32{
-		{0.0, 0.0, 1.0}, // Left,  bottom, front
-		{1.0, 0.0, 1.0}, // Right, bottom, front
-		{1.0, 1.0, 1.0}, // Right, top,    front
-		{0.0, 1.0, 1.0}, // Left,  top,    front
-		{0.0, 0.0, 0.0}, // Left,  bottom, back
-		{1.0, 0.0, 0.0}, // Right, bottom, back
-		{1.0, 1.0, 0.0}, // Right, top,    back
-		{0.0, 1.0, 0.0}, // Left,  top,    back
+// IsSideHidden returns true if the side of the block at the given coordinates
+// facing in the direction of `face` is completely covered by the block next to
+// it, in which case any faces on that side can't be seen. Blocks at a chunk
+// border are never hidden by blocks in the neighbouring chunk.
+//
+// A transparent full cube (like glass) can be seen through, so only hides
+// blocks of the same type (so there's no visible face between two blocks of
+// water, for example). Other shapes are only transparent because of the parts
+// of the block they don't fill, so they hide any side they completely cover.
+func isSideHidden(info vertexGenInfo, x, y, z int, face blockFace) bool {
+	nx, ny, nz := face.normal()
+	neighbour := info.blocks.At(x+nx, y+ny, z+nz)
+	if neighbour == nil {
+		return false
 	}
+	neighbourInfo := info.blocksInfo.get(*neighbour)
+	shape := neighbourInfo.getShape()
+	if neighbourInfo.Transparent && shape == &cubeShape {
+		current := *info.blocks.At(x, y, z)
+		if neighbour.Type() != current.Type() {
+			return false
+		}
 
-	// Vertices that make up each face of a cube. A vertex is specified by an
-	// index into the `cubeVertices` array above
-	faceIndices := [...][6]uint16{
-		{7, 4, 0, 0, 3, 7}, // Left
-		{2, 1, 5, 5, 6, 2}, // Right
-		{6, 7, 3, 3, 2, 6}, // Top
-		{0, 4, 5, 5, 1, 0}, // Bottom
-		{3, 0, 1, 1, 2, 3}, // Front
-		{6, 5, 4, 4, 7, 6}, // Back
+		// The side of a fluid is only hidden if the fluid next to it is at
+		// least as high
+		return !neighbourInfo.Fluid || ny != 0 ||
+			renderedFluidHeight(info, x+nx, y, z+nz) >=
+				renderedFluidHeight(info, x, y, z)
 	}
+	return shape.fullFaces[face.opposite()]
+}
 
-	// UVs for each vertex, for all faces on a cube
-	faceUVs := [...][2]float32{
-		{0.0, 0.0}, {0.0, 1.0}, {1.0, 1.0}, {1.0, 1.0}, {1.0, 0.0}, {0.0, 0.0},
+// RenderedFluidHeight returns the height of the top surface of the fluid at
+// the given coordinates, as a fraction of a full block. Fluid with more of the
+// same fluid above it fills the whole block, so there's no gap between them.
+func renderedFluidHeight(info vertexGenInfo, x, y, z int) float32 {
+	current := *info.blocks.At(x, y, z)
+	above := info.blocks.At(x, y+1, z)
+	if above != nil && above.Type() == current.Type() {
+		return 1.0
 	}
+	return fluidHeight(current, info.blocksInfo.get(current).FlowDistance)
+}
+
+// GenVerticesForFace adds the vertex data for a visible face of one of the
+// boxes making up a block to the mesh.
+func genVerticesForFace(m *mesh, info vertexGenInfo, block Block,
+	x, y, z int, face blockFace, box shapeBox) {
+	// The face is lit by the block in front of it
+	nx, ny, nz := face.normal()
+	light := lightAt(info, x+nx, y+ny, z+nz)
 
-	// Iterate over the 6 vertices of the 2 triangles that make up the face
-	for vertex := 0; vertex < 6; vertex++ {
-		// Position
-		position := &cubeVertices[faceIndices[face][vertex]]
+	// Blocks lying on their side use the textures of a different face, and
+	// some of their textures are rotated to run along the block
+	textureFace, rotate := orientFace(face, block.Axis())
+	uv := info.blocksInfo.get(block).UV[textureFace]
+	w, h := uv.Size()
+
+	// Iterate over the 4 corners of the face
+	vertices := &m.vertices
+	for vertex := 0; vertex < 4; vertex++ {
+		// Position, scaling the cube's vertex to fit the box
+		corner := &cubeVertices[faceCorners[face][vertex]]
+		var position [3]float32
+		for axis := 0; axis < 3; axis++ {
+			position[axis] = box.min[axis] +
+				corner[axis]*(box.max[axis]-box.min[axis])
+		}
 		*vertices = append(*vertices, float32(info.p*ChunkWidth+x)+position[0])
 		*vertices = append(*vertices, float32(y)+position[1])
 		*vertices = append(*vertices, float32(info.q*ChunkDepth+z)+position[2])
 
 		// Normal
-		nx, ny, nz := face.normal()
 		*vertices = append(*vertices, float32(nx))
 		*vertices = append(*vertices, float32(ny))
 		*vertices = append(*vertices, float32(nz))
 
-		// UV
-		uv := info.blocksInfo.get(block).UV
-		w, h := uv.Size()
-		*vertices = append(*vertices, uv.X+w*faceUVs[vertex][0])
-		*vertices = append(*vertices, uv.Y+h*faceUVs[vertex][1])
+		// UV, using only the part of the texture covered by the box
+		u, v := faceUV(face, position)
+		if rotate {
+			u, v = v, 1.0-u
+		}
+		*vertices = append(*vertices, uv.X+w*u)
+		*vertices = append(*vertices, uv.Y+h*v)
+
+		// Light, sampled from the block this face is facing into, or from
+		// all the blocks in front of the face that touch this vertex
+		if info.smooth {
+			*vertices = append(*vertices,
+				smoothLight(info, x, y, z, face, *corner))
+		} else {
+			*vertices = append(*vertices, float32(light)/maxLight)
+		}
+
+		// Number of animation frames, which the chunk shader cycles through
+		*vertices = append(*vertices, float32(uv.Frames))
 	}
+	m.addQuad()
+}
+
+// FaceUV returns the texture coordinates, between 0 and 1, for a point on a
+// face of a block, given the point's position relative to the block's minimum
+// corner. Textures are oriented so that they're upright on the sides of a
+// block. Since the texture coordinates depend on the point's position, faces
+// of boxes smaller than a block only show the part of the texture they cover
+// (e.g. the sides of a slab show the bottom half of the texture).
+func faceUV(face blockFace, pos [3]float32) (u, v float32) {
+	switch face {
+	case faceLeft:
+		return pos[2], 1.0 - pos[1]
+	case faceRight:
+		return 1.0 - pos[2], 1.0 - pos[1]
+	case faceTop:
+		return pos[2], 1.0 - pos[0]
+	case faceBottom:
+		return pos[0], 1.0 - pos[2]
+	case faceFront:
+		return pos[0], 1.0 - pos[1]
+	default:
+		return 1.0 - pos[0], 1.0 - pos[1]
+	}
+}
+
+// SmoothLight calculates the light level for a vertex of a face when using
+// smooth lighting, by averaging the light levels of the four blocks in front
+// of the face that touch the vertex. Opaque blocks have no light, so this also
+// darkens corners tucked in against other blocks (ambient occlusion).
+func smoothLight(info vertexGenInfo, x, y, z int, face blockFace,
+	corner [3]float32) float32 {
+	// The block directly in front of the face
+	nx, ny, nz := face.normal()
+	normal := [3]int{nx, ny, nz}
+	front := [3]int{x + nx, y + ny, z + nz}
+
+	// Find the two directions within the plane of the face that point from
+	// the centre of the face towards the vertex
+	var tangents [2][3]int
+	count := 0
+	for axis := 0; axis < 3; axis++ {
+		if normal[axis] != 0 {
+			continue
+		}
+		tangents[count][axis] = -1
+		if corner[axis] > 0.5 {
+			tangents[count][axis] = 1
+		}
+		count++
+	}
+
+	// Average the light of the four blocks surrounding the vertex
+	t1, t2 := tangents[0], tangents[1]
+	sum := int(lightAt(info, front[0], front[1], front[2]))
+	sum += int(lightAt(info, front[0]+t1[0], front[1]+t1[1], front[2]+t1[2]))
+	sum += int(lightAt(info, front[0]+t2[0], front[1]+t2[1], front[2]+t2[2]))
+	sum += int(lightAt(info, front[0]+t1[0]+t2[0], front[1]+t1[1]+t2[1],
+		front[2]+t1[2]+t2[2]))
+	return float32(sum) / 4.0 / maxLight
 }
@@ -0,0 +1,76 @@
+package world
+
+// Weather is the state of the weather across the whole world.
+type Weather int
+
+// All possible weather states.
+const (
+	Clear Weather = iota
+	Rain
+)
+
+// RainFadeTicks is the number of update ticks taken for rain to fade in
+// completely when it starts raining, or fade out when it stops.
+const rainFadeTicks = 300
+
+// Weather returns the current weather. The rain strength may still be fading
+// towards it.
+func (w *World) Weather() Weather {
+	return w.weather
+}
+
+// SetWeather changes the weather. Rain fades in or out over a few seconds,
+// rather than starting or stopping immediately.
+func (w *World) SetWeather(weather Weather) {
+	w.weather = weather
+}
+
+// RainStrength returns how heavily it's raining, between 0 (no rain) and 1
+// (raining fully). It's in between while rain is fading in or out.
+func (w *World) RainStrength() float32 {
+	return w.rainStrength
+}
+
+// UpdateWeather moves the rain strength one update tick closer to the
+// current weather.
+func (w *World) updateWeather() {
+	target := float32(0.0)
+	if w.weather == Rain {
+		target = 1.0
+	}
+	step := float32(1.0) / rainFadeTicks
+	if w.rainStrength < target {
+		w.rainStrength += step
+		if w.rainStrength > target {
+			w.rainStrength = target
+		}
+	} else if w.rainStrength > target {
+		w.rainStrength -= step
+		if w.rainStrength < target {
+			w.rainStrength = target
+		}
+	}
+}
+
+// HasSkyAccess returns true if there's nothing between the given world-space
+// block coordinates and the sky that would stop rain from falling on it:
+// rain is stopped by any block that's collidable or a fluid. Positions above
+// the top of the world always have sky access; positions in chunks that
+// aren't loaded never do.
+func (w *World) HasSkyAccess(wx, wy, wz int) bool {
+	if wy >= w.Height {
+		return true
+	}
+	p, q, x, _, z := ToChunkSpace(wx, 0, wz)
+	chunk := w.FindChunk(p, q)
+	if chunk == nil || chunk.Blocks == nil || wy < 0 {
+		return false
+	}
+	for y := wy + 1; y < w.Height; y++ {
+		info := w.blocksInfo.get(*chunk.Blocks.At(x, y, z))
+		if info.Collidable || info.Fluid {
+			return false
+		}
+	}
+	return true
+}
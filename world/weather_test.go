@@ -0,0 +1,61 @@
+package world
+
+import "testing"
+
+func TestHasSkyAccess(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	floor := int(testFloorY)
+	if !w.HasSkyAccess(2, floor, 2) {
+		t.Error("no sky access on an open floor")
+	}
+
+	// Solid blocks and fluids anywhere above cover the position; the block
+	// at the position itself doesn't
+	covers := map[string]int{"Stone": 2, "Water": 5, "Glowstone": 25}
+	x := 4
+	for name, height := range covers {
+		w.SetBlock(x, floor+height, 2, testBlock(t, w, name))
+		if w.HasSkyAccess(x, floor, 2) {
+			t.Errorf("sky access under %s %d blocks up", name, height)
+		}
+		if !w.HasSkyAccess(x, floor+height, 2) {
+			t.Errorf("no sky access at the top of %s", name)
+		}
+		x++
+	}
+
+	// Above the world is always open, and unloaded chunks are never open
+	if !w.HasSkyAccess(2, testHeight+10, 2) {
+		t.Error("no sky access above the top of the world")
+	}
+	if w.HasSkyAccess(100, testHeight-1, 100) {
+		t.Error("sky access in an unloaded chunk")
+	}
+}
+
+func TestRainFades(t *testing.T) {
+	w := newTestWorld()
+	w.SetWeather(Rain)
+	for i := 0; i < rainFadeTicks/2; i++ {
+		w.updateWeather()
+	}
+	if got := w.RainStrength(); got <= 0.4 || got >= 0.6 {
+		t.Errorf("got rain strength %v halfway through fading in, want 0.5",
+			got)
+	}
+	for i := 0; i < rainFadeTicks; i++ {
+		w.updateWeather()
+	}
+	if got := w.RainStrength(); got != 1.0 {
+		t.Errorf("got rain strength %v after fading in, want 1", got)
+	}
+
+	w.SetWeather(Clear)
+	for i := 0; i < rainFadeTicks+1; i++ {
+		w.updateWeather()
+	}
+	if got := w.RainStrength(); got != 0.0 {
+		t.Errorf("got rain strength %v after fading out, want 0", got)
+	}
+}
@@ -1,27 +1,39 @@
 package world
 
 import (
-	"log"
-	"unsafe"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"time"
 
 	"github.com/benanders/mineral/camera"
+	"github.com/benanders/mineral/math"
 	"github.com/benanders/mineral/render"
 
 	"github.com/chewxy/math32"
 	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
 )
 
 const (
 	// MaxRenderRadius is the maximum number of chunks ahead of the player which
-	// we can feasibly render.
-	MaxRenderRadius = 32
+	// we can feasibly render. Chunks any further away would be beyond the
+	// camera's far plane, so would be loaded but never seen.
+	MaxRenderRadius = int(camera.Far / ChunkWidth)
 
-	// DeleteRadiusFactor is the additional factor added to the render radius to
+	// DeleteRadiusPadding is the number of chunks added to the render radius to
 	// create the delete radius. Only chunks outside this delete radius will be
 	// unloaded as the player moves around. The delete radius is larger than the
 	// render radius so that if the player rapidly moves back and forth across
 	// a chunk boundary, we don't have to keep unloading and reloading chunks.
-	deleteRadiusFactor = 2
+	deleteRadiusPadding = 2
+
+	// MaxUploadsPerUpdate is the most chunks whose vertex data is uploaded to
+	// the GPU in a single update. Any other finished chunks wait until the
+	// next update, so that lots of chunks finishing at once (e.g. when the
+	// world is first loaded) don't cause a long pause.
+	maxUploadsPerUpdate = 2
 )
 
 // ToWorldSpace returns the absolute coordinate of the block that contains the
@@ -31,88 +43,189 @@ func ToWorldSpace(wx, wy, wz float32) (int, int, int) {
 }
 
 // ToChunkSpace returns the coordinates of the chunk and the block within that
-// chunk that contain the given world-space coordinate.
+// chunk that contain the given world-space coordinate. The coordinates within
+// the chunk are always between 0 and `ChunkWidth` or `ChunkDepth` (exclusive),
+// so block -1 is the last block in chunk -1.
 func ToChunkSpace(wx, wy, wz int) (p, q, x, y, z int) {
-	// Use floor to always round down towards negative infinity. Otherwise the
-	// 4 chunks around the centre of the world would have a (p, q) of (0, 0)
-	p = int(math32.Floor(float32(wx) / float32(ChunkWidth)))
-	q = int(math32.Floor(float32(wz) / float32(ChunkDepth)))
+	p, x = floorDivMod(wx, ChunkWidth)
+	q, z = floorDivMod(wz, ChunkDepth)
+	return p, q, x, wy, z
+}
 
-	// Go's modulus operator is stupid and returns negative numbers, so we fix
-	// this by adding on `ChunkWidth` or `ChunkDepth` if necessary
-	x = wx % ChunkWidth
-	if x < 0 {
-		x += ChunkWidth
-	}
-	y = wy
-	z = wz % ChunkDepth
-	if z < 0 {
-		z += ChunkDepth
+// FloorDivMod divides `a` by `b` (which must be positive), rounding down
+// towards negative infinity, and returns the quotient and the remainder, which
+// is always between 0 and `b` (exclusive). Go's division operator rounds
+// towards 0 instead, and its modulus operator returns negative remainders for
+// negative numbers. Without rounding down, the 4 chunks around the centre of
+// the world would all have a (p, q) of (0, 0).
+//
+// Integer division is used, rather than dividing floats, since a float32 can't
+// exactly represent coordinates more than 2^24 blocks from the origin.
+func floorDivMod(a, b int) (quotient, remainder int) {
+	quotient, remainder = a/b, a%b
+	if remainder < 0 {
+		quotient--
+		remainder += b
 	}
-	return
+	return quotient, remainder
 }
 
 // World manages the loading, unloading, and rendering of chunks.
 type World struct {
 	RenderRadius int                 // Current render distance
+	Height       int                 // Height of the world, in blocks
+	Seed         int64               // Seed for everything random
 	chunks       map[chunkPos]*Chunk // All loaded chunks
-	loading      []chan interface{}  // Channels to goroutines loading chunks
 	blocksInfo   BlocksInfo          // Information about each block type
 
-	// Shader program uniforms and attributes
-	program       uint32
-	mvpUnf        int32
-	blockAtlasUnf int32
-	posAttr       uint32
-	normalAttr    uint32
-	uvAttr        uint32
+	// Chunks are generated on a pool of worker goroutines. Jobs are queued in
+	// order of distance from the player's chunk, and only handed to a worker
+	// once it's idle, so that the nearest chunks are always generated first
+	queue    jobQueue          // Jobs waiting for an idle worker
+	pending  map[chunkPos]bool // Chunks queued or being generated
+	inFlight int               // Number of jobs currently being worked on
+	idle     chan struct{}     // Receives a token from each idle worker
+	jobs     chan chunkJob     // Sends jobs to idle workers
+	results  chan interface{}  // Receives results from workers
+
+	// Results received from workers that are still waiting to be uploaded,
+	// oldest first (see `maxUploadsPerUpdate`)
+	finished []interface{}
+
+	// The chunk that chunks are currently being loaded around
+	centerP, centerQ int
+
+	// Chunks that are never unloaded, regardless of their distance from the
+	// player
+	pinned map[chunkPos]bool
+
+	// Blocks picked at random are ticked every update, calling the handler
+	// registered for the block's type (see `randomTick`)
+	rand         *rand.Rand
+	tickHandlers map[Block]tickHandler
+
+	// The world-space coordinates of blocks affected by gravity that are
+	// falling, and the number of ticks since they last moved (see
+	// `updateFalling`)
+	falling   map[[3]int]bool
+	fallTimer int
+
+	// The world-space coordinates of fluid blocks waiting to flow, and the
+	// number of ticks since fluid last flowed (see `updateFlowing`)
+	flowing   map[[3]int]bool
+	flowTimer int
+
+	// The current weather, and how heavily it's raining, which fades towards
+	// the current weather over time (see `updateWeather`)
+	weather      Weather
+	rainStrength float32
+
+	// True if light is interpolated smoothly across block faces, rather than
+	// each face having a single flat light level
+	smoothLighting bool
+
+	// The folder edited chunks are saved to and loaded from, or empty if
+	// chunks aren't saved (see `SetSaveDir`)
+	saveDir string
+
+	// Shader program and attributes
+	program    *render.ReloadableProgram
+	posAttr    uint32
+	normalAttr uint32
+	uvAttr     uint32
+	lightAttr  uint32
+	framesAttr uint32
 
 	// Block texture atlas ID
 	terrainTexture uint32
 }
 
-// New creates a new world instance with no loaded chunks.
-func New(renderRadius int) *World {
+// LoadShaders loads the chunk shader program. Tests replace it to check that
+// shaders failing to load are handled.
+var loadShaders = render.LoadReloadableShaders
+
+// New creates a new world instance with no loaded chunks. The world is
+// `height` blocks tall, which must be between 1 and `MaxHeight`. Everything
+// random in the world is derived from `seed`, so two worlds created with the
+// same seed evolve in the same way. Returns an error if the height is invalid
+// or the chunk shaders fail to load.
+func New(renderRadius, height int, seed int64) (*World, error) {
+	if height < 1 || height > MaxHeight {
+		return nil, fmt.Errorf("invalid world height: %v", height)
+	}
+
 	// Load the chunk rendering program
-	program, err := render.LoadShaders(
+	program, err := loadShaders(
 		"shaders/chunkVert.glsl",
 		"shaders/chunkFrag.glsl")
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
 	}
-	gl.UseProgram(program)
+	program.Use()
 
-	// Cache the uniform locations
-	mvpUnf := gl.GetUniformLocation(program, gl.Str("mvp\x00"))
-	blockAtlasUnf := gl.GetUniformLocation(program, gl.Str("blockAtlas\x00"))
+	// The directional shading applied to each face never changes. The shader
+	// picks one of these for each vertex based on its normal
+	gl.Uniform4f(program.Uniform("faceShading"), faceTop.shade(),
+		faceBottom.shade(), faceLeft.shade(), faceFront.shade())
 
-	// Cache the attribute locations
-	posAttr := uint32(gl.GetAttribLocation(program, gl.Str("position\x00")))
-	normalAttr := uint32(gl.GetAttribLocation(program, gl.Str("normal\x00")))
-	uvAttr := uint32(gl.GetAttribLocation(program, gl.Str("uv\x00")))
+	// Neither does the layout or speed of animated textures
+	gl.Uniform1f(program.Uniform("frameHeight"), frameHeight)
+	gl.Uniform1f(program.Uniform("frameTime"), animationFrameTime)
+
+	// Cache the attribute locations, which stay the same even if the program
+	// is reloaded
+	posAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("position\x00")))
+	normalAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("normal\x00")))
+	uvAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("uv\x00")))
+	lightAttr := uint32(gl.GetAttribLocation(program.ID, gl.Str("light\x00")))
+	framesAttr := uint32(gl.GetAttribLocation(program.ID,
+		gl.Str("frames\x00")))
 
 	// Load information about each block type and create the block texture atlas
 	blocksInfo, terrainTexture := loadBlocksInfo()
 
-	return &World{
-		renderRadius,
-		make(map[chunkPos]*Chunk, 0),
-		make([]chan interface{}, 0),
-		blocksInfo,
-		program, mvpUnf, blockAtlasUnf, posAttr, normalAttr, uvAttr,
-		terrainTexture,
+	// Each worker holds on to at most one idle token or result, so buffering
+	// these channels by the number of workers means workers never block on
+	// sending either, even once the world has been destroyed
+	numWorkers := runtime.NumCPU()
+	w := &World{
+		RenderRadius:   renderRadius,
+		Height:         height,
+		Seed:           seed,
+		chunks:         make(map[chunkPos]*Chunk),
+		blocksInfo:     blocksInfo,
+		pending:        make(map[chunkPos]bool),
+		pinned:         make(map[chunkPos]bool),
+		falling:        make(map[[3]int]bool),
+		flowing:        make(map[[3]int]bool),
+		idle:           make(chan struct{}, numWorkers),
+		jobs:           make(chan chunkJob),
+		results:        make(chan interface{}, numWorkers),
+		program:        program,
+		posAttr:        posAttr,
+		normalAttr:     normalAttr,
+		uvAttr:         uvAttr,
+		lightAttr:      lightAttr,
+		framesAttr:     framesAttr,
+		terrainTexture: terrainTexture,
+		rand:           rand.New(rand.NewSource(seed)),
+	}
+	w.registerTickHandlers()
+
+	// Start the worker goroutines
+	for i := 0; i < numWorkers; i++ {
+		go w.worker()
 	}
+	return w, nil
 }
 
 // Destroy unloads all the currently loaded chunks.
 func (w *World) Destroy() {
-	gl.DeleteProgram(w.program)
+	w.program.Destroy()
 	gl.DeleteTextures(1, &w.terrainTexture)
 
-	// Close all loading to goroutines loading chunks
-	for _, ch := range w.loading {
-		close(ch)
-	}
+	// Stop all the worker goroutines
+	close(w.jobs)
 
 	// Destroy all loaded chunks
 	for pos, chunk := range w.chunks {
@@ -135,28 +248,253 @@ func (w *World) GetBlockInfo(block Block) *BlockInfo {
 	return w.blocksInfo.get(block)
 }
 
-// BlockVertexGenResult stores the block and vertex data generated for a chunk
-// upon initially loading the chunk.
-type blockVertexGenResult struct {
-	p, q     int       // The location of the chunk we generated vertex data for
-	blocks   blockData // The generated block data
-	vertices []float32 // The generated vertex data
+// ClampHeight clamps a range of world-space block y coordinates (from `y1` to
+// `y2` inclusive) to the blocks that exist within the height of the world, so
+// that loops over a region of blocks don't visit rows that are always empty.
+// The returned range is empty (`y1 > y2`) if it lies entirely outside the
+// world.
+func (w *World) ClampHeight(y1, y2 int) (int, int) {
+	if y1 < 0 {
+		y1 = 0
+	}
+	if y2 > w.Height-1 {
+		y2 = w.Height - 1
+	}
+	return y1, y2
+}
+
+// FindBlock returns the type of block with the given name (as it appears in
+// `blocks.toml`). Returns false if there's no block with that name.
+func (w *World) FindBlock(name string) (Block, bool) {
+	return w.blocksInfo.find(name)
+}
+
+// GetBlock returns the block at the given world-space coordinates. Returns
+// false if the chunk containing the block isn't loaded, or if the coordinates
+// are outside the vertical bounds of the world.
+func (w *World) GetBlock(wx, wy, wz int) (Block, bool) {
+	p, q, x, y, z := ToChunkSpace(wx, wy, wz)
+	chunk := w.FindChunk(p, q)
+	if chunk == nil || chunk.Blocks == nil {
+		return Air, false
+	}
+	block := chunk.Blocks.At(x, y, z)
+	if block == nil {
+		return Air, false
+	}
+	return *block, true
+}
+
+// SetBlock changes the block at the given world-space coordinates, and
+// regenerates the vertex data for the chunk containing it.
+//
+// Returns false if the chunk containing the block isn't loaded, or if the
+// coordinates are outside the vertical bounds of the world.
+func (w *World) SetBlock(wx, wy, wz int, b Block) bool {
+	// Find the block within its chunk
+	p, q, x, y, z := ToChunkSpace(wx, wy, wz)
+	chunk := w.FindChunk(p, q)
+	if chunk == nil || chunk.Blocks == nil {
+		return false
+	}
+	block := chunk.Blocks.At(x, y, z)
+	if block == nil {
+		return false
+	}
+
+	// Darken the area around the block if the new block casts a shadow, or
+	// the old one gave off light
+	if !w.blocksInfo.get(b).Transparent ||
+		w.blocksInfo.get(*block).LightEmission > 0 {
+		w.unlight(wx, wy, wz)
+	}
+
+	// Change the block and update the affected chunk meshes
+	*block = b
+	chunk.dirty = true
+	w.regenChunksAround(p, q, x, z)
+
+	w.updateOpaqueFaces(chunk, p, q, x, y, z)
+
+	// The new block may need to fall, or may have been holding up a block
+	// that now needs to fall. Fluid may also flow into or out of the block
+	w.checkFalling(wx, wy, wz)
+	w.checkFalling(wx, wy+1, wz)
+	w.checkFlowing(wx, wy, wz)
+	return true
+}
+
+// BreakBlock replaces the block at the given world-space coordinates with air,
+// returning the block that was there previously. The chunk containing the
+// block is regenerated, exposing the faces of the surrounding blocks.
+//
+// Returns false if the chunk containing the block isn't loaded, if there's no
+// block there to break, or if the block is unbreakable (see `Breakable`).
+func (w *World) BreakBlock(wx, wy, wz int) (Block, bool) {
+	// Find the block within its chunk
+	p, q, x, y, z := ToChunkSpace(wx, wy, wz)
+	chunk := w.FindChunk(p, q)
+	if chunk == nil || chunk.Blocks == nil {
+		return Air, false
+	}
+	block := chunk.Blocks.At(x, y, z)
+	if block == nil || *block == Air || !w.blocksInfo.get(*block).Breakable() {
+		return Air, false
+	}
+
+	// Darken the area the block lit, if it gave off light
+	previous := *block
+	if w.blocksInfo.get(previous).LightEmission > 0 {
+		w.unlight(wx, wy, wz)
+	}
+
+	// Remove the block and update the affected chunk meshes
+	*block = Air
+	chunk.dirty = true
+	w.regenChunksAround(p, q, x, z)
+	w.updateOpaqueFaces(chunk, p, q, x, y, z)
+
+	// Blocks affected by gravity above the broken block start to fall, and
+	// fluid next to it flows into the gap
+	w.checkFalling(wx, wy+1, wz)
+	w.checkFlowing(wx, wy, wz)
+	return previous, true
+}
+
+// UpdateOpaqueFaces is called after the block at the given chunk-space
+// coordinates in the chunk (p, q) changes. If the block is on one of the
+// chunk's faces, it might have opened up or closed off that face, which
+// changes whether the chunk and its neighbours are enclosed.
+func (w *World) updateOpaqueFaces(chunk *Chunk, p, q, x, y, z int) {
+	if isOnChunkFace(x, y, z, chunk.Blocks.height()) {
+		chunk.opaqueFaces = opaqueFaces(chunk.Blocks, &w.blocksInfo)
+		w.updateEnclosedAround(p, q)
+	}
+}
+
+// CanPlaceBlock checks whether a block of the given type can be placed at the
+// given world-space coordinates without overlapping any of the given AABBs
+// (usually the AABBs of every entity in the world, including the player).
+// Placing a block inside an entity would otherwise suffocate it.
+//
+// Non-collidable blocks can always be placed, since entities can move through
+// them.
+func (w *World) CanPlaceBlock(wx, wy, wz int, b Block,
+	occupied []math.AABB) bool {
+	info := w.GetBlockInfo(b)
+	if !info.Collidable {
+		return true
+	}
+
+	// Check the block's AABBs against every entity's AABB
+	p, q, x, y, z := ToChunkSpace(wx, wy, wz)
+	for _, aabb := range info.AABBs(p, q, x, y, z) {
+		for _, other := range occupied {
+			if aabb.Intersects(other) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CollisionAABBs returns the AABBs of every collidable block that overlaps the
+// given world-space region. Blocks in chunks that aren't loaded are skipped.
+func (w *World) CollisionAABBs(region math.AABB) []math.AABB {
+	x1, y1, z1 := ToWorldSpace(region.MinX(), region.MinY(), region.MinZ())
+	x2, y2, z2 := ToWorldSpace(region.MaxX(), region.MaxY(), region.MaxZ())
+	y1, y2 = w.ClampHeight(y1, y2)
+	var aabbs []math.AABB
+	for x := x1; x <= x2; x++ {
+		for y := y1; y <= y2; y++ {
+			for z := z1; z <= z2; z++ {
+				block, ok := w.GetBlock(x, y, z)
+				if !ok {
+					continue
+				}
+				info := w.GetBlockInfo(block)
+				if !info.Collidable {
+					continue
+				}
+				p, q, cx, cy, cz := ToChunkSpace(x, y, z)
+				for _, aabb := range info.AABBs(p, q, cx, cy, cz) {
+					if aabb.Intersects(region) {
+						aabbs = append(aabbs, aabb)
+					}
+				}
+			}
+		}
+	}
+	return aabbs
+}
+
+// RegenChunksAround regenerates the vertex data for the chunk (p, q) after the
+// block at (x, z) within it has been modified. If the block sits on a chunk
+// border, then the neighbouring chunk is also regenerated, since the faces
+// it culled against the modified block may now be visible (or vice versa).
+func (w *World) regenChunksAround(p, q, x, z int) {
+	w.regenChunk(p, q)
+	if x == 0 {
+		w.regenChunk(p-1, q)
+	} else if x == ChunkWidth-1 {
+		w.regenChunk(p+1, q)
+	}
+	if z == 0 {
+		w.regenChunk(p, q-1)
+	} else if z == ChunkDepth-1 {
+		w.regenChunk(p, q+1)
+	}
 }
 
 // GenChunksAround generates all chunks within the render radius around a
 // central chunk (usually the chunk that the player is in).
+//
+// Chunks are queued for generation in order of their distance from the
+// central chunk, so the nearest chunks are loaded first.
 func (w *World) GenChunksAround(p, q int) {
 	// Delete all chunks not within the delete radius around p, q
-	deleteRadius := w.RenderRadius + deleteRadiusFactor
+	deleteRadius := w.RenderRadius + deleteRadiusPadding
 	for pos, chunk := range w.chunks {
+		if w.pinned[pos] {
+			continue
+		}
 		dp := pos.p - p
 		dq := pos.q - q
-		if dp*dp+dq*dq > deleteRadius {
+		if dp*dp+dq*dq > deleteRadius*deleteRadius {
+			w.saveUnloadedChunk(pos, chunk)
 			chunk.destroy()
+			if chunk.Blocks != nil {
+				freeBlockData(chunk.Blocks)
+				chunk.Blocks = nil
+			}
 			delete(w.chunks, pos)
+			w.updateEnclosedAround(pos.p, pos.q)
 		}
 	}
 
+	// Drop any queued jobs for unpinned chunks outside the delete radius, so
+	// they aren't loaded only to be unloaded again. Jobs already handed to a
+	// worker can't be cancelled
+	w.queue.prune(func(job chunkJob) bool {
+		if w.pinned[job.pos] ||
+			chunkDistSq(job.pos, p, q) <= deleteRadius*deleteRadius {
+			return false
+		}
+		if !job.regen {
+			delete(w.pending, job.pos)
+		}
+		return true
+	})
+
+	// Re-sort the queue if the central chunk has changed, and rebuild the
+	// vertex data of any chunks that have moved across a level of detail
+	// boundary
+	if p != w.centerP || q != w.centerQ {
+		w.centerP, w.centerQ = p, q
+		w.queue.rekey(p, q)
+		w.regenChangedLODs()
+	}
+
 	// Iterate over all chunks around p, q within the render radius
 	for dp := -w.RenderRadius; dp <= w.RenderRadius; dp++ {
 		for dq := -w.RenderRadius; dq <= w.RenderRadius; dq++ {
@@ -168,36 +506,150 @@ func (w *World) GenChunksAround(p, q int) {
 	}
 }
 
-// GenChunk first generates block data for a chunk, then the chunk's vertex
-// data from this, on a separate goroutine.
-//
-// If the chunk at the given coordinates is already loaded, then the function
-// does nothing.
-func (w *World) genChunk(p, q int) {
-	// Check the chunk isn't already loaded
-	if chunk := w.FindChunk(p, q); chunk != nil {
+// PinChunk prevents the chunk at the given coordinates from ever being
+// unloaded, loading it if it isn't already. Pinned chunks are still only
+// rendered if they're within the render radius.
+func (w *World) PinChunk(p, q int) {
+	w.pinned[chunkPos{p, q}] = true
+	w.genChunk(p, q)
+}
+
+// UnpinChunk allows a chunk previously pinned with `PinChunk` to be unloaded
+// again once it's outside the delete radius.
+func (w *World) UnpinChunk(p, q int) {
+	delete(w.pinned, chunkPos{p, q})
+}
+
+// SetRenderRadius changes the number of chunks around the player that are
+// loaded and rendered, clamped between 1 and `MaxRenderRadius`. Chunks in the
+// new outer ring are queued for generation immediately when the radius grows,
+// and chunks left outside the delete radius are unloaded when it shrinks.
+func (w *World) SetRenderRadius(radius int) {
+	if radius < 1 {
+		radius = 1
+	} else if radius > MaxRenderRadius {
+		radius = MaxRenderRadius
+	}
+	if radius == w.RenderRadius {
 		return
 	}
+	w.RenderRadius = radius
+	w.GenChunksAround(w.centerP, w.centerQ)
 
-	// Load the chunk's block and vertex data
-	ch := make(chan interface{})
-	w.loading = append(w.loading, ch)
-	go (func() {
-		blocks := genBlocks(p, q)
-		vertices := genVertices(vertexGenInfo{p, q, blocks, &w.blocksInfo})
-		ch <- blockVertexGenResult{p, q, blocks, vertices}
-	})()
+	// The level of detail boundaries move with the render radius
+	w.regenChangedLODs()
 }
 
-// VertexGenResult stores the data generated when a chunk's vertex data is
-// reloaded from its existing block data.
-type vertexGenResult struct {
-	p, q     int       // The location of the chunk we generated vertex data for
-	vertices []float32 // The generated vertex data itself
+// RegenChangedLODs rebuilds the vertex data of every loaded chunk that's no
+// longer meshed at the right level of detail for its distance from the
+// central chunk.
+func (w *World) regenChangedLODs() {
+	for pos, chunk := range w.chunks {
+		distSq := chunkDistSq(pos, w.centerP, w.centerQ)
+		lod := chunkLOD(distSq, w.RenderRadius)
+		if chunk.Blocks != nil && chunk.lod != lod {
+			w.regenChunk(pos.p, pos.q)
+		}
+	}
+}
+
+// SetSmoothLighting switches between smooth lighting, where light levels are
+// interpolated across each block face, and flat lighting, where each face has
+// a single light level (which is faster to generate). All loaded chunks are
+// regenerated with the new setting.
+func (w *World) SetSmoothLighting(smooth bool) {
+	if smooth == w.smoothLighting {
+		return
+	}
+	w.smoothLighting = smooth
+	for pos := range w.chunks {
+		w.regenChunk(pos.p, pos.q)
+	}
+}
+
+// PendingChunkCount returns the number of chunk jobs that are either waiting
+// in the queue, currently being worked on, or finished but not yet uploaded.
+// Useful for displaying a loading indicator.
+func (w *World) PendingChunkCount() int {
+	return len(w.queue) + w.inFlight + len(w.finished)
+}
+
+// ChunkState describes how far through loading a chunk is.
+type ChunkState int
+
+// Each state a chunk goes through while loading.
+const (
+	ChunkLoading ChunkState = iota // Waiting for its blocks to be generated
+	ChunkLoaded                    // Blocks loaded, but nothing to draw
+	ChunkMeshed                    // Blocks loaded, and vertex data uploaded
+)
+
+// ChunkSnapshot describes a single chunk, for use by code building on top of
+// the world (such as editors or viewers) that needs to know where chunks are
+// without access to the world's internals.
+type ChunkSnapshot struct {
+	P, Q  int        // The chunk's coordinates
+	AABB  math.AABB  // The world-space volume the chunk occupies
+	Ready bool       // True if the chunk's blocks and vertex data are loaded
+	State ChunkState // How far through loading the chunk is
+}
+
+// ChunkSnapshots returns a description of every loaded chunk, as well as every
+// chunk still waiting to be generated (which isn't yet ready).
+//
+// Like all other world methods, this must be called from the same goroutine
+// that calls `Update`, since the set of loaded chunks changes during an
+// update. The returned slice is a copy, so can be safely used from any
+// goroutine afterwards, but it won't reflect any changes to the world made
+// after it was taken.
+func (w *World) ChunkSnapshots() []ChunkSnapshot {
+	snapshots := make([]ChunkSnapshot, 0, len(w.chunks)+len(w.pending))
+	for pos, chunk := range w.chunks {
+		snapshots = append(snapshots, ChunkSnapshot{pos.p, pos.q,
+			w.ChunkAABB(pos.p, pos.q), chunk.Blocks != nil, chunk.state()})
+	}
+	for pos := range w.pending {
+		snapshots = append(snapshots, ChunkSnapshot{pos.p, pos.q,
+			w.ChunkAABB(pos.p, pos.q), false, ChunkLoading})
+	}
+	return snapshots
 }
 
-// RegenChunk regenerates the vertex data for the chunk at the given
-// coordinates on a separate goroutine, using its existing block data. This
+// ChunkAABB returns the world-space bounding box of the chunk (p, q), spanning
+// the full height of the world. Chunk (p, q) contains the blocks whose x
+// coordinates lie in [p * ChunkWidth, (p + 1) * ChunkWidth), and likewise for
+// z, consistent with `ToChunkSpace` (which rounds down, so chunk -1 lies just
+// below 0).
+func (w *World) ChunkAABB(p, q int) math.AABB {
+	return math.AABB{
+		Center: mgl32.Vec3{
+			(float32(p) + 0.5) * ChunkWidth,
+			float32(w.Height) / 2.0,
+			(float32(q) + 0.5) * ChunkDepth,
+		},
+		Size: mgl32.Vec3{ChunkWidth, float32(w.Height), ChunkDepth},
+	}
+}
+
+// GenChunk queues a job to first generate block data for a chunk, then the
+// chunk's vertex data from this, on a worker goroutine.
+//
+// If the chunk at the given coordinates is already loaded or queued, then the
+// function does nothing.
+func (w *World) genChunk(p, q int) {
+	// Check the chunk isn't already loaded or on its way
+	pos := chunkPos{p, q}
+	if chunk := w.FindChunk(p, q); chunk != nil || w.pending[pos] {
+		return
+	}
+
+	// Queue the chunk's block and vertex data for loading
+	w.pending[pos] = true
+	w.queue.push(chunkJob{pos: pos}, w.centerP, w.centerQ)
+}
+
+// RegenChunk queues a job to regenerate the vertex data for the chunk at the
+// given coordinates on a worker goroutine, using its existing block data. This
 // should be called if the chunk's block data is modified (e.g. after placing a
 // new block).
 //
@@ -210,78 +662,263 @@ func (w *World) regenChunk(p, q int) {
 		return
 	}
 
-	// Copy block data into a new array, in case the chunk is unloaded while
-	// we're in the middle of loading it
-	copied := newBlockData()
-	copy(copied, chunk.Blocks)
+	// Multiple edits before the job is dispatched only need a single regen,
+	// since the block data is copied when the job is handed to a worker
+	for _, job := range w.queue {
+		if job.regen && job.pos == (chunkPos{p, q}) {
+			return
+		}
+	}
+	w.queue.push(chunkJob{pos: chunkPos{p, q}, regen: true}, w.centerP,
+		w.centerQ)
+}
 
-	// Load the vertex data on a separate goroutine
-	ch := make(chan interface{})
-	w.loading = append(w.loading, ch)
-	go (func() {
-		vertices := genVertices(vertexGenInfo{p, q, copied, &w.blocksInfo})
-		ch <- vertexGenResult{p, q, vertices}
-	})()
+// BlockVertexGenResult stores the block and vertex data generated for a chunk
+// upon initially loading the chunk.
+type blockVertexGenResult struct {
+	p, q      int       // The location of the chunk the data was generated for
+	blocks    blockData // The generated block data
+	light     lightData // The generated light data
+	mesh      *mesh     // The generated vertex data
+	numOpaque int32     // The number of indices that aren't translucent
+	lod       int       // The level of detail of the vertex data
+}
+
+// VertexGenResult stores the data generated when a chunk's vertex data is
+// reloaded from its existing block data.
+type vertexGenResult struct {
+	p, q      int       // The location of the chunk the data was generated for
+	light     lightData // The recalculated light data
+	mesh      *mesh     // The generated vertex data itself
+	numOpaque int32     // The number of indices that aren't translucent
+	lod       int       // The level of detail of the vertex data
+}
+
+// Worker runs on its own goroutine, generating block, light, and vertex data
+// for each job it receives until the jobs channel is closed.
+func (w *World) worker() {
+	for {
+		// Let the world know we're ready for another job
+		w.idle <- struct{}{}
+		job, ok := <-w.jobs
+		if !ok {
+			return
+		}
+
+		p, q := job.pos.p, job.pos.q
+		blocks := job.blocks
+		if !job.regen {
+			blocks = w.loadBlocks(p, q)
+		}
+		light := genLight(blocks, job.border, &w.blocksInfo)
+		mesh, numOpaque := genVertices(vertexGenInfo{p, q, blocks, light,
+			job.border, job.smooth, job.lod, &w.blocksInfo})
+		if job.regen {
+			// The copy of the block data is no longer needed
+			freeBlockData(blocks)
+			w.results <- vertexGenResult{p, q, light, mesh, numOpaque, job.lod}
+		} else {
+			w.results <- blockVertexGenResult{p, q, blocks, light, mesh,
+				numOpaque, job.lod}
+		}
+	}
 }
 
 // Update is called every update tick, and checks to see if any loading tasks
-// are finished.
+// are finished, handing out new jobs to any idle workers. Random blocks near
+// the player are also ticked (see `randomTick`), falling blocks are moved
+// downwards, fluids flow, and rain fades in or out.
 func (w *World) Update() {
-	// Select across all channels
-	for _, ch := range w.loading {
+	w.randomTick()
+	w.updateFalling()
+	w.updateFlowing()
+	w.updateWeather()
+
+	// Collect the results of all finished jobs, so the workers are free to
+	// carry on, but only upload a few of them
+	w.collectResults()
+	w.uploadFinished(maxUploadsPerUpdate)
+
+	// Hand out the closest jobs to idle workers
+	for len(w.queue) > 0 {
 		select {
-		case result := <-ch:
-			w.handleFinishedTask(result)
+		case <-w.idle:
+		default:
+			return // No idle workers
+		}
+
+		// Find the closest job that can still be worked on
+		sent := false
+		for len(w.queue) > 0 && !sent {
+			job := w.queue.pop()
+			if w.prepareJob(&job) {
+				w.jobs <- job
+				w.inFlight++
+				sent = true
+			}
+		}
+		if !sent {
+			// Return the idle worker's token if there was nothing to give it
+			w.idle <- struct{}{}
+		}
+	}
+}
+
+// CollectResults receives the results of every job that's finished since the
+// last update, adding them to the list waiting to be uploaded.
+func (w *World) collectResults() {
+	for {
+		select {
+		case result := <-w.results:
+			w.inFlight--
+			w.finished = append(w.finished, result)
 		default: // We want non-blocking channel reads
+			return
 		}
 	}
 }
 
+// UploadFinished handles up to `limit` of the finished results waiting to be
+// uploaded, oldest first. Results are handled in the order they finished, so
+// an older mesh for a chunk never replaces a newer one.
+func (w *World) uploadFinished(limit int) {
+	count := len(w.finished)
+	if count > limit {
+		count = limit
+	}
+	for _, result := range w.finished[:count] {
+		w.handleFinishedTask(result)
+	}
+
+	// Shift the remaining results to the front of the list, so its storage is
+	// reused rather than growing forever
+	remaining := copy(w.finished, w.finished[count:])
+	for i := remaining; i < len(w.finished); i++ {
+		w.finished[i] = nil // Don't hold on to the uploaded results
+	}
+	w.finished = w.finished[:remaining]
+}
+
+// PrepareJob copies the data a worker needs from the world into a job, just
+// before it's handed to the worker. Returns false if the job no longer needs
+// doing.
+func (w *World) prepareJob(job *chunkJob) bool {
+	p, q := job.pos.p, job.pos.q
+	if job.regen {
+		// Copy block data into a new array, in case the chunk is modified or
+		// unloaded while we're in the middle of regenerating it
+		chunk := w.FindChunk(p, q)
+		if chunk == nil {
+			return false
+		}
+		job.blocks = newBlockData(w.Height)
+		copy(job.blocks, chunk.Blocks)
+	}
+	job.border = w.borderLight(p, q)
+	job.smooth = w.smoothLighting
+	job.lod = chunkLOD(chunkDistSq(job.pos, w.centerP, w.centerQ),
+		w.RenderRadius)
+	return true
+}
+
 // HandleFinishedTask takes the data generated by a chunk loading task and
 // updates the relevant chunk with the information.
 func (w *World) handleFinishedTask(result interface{}) {
 	switch r := result.(type) {
 	case blockVertexGenResult:
 		// Loaded all information to do with a chunk
+		delete(w.pending, chunkPos{r.p, r.q})
 		chunk := newChunk()
 		chunk.Blocks = r.blocks
-		w.uploadChunk(chunk, r.vertices)
+		chunk.light = r.light
+		chunk.lod = r.lod
+		chunk.opaqueFaces = opaqueFaces(r.blocks, &w.blocksInfo)
+		chunk.loadTime = time.Now()
+		w.uploadChunk(chunk, r.mesh, r.numOpaque)
+		freeMesh(r.mesh)
 		w.chunks[chunkPos{r.p, r.q}] = chunk
+		w.updateEnclosedAround(r.p, r.q)
+
+		// Light from the new chunk may spill into its neighbours
+		w.regenChunk(r.p-1, r.q)
+		w.regenChunk(r.p+1, r.q)
+		w.regenChunk(r.p, r.q-1)
+		w.regenChunk(r.p, r.q+1)
 	case vertexGenResult:
 		// Reloaded a chunk's vertex data
 		chunk := w.FindChunk(r.p, r.q)
 		if chunk == nil {
 			// Chunk was unloaded while we were loading its data; do nothing
+			// except recycle the vertex data
+			freeMesh(r.mesh)
 			return
 		}
-		w.uploadChunk(chunk, r.vertices)
+		old := chunk.light
+		chunk.light = r.light
+		chunk.lod = r.lod
+		w.uploadChunk(chunk, r.mesh, r.numOpaque)
+		freeMesh(r.mesh)
+
+		// Pass any changes in light along the chunk's sides on to its
+		// neighbours
+		for face := faceLeft; face <= faceBack; face++ {
+			nx, ny, nz := face.normal()
+			if ny == 0 && sideLightChanged(old, r.light, face) {
+				w.regenChunk(r.p+nx, r.q+nz)
+			}
+		}
+	}
+}
+
+// UploadChunk pushes the new vertex data for a chunk to the GPU. The triangles
+// made up of the first `numOpaque` indices are drawn normally, and the rest
+// are translucent.
+func (w *World) uploadChunk(chunk *Chunk, m *mesh, numOpaque int32) {
+	chunk.numVertices = int32(len(m.vertices) / valuesPerVertex)
+	chunk.numIndices = int32(len(m.indices))
+	chunk.numOpaque = numOpaque
+
+	// Free the buffers of chunks with nothing to render, and reallocate them
+	// if the chunk gains some vertex data later on
+	chunk.empty = len(m.indices) == 0
+	if chunk.empty {
+		chunk.destroy()
+		return
 	}
+	uploadMesh(w, chunk, m)
 }
 
-// UploadChunk pushes the new vertex data for a chunk to the GPU.
-func (w *World) uploadChunk(chunk *Chunk, vertices []float32) {
-	chunk.numVertices = int32(len(vertices)) / valuesPerVertex
+// UploadMesh copies a chunk's mesh into the chunk's OpenGL buffers. Tests
+// replace it to check what's uploaded without needing OpenGL.
+var uploadMesh = (*World).uploadMeshBuffers
+
+// UploadMeshBuffers copies a chunk's mesh into the chunk's OpenGL buffers,
+// allocating the buffers first if the chunk doesn't have any.
+func (w *World) uploadMeshBuffers(chunk *Chunk, m *mesh) {
+	created := chunk.allocBuffers()
 
-	// Upload the vertex data by deleting the current vertex buffer and
-	// reallocating it
+	// Reuse the chunk's existing buffers if the new data fits in them. The
+	// index buffer is part of the VAO's state, so is bound with the VAO
 	gl.BindVertexArray(chunk.vao)
-	gl.DeleteBuffers(1, &chunk.vbo)
-	gl.GenBuffers(1, &chunk.vbo)
 	gl.BindBuffer(gl.ARRAY_BUFFER, chunk.vbo)
-	var ptr unsafe.Pointer
-	if len(vertices) > 0 {
-		ptr = gl.Ptr(vertices)
-	}
+	vertexBytes := int(chunk.numVertices) * valuesPerVertex * 4
+	chunk.vboSize = uploadBufferData(gl.ARRAY_BUFFER, chunk.vboSize,
+		vertexBytes, gl.Ptr(m.vertices))
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, chunk.ebo)
+	chunk.eboSize = uploadBufferData(gl.ELEMENT_ARRAY_BUFFER, chunk.eboSize,
+		int(chunk.numIndices)*4, gl.Ptr(m.indices))
 
-	// For some reason (I have no idea why, maybe something to do with Go's
-	// internal representation of slices, and how they have a length/capacity
-	// value associated with them in a struct??) we need to add 1 to the length
-	// of the slice that we're copying to the GPU. If we don't do this, the
-	// last value in the vertex data is cut off.
-	gl.BufferData(gl.ARRAY_BUFFER, (len(vertices)+1)*4, ptr, gl.STATIC_DRAW)
+	// The VAO remembers its vertex attributes, and the buffer they read from
+	// never changes, so they only need setting up once
+	if created {
+		w.setVertexAttribs()
+	}
+}
 
-	// Set the vertex attributes on the new buffer
-	gl.UseProgram(w.program)
+// SetVertexAttribs sets up the vertex attributes for the currently bound VAO,
+// reading from the currently bound vertex buffer.
+func (w *World) setVertexAttribs() {
+	gl.UseProgram(w.program.ID)
 
 	// Position attribute
 	gl.EnableVertexAttribArray(w.posAttr)
@@ -295,8 +932,18 @@ func (w *World) uploadChunk(chunk *Chunk, vertices []float32) {
 
 	// UV attribute
 	gl.EnableVertexAttribArray(w.uvAttr)
-	gl.VertexAttribPointer(w.uvAttr, 3, gl.FLOAT, false, valuesPerVertex*4,
+	gl.VertexAttribPointer(w.uvAttr, 2, gl.FLOAT, false, valuesPerVertex*4,
 		gl.PtrOffset(6*4))
+
+	// Light attribute
+	gl.EnableVertexAttribArray(w.lightAttr)
+	gl.VertexAttribPointer(w.lightAttr, 1, gl.FLOAT, false, valuesPerVertex*4,
+		gl.PtrOffset(8*4))
+
+	// Animation frames attribute
+	gl.EnableVertexAttribArray(w.framesAttr)
+	gl.VertexAttribPointer(w.framesAttr, 1, gl.FLOAT, false,
+		valuesPerVertex*4, gl.PtrOffset(9*4))
 }
 
 // RenderInfo stores information required by the world for rendering.
@@ -304,6 +951,10 @@ type RenderInfo struct {
 	Camera       *camera.Camera
 	PlayerChunkP int
 	PlayerChunkQ int
+	Time         float32 // Seconds since the game started, for animations
+
+	// The color of the fog, which newly loaded chunks fade in from
+	FogColor mgl32.Vec3
 }
 
 // Render draws all loaded chunks with vertex data to the screen.
@@ -313,15 +964,22 @@ func (w *World) Render(info RenderInfo) {
 	gl.Enable(gl.DEPTH_TEST)
 
 	// Use the chunk shader program and set uniforms
-	gl.UseProgram(w.program)
-	gl.UniformMatrix4fv(w.mvpUnf, 1, false, &info.Camera.View[0])
-	gl.Uniform1i(w.blockAtlasUnf, blockAtlasSlot)
+	w.program.Use()
+	gl.UniformMatrix4fv(w.program.Uniform("mvp"), 1, false,
+		&info.Camera.View[0])
+	gl.Uniform1i(w.program.Uniform("blockAtlas"), BlockAtlasSlot)
+	gl.Uniform1f(w.program.Uniform("time"), info.Time)
+	gl.Uniform3f(w.program.Uniform("fogColor"), info.FogColor.X(),
+		info.FogColor.Y(), info.FogColor.Z())
 
 	// Iterate over each available chunk
+	now := time.Now()
+	frustum := info.Camera.Frustum()
+	var translucent []chunkPos
 	for pos, chunk := range w.chunks {
 		// Don't bother rendering a chunk that's yet to be loaded, or has no
 		// vertex data
-		if chunk.Blocks == nil || chunk.numVertices == 0 {
+		if chunk.Blocks == nil || chunk.empty {
 			continue
 		}
 
@@ -332,11 +990,61 @@ func (w *World) Render(info RenderInfo) {
 			continue
 		}
 
-		// Render the chunk
-		chunk.render()
+		// Don't render a chunk hidden behind opaque blocks on every side,
+		// unless the player is inside it (e.g. having dug into it)
+		if chunk.enclosed && (dp != 0 || dq != 0) {
+			continue
+		}
+
+		// Don't render a chunk that's outside the camera's field of view
+		if !frustum.IntersectsAABB(w.ChunkAABB(pos.p, pos.q)) {
+			continue
+		}
+
+		// Render the chunk's opaque faces, leaving its translucent faces until
+		// everything behind them has been drawn. Newly loaded chunks are
+		// darkened towards the fog color, rather than made translucent, so
+		// they're still drawn with the opaque faces
+		w.setFade(chunk, now)
+		chunk.renderOpaque()
+		if chunk.numOpaque < chunk.numIndices {
+			translucent = append(translucent, pos)
+		}
+	}
+
+	// Draw translucent faces from the furthest chunk to the nearest, blending
+	// them with what's behind them. Translucent faces don't write to the depth
+	// buffer, so they don't hide any translucent faces behind them
+	sort.Slice(translucent, func(i, j int) bool {
+		return chunkDistanceSq(translucent[i], info) >
+			chunkDistanceSq(translucent[j], info)
+	})
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	gl.DepthMask(false)
+	for _, pos := range translucent {
+		w.setFade(w.chunks[pos], now)
+		w.chunks[pos].renderTranslucent()
 	}
 
 	// Reset the OpenGL state
+	gl.DepthMask(true)
+	gl.Disable(gl.BLEND)
 	gl.Disable(gl.CULL_FACE)
 	gl.Disable(gl.DEPTH_TEST)
 }
+
+// SetFade tells the chunk shader how far the given chunk has faded in, at the
+// time `now`.
+func (w *World) setFade(chunk *Chunk, now time.Time) {
+	fade := fadeFactor(now.Sub(chunk.loadTime))
+	gl.Uniform1f(w.program.Uniform("fade"), fade)
+}
+
+// ChunkDistanceSq returns the square of the distance, in chunks, between a
+// chunk and the chunk the player is in.
+func chunkDistanceSq(pos chunkPos, info RenderInfo) int {
+	dp := pos.p - info.PlayerChunkP
+	dq := pos.q - info.PlayerChunkQ
+	return dp*dp + dq*dq
+}
@@ -0,0 +1,579 @@
+package world
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/benanders/mineral/camera"
+	"github.com/benanders/mineral/math"
+	"github.com/benanders/mineral/render"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The height of the worlds created for testing, which is kept small so that
+// generating chunks is quick. Generated chunks have a floor of stone whose top
+// is at y = 3.
+const (
+	testHeight = 32
+	testFloorY = 3.0
+)
+
+// NewTestWorld creates a headless world (see `NewHeadless`), so that tests
+// can edit blocks and queue jobs without a window.
+func newTestWorld() *World {
+	w := NewHeadless(testHeight, 1)
+	w.RenderRadius = 2
+	return w
+}
+
+// LoadTestChunk loads the chunk (p, q) into a headless world, and returns it.
+func loadTestChunk(w *World, p, q int) *Chunk {
+	w.LoadChunkHeadless(p, q)
+	return w.FindChunk(p, q)
+}
+
+// TestBlock returns the type of block with the given name, failing the test
+// if there's no such block.
+func testBlock(t *testing.T, w *World, name string) Block {
+	t.Helper()
+	block, ok := w.FindBlock(name)
+	if !ok {
+		t.Fatalf("no block named %q", name)
+	}
+	return block
+}
+
+func TestPinnedChunkIsNeverUnloaded(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	loadTestChunk(w, 20, 0)
+	loadTestChunk(w, 21, 0)
+	w.PinChunk(20, 0)
+
+	// Both far chunks are well outside the delete radius around (0, 0)
+	w.GenChunksAround(0, 0)
+	if w.FindChunk(20, 0) == nil {
+		t.Error("pinned chunk was unloaded")
+	}
+	if w.FindChunk(21, 0) != nil {
+		t.Error("unpinned chunk wasn't unloaded")
+	}
+
+	// Once unpinned, the chunk is unloaded like any other
+	w.UnpinChunk(20, 0)
+	w.GenChunksAround(0, 0)
+	if w.FindChunk(20, 0) != nil {
+		t.Error("unpinned chunk wasn't unloaded")
+	}
+}
+
+func TestPinChunkQueuesUnloadedChunk(t *testing.T) {
+	w := newTestWorld()
+	w.PinChunk(30, 30)
+	if !w.pending[chunkPos{30, 30}] {
+		t.Error("pinned chunk wasn't queued for loading")
+	}
+
+	// The queued job isn't dropped for being outside the delete radius
+	w.GenChunksAround(0, 0)
+	if !w.pending[chunkPos{30, 30}] {
+		t.Error("job for pinned chunk was dropped")
+	}
+}
+
+// HasRegenJob returns true if a job to regenerate the vertex data of the
+// chunk (p, q) is waiting in the world's queue.
+func hasRegenJob(w *World, p, q int) bool {
+	for _, job := range w.queue {
+		if job.regen && job.pos == (chunkPos{p, q}) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSetBlock(t *testing.T) {
+	w := newTestWorld()
+	chunk := loadTestChunk(w, 0, 0)
+	loadTestChunk(w, -1, 0)
+	loadTestChunk(w, 1, 0)
+	cobblestone := testBlock(t, w, "Cobblestone")
+
+	// An edit inside the chunk only regenerates that chunk
+	if !w.SetBlock(5, 10, 5, cobblestone) {
+		t.Fatal("failed to set block in loaded chunk")
+	}
+	if got := *chunk.Blocks.At(5, 10, 5); got != cobblestone {
+		t.Errorf("got block %v, want %v", got, cobblestone)
+	}
+	if !hasRegenJob(w, 0, 0) {
+		t.Error("chunk wasn't regenerated")
+	}
+	if hasRegenJob(w, -1, 0) || hasRegenJob(w, 1, 0) {
+		t.Error("neighbouring chunk regenerated for an interior edit")
+	}
+
+	// An edit on the chunk's border regenerates the neighbour across it
+	if !w.SetBlock(0, 10, 5, cobblestone) {
+		t.Fatal("failed to set block in loaded chunk")
+	}
+	if !hasRegenJob(w, -1, 0) {
+		t.Error("neighbouring chunk wasn't regenerated for a border edit")
+	}
+}
+
+func TestSetBlockOutsideLoadedChunks(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	if w.SetBlock(100, 10, 100, 1) {
+		t.Error("set block in a chunk that isn't loaded")
+	}
+	if w.SetBlock(0, testHeight, 0, 1) {
+		t.Error("set block above the top of the world")
+	}
+	if w.SetBlock(0, -1, 0, 1) {
+		t.Error("set block below the bottom of the world")
+	}
+}
+
+func TestWorldHeight(t *testing.T) {
+	w := NewHeadless(128, 1)
+	chunk := loadTestChunk(w, 0, 0)
+	if got := chunk.Blocks.height(); got != 128 {
+		t.Fatalf("generated chunk %d blocks tall, want 128", got)
+	}
+
+	// Blocks can only be set within the world's height
+	stone := testBlock(t, w, "Stone")
+	if w.SetBlock(5, 200, 5, stone) {
+		t.Error("set block at y = 200 in a world 128 blocks tall")
+	}
+	if _, ok := w.GetBlock(5, 200, 5); ok {
+		t.Error("got block at y = 200 in a world 128 blocks tall")
+	}
+	if !w.SetBlock(5, 127, 5, stone) {
+		t.Error("failed to set block at the top of the world")
+	}
+
+	// The terrain is generated within the world's height, with the light
+	// data to match
+	if got := chunk.light.height(); got != 128 {
+		t.Errorf("generated light for %d blocks, want 128", got)
+	}
+	if block, _ := w.GetBlock(5, 0, 5); block == Air {
+		t.Error("no terrain generated at the bottom of the world")
+	}
+}
+
+// FailShaders makes loading shaders fail until the returned function is
+// called.
+func failShaders() (restore func()) {
+	load := loadShaders
+	loadShaders = func(vertexPath,
+		fragmentPath string) (*render.ReloadableProgram, error) {
+		return nil, errors.New("shader failed to compile")
+	}
+	return func() { loadShaders = load }
+}
+
+func TestNewWithFailedShaders(t *testing.T) {
+	defer failShaders()()
+	w, err := New(2, testHeight, 1)
+	if err == nil || w != nil {
+		t.Error("created world with shaders that failed to load")
+	}
+}
+
+func TestCanPlaceBlockAgainstEntities(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	stone := testBlock(t, w, "Stone")
+	water := testBlock(t, w, "Water")
+
+	// An entity standing in the column of blocks at (5, 5), 2 blocks tall
+	occupied := []math.AABB{{
+		Center: mgl32.Vec3{5.5, 10.9, 5.5},
+		Size:   mgl32.Vec3{0.6, 1.8, 0.6},
+	}}
+	if w.CanPlaceBlock(5, 10, 5, stone, occupied) {
+		t.Error("placed block inside an entity's feet")
+	}
+	if w.CanPlaceBlock(5, 11, 5, stone, occupied) {
+		t.Error("placed block inside an entity's head")
+	}
+	if !w.CanPlaceBlock(6, 10, 5, stone, occupied) {
+		t.Error("couldn't place block next to an entity")
+	}
+	if !w.CanPlaceBlock(5, 12, 5, stone, occupied) {
+		t.Error("couldn't place block above an entity")
+	}
+
+	// Entities can move through non-collidable blocks
+	if !w.CanPlaceBlock(5, 10, 5, water, occupied) {
+		t.Error("couldn't place non-collidable block inside an entity")
+	}
+}
+
+// GenTestMesh generates the vertex data for a loaded chunk at the given level
+// of detail, as a worker would. The mesh should be freed with `freeMesh`.
+func genTestMesh(w *World, p, q, lod int) *mesh {
+	chunk := w.FindChunk(p, q)
+	border := w.borderLight(p, q)
+	light := genLight(chunk.Blocks, border, &w.blocksInfo)
+	m, _ := genVertices(vertexGenInfo{p, q, chunk.Blocks, light, border,
+		false, lod, &w.blocksInfo})
+	return m
+}
+
+// GenTestVertices generates the vertex data for a loaded chunk at full
+// detail, and returns the number of vertices.
+func genTestVertices(w *World, p, q int) int {
+	m := genTestMesh(w, p, q, 0)
+	defer freeMesh(m)
+	return len(m.vertices) / valuesPerVertex
+}
+
+func TestBreakInteriorBlockExposesFaces(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	stone := testBlock(t, w, "Stone")
+	before := genTestVertices(w, 0, 0)
+
+	// The block is surrounded by stone on every side
+	block, ok := w.BreakBlock(5, 1, 5)
+	if !ok {
+		t.Fatal("failed to break block")
+	}
+	if block != stone {
+		t.Errorf("broke block %v, want %v", block, stone)
+	}
+	if !hasRegenJob(w, 0, 0) {
+		t.Error("chunk wasn't regenerated")
+	}
+
+	// Each of the 6 newly exposed faces adds a quad
+	if after := genTestVertices(w, 0, 0); after != before+6*4 {
+		t.Errorf("got %d vertices after breaking, want %d", after, before+6*4)
+	}
+}
+
+func TestBreakBlockOnChunkBorder(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	loadTestChunk(w, 0, -1)
+	if _, ok := w.BreakBlock(5, 1, 0); !ok {
+		t.Fatal("failed to break block")
+	}
+	if !hasRegenJob(w, 0, -1) {
+		t.Error("neighbouring chunk wasn't regenerated")
+	}
+}
+
+func TestBreakAir(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	if _, ok := w.BreakBlock(5, 10, 5); ok {
+		t.Error("broke air")
+	}
+	if hasRegenJob(w, 0, 0) {
+		t.Error("chunk regenerated without any change")
+	}
+}
+
+func TestSetSmoothLightingRegeneratesChunks(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	loadTestChunk(w, 1, 0)
+	w.SetSmoothLighting(true)
+	if !hasRegenJob(w, 0, 0) || !hasRegenJob(w, 1, 0) {
+		t.Error("loaded chunks weren't regenerated")
+	}
+	job := w.queue.pop()
+	if !w.prepareJob(&job) || !job.smooth {
+		t.Error("chunk regenerated without smooth lighting")
+	}
+}
+
+func TestChunkAABB(t *testing.T) {
+	w := newTestWorld()
+	tests := []struct {
+		p, q     int
+		min, max mgl32.Vec3
+	}{
+		{0, 0, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{16, testHeight, 16}},
+		{1, 0, mgl32.Vec3{16, 0, 0}, mgl32.Vec3{32, testHeight, 16}},
+		{-1, -2, mgl32.Vec3{-16, 0, -32}, mgl32.Vec3{0, testHeight, -16}},
+	}
+	for _, test := range tests {
+		aabb := w.ChunkAABB(test.p, test.q)
+		if aabb.Min() != test.min || aabb.Max() != test.max {
+			t.Errorf("chunk (%d, %d) spans %v to %v, want %v to %v", test.p,
+				test.q, aabb.Min(), aabb.Max(), test.min, test.max)
+		}
+	}
+}
+
+func TestChunkSnapshots(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	loadTestChunk(w, -1, 2)
+	w.genChunk(3, -1)
+
+	// Every loaded chunk is ready, and the chunk waiting to be generated
+	// isn't
+	want := map[chunkPos]bool{{0, 0}: true, {-1, 2}: true, {3, -1}: false}
+	snapshots := w.ChunkSnapshots()
+	if len(snapshots) != len(want) {
+		t.Fatalf("got %d snapshots, want %d", len(snapshots), len(want))
+	}
+	for _, s := range snapshots {
+		ready, ok := want[chunkPos{s.P, s.Q}]
+		if !ok {
+			t.Errorf("got snapshot of unexpected chunk (%d, %d)", s.P, s.Q)
+			continue
+		}
+		if s.Ready != ready {
+			t.Errorf("chunk (%d, %d) has ready %v, want %v", s.P, s.Q,
+				s.Ready, ready)
+		}
+		corner := mgl32.Vec3{float32(s.P * ChunkWidth), 0.0,
+			float32(s.Q * ChunkDepth)}
+		if s.AABB.Min() != corner || s.AABB != w.ChunkAABB(s.P, s.Q) {
+			t.Errorf("chunk (%d, %d) has AABB from %v, want from %v", s.P,
+				s.Q, s.AABB.Min(), corner)
+		}
+		delete(want, chunkPos{s.P, s.Q})
+	}
+
+	// Unloaded chunks are left out
+	w.GenChunksAround(20, 20)
+	for _, s := range w.ChunkSnapshots() {
+		if s.P == 0 && s.Q == 0 {
+			t.Error("got snapshot of unloaded chunk (0, 0)")
+		}
+	}
+}
+
+func TestClampHeight(t *testing.T) {
+	w := newTestWorld()
+	tests := []struct{ y1, y2, want1, want2 int }{
+		{5, 10, 5, 10},
+		{-3, 10, 0, 10},
+		{5, testHeight + 20, 5, testHeight - 1},
+		{-1000000, 1000000, 0, testHeight - 1},
+	}
+	for _, test := range tests {
+		y1, y2 := w.ClampHeight(test.y1, test.y2)
+		if y1 != test.want1 || y2 != test.want2 {
+			t.Errorf("clamped %d to %d to %d to %d, want %d to %d", test.y1,
+				test.y2, y1, y2, test.want1, test.want2)
+		}
+	}
+
+	// Ranges entirely outside the world are empty
+	for _, r := range [][2]int{{-10, -2}, {testHeight, testHeight + 5}} {
+		if y1, y2 := w.ClampHeight(r[0], r[1]); y1 <= y2 {
+			t.Errorf("range %d to %d outside the world isn't empty", r[0],
+				r[1])
+		}
+	}
+}
+
+func TestCollisionAABBsFarOutsideWorld(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	region := math.AABB{Center: mgl32.Vec3{5.5, 1e6, 5.5},
+		Size: mgl32.Vec3{2.0, 2.0, 2.0}}
+	if aabbs := w.CollisionAABBs(region); len(aabbs) != 0 {
+		t.Errorf("got %d collision boxes above the world, want 0",
+			len(aabbs))
+	}
+}
+
+func TestToChunkSpace(t *testing.T) {
+	tests := []struct {
+		wx, wz, p, q, x, z int
+	}{
+		{0, 0, 0, 0, 0, 0},
+		{15, 15, 0, 0, 15, 15},
+		{16, 0, 1, 0, 0, 0},
+		{-1, 0, -1, 0, 15, 0},
+		{-16, 0, -1, 0, 0, 0},
+		{-17, 0, -2, 0, 15, 0},
+		{0, -1, 0, -1, 0, 15},
+		{0, -16, 0, -1, 0, 0},
+		{0, -17, 0, -2, 0, 15},
+		{-33, 40, -3, 2, 15, 8},
+	}
+	for _, test := range tests {
+		p, q, x, y, z := ToChunkSpace(test.wx, 7, test.wz)
+		if p != test.p || q != test.q || x != test.x || y != 7 ||
+			z != test.z {
+			t.Errorf("(%d, %d) is in chunk (%d, %d) at (%d, %d), want "+
+				"chunk (%d, %d) at (%d, %d)", test.wx, test.wz, p, q, x, z,
+				test.p, test.q, test.x, test.z)
+		}
+	}
+}
+
+func TestToChunkSpaceRoundTrips(t *testing.T) {
+	for wx := -100; wx <= 100; wx++ {
+		p, q, x, _, z := ToChunkSpace(wx, 0, -wx)
+		if x < 0 || x >= ChunkWidth || z < 0 || z >= ChunkDepth {
+			t.Fatalf("(%d, %d) has local coordinates (%d, %d) outside the "+
+				"chunk", wx, -wx, x, z)
+		}
+		if p*ChunkWidth+x != wx || q*ChunkDepth+z != -wx {
+			t.Fatalf("(%d, %d) maps to chunk (%d, %d) at (%d, %d), which "+
+				"isn't the same block", wx, -wx, p, q, x, z)
+		}
+	}
+}
+
+func TestSetRenderRadiusIsClamped(t *testing.T) {
+	w := newTestWorld()
+	tests := []struct{ radius, want int }{
+		{5, 5},
+		{0, 1},
+		{-3, 1},
+		{MaxRenderRadius, MaxRenderRadius},
+		{MaxRenderRadius + 1, MaxRenderRadius},
+		{100, MaxRenderRadius},
+	}
+	for _, test := range tests {
+		w.SetRenderRadius(test.radius)
+		if w.RenderRadius != test.want {
+			t.Errorf("got render radius %d after setting %d, want %d",
+				w.RenderRadius, test.radius, test.want)
+		}
+	}
+
+	// Every chunk within the render radius is in front of the far plane
+	if MaxRenderRadius*ChunkWidth > camera.Far {
+		t.Errorf("max render radius of %d blocks is beyond the far plane at "+
+			"%v", MaxRenderRadius*ChunkWidth, camera.Far)
+	}
+}
+
+// WithinRadius returns true if the chunk (p, q) is within `radius` chunks of
+// the chunk (centerP, centerQ).
+func withinRadius(p, q, centerP, centerQ, radius int) bool {
+	return chunkDistSq(chunkPos{p, q}, centerP, centerQ) <= radius*radius
+}
+
+func TestSetRenderRadiusQueuesOuterRing(t *testing.T) {
+	w := newTestWorld()
+	w.GenChunksAround(0, 0)
+	r := w.RenderRadius
+
+	// Raising the radius queues every chunk in the new outer ring straight
+	// away
+	w.SetRenderRadius(r + 2)
+	ring := 0
+	for p := -r - 2; p <= r+2; p++ {
+		for q := -r - 2; q <= r+2; q++ {
+			if !withinRadius(p, q, 0, 0, r+2) || withinRadius(p, q, 0, 0, r) {
+				continue
+			}
+			ring++
+			if !w.pending[chunkPos{p, q}] {
+				t.Errorf("chunk (%d, %d) in the new ring isn't queued", p, q)
+			}
+		}
+	}
+	if ring == 0 {
+		t.Fatal("no new chunks came within the render radius")
+	}
+
+	// Lowering it again unloads the chunks left outside the delete radius
+	far := chunkPos{r + 2 + deleteRadiusPadding, 0}
+	loadTestChunk(w, far.p, far.q)
+	w.SetRenderRadius(r)
+	if w.FindChunk(far.p, far.q) != nil {
+		t.Errorf("chunk %v outside the delete radius is still loaded", far)
+	}
+}
+
+func TestGenChunksAroundCrossingBoundary(t *testing.T) {
+	w := newTestWorld()
+	w.GenChunksAround(0, 0)
+	r := w.RenderRadius
+	if w.pending[chunkPos{r + 1, 0}] {
+		t.Fatalf("chunk (%d, 0) outside the render radius is queued", r+1)
+	}
+
+	// Crossing into the next chunk along the x axis queues the ring of chunks
+	// that have just come within the render radius
+	w.GenChunksAround(1, 0)
+	ring := 0
+	for p := 1 - r; p <= 1+r; p++ {
+		for q := -r; q <= r; q++ {
+			if !withinRadius(p, q, 1, 0, r) || withinRadius(p, q, 0, 0, r) {
+				continue
+			}
+			ring++
+			if !w.pending[chunkPos{p, q}] {
+				t.Errorf("chunk (%d, %d) in the new ring isn't queued", p, q)
+			}
+		}
+	}
+	if ring == 0 {
+		t.Fatal("no new chunks came within the render radius")
+	}
+	if w.centerP != 1 || w.centerQ != 0 {
+		t.Errorf("queue centred on chunk (%d, %d), want (1, 0)", w.centerP,
+			w.centerQ)
+	}
+}
+
+func TestGenChunksAroundDeleteRadius(t *testing.T) {
+	w := newTestWorld()
+	deleteRadius := w.RenderRadius + deleteRadiusPadding
+
+	// Chunks outside the render radius but within the delete radius are kept,
+	// including those right on the delete radius
+	kept := []chunkPos{{w.RenderRadius + 1, 0}, {deleteRadius, 0},
+		{0, -deleteRadius}, {3, 2}}
+	removed := []chunkPos{{deleteRadius, 1}, {deleteRadius + 1, 0},
+		{-10, 4}, {30, 30}}
+	for _, pos := range append(kept, removed...) {
+		loadTestChunk(w, pos.p, pos.q)
+	}
+	w.GenChunksAround(0, 0)
+	for _, pos := range kept {
+		if !withinRadius(pos.p, pos.q, 0, 0, deleteRadius) {
+			t.Fatalf("chunk %v isn't within the delete radius", pos)
+		}
+		if w.FindChunk(pos.p, pos.q) == nil {
+			t.Errorf("chunk %v inside the delete radius was unloaded", pos)
+		}
+	}
+	for _, pos := range removed {
+		if w.FindChunk(pos.p, pos.q) != nil {
+			t.Errorf("chunk %v outside the delete radius is still loaded",
+				pos)
+		}
+	}
+}
+
+func TestBreakUnbreakableBlock(t *testing.T) {
+	w := newTestWorld()
+	loadTestChunk(w, 0, 0)
+	bedrock := testBlock(t, w, "Bedrock")
+	if w.GetBlockInfo(bedrock).Breakable() {
+		t.Fatal("bedrock is breakable")
+	}
+	if !w.GetBlockInfo(testBlock(t, w, "Stone")).Breakable() {
+		t.Fatal("stone isn't breakable")
+	}
+
+	w.SetBlock(5, 10, 5, bedrock)
+	if _, ok := w.BreakBlock(5, 10, 5); ok {
+		t.Error("broke bedrock")
+	}
+	if block, _ := w.GetBlock(5, 10, 5); block != bedrock {
+		t.Errorf("got block %v after failing to break bedrock, want %v",
+			block, bedrock)
+	}
+}